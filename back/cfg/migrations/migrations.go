@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned schema migrations of each database
+// driver. Files are named <version>_<name>.up.sql and <version>_<name>.down.sql
+// and live in the directory of the driver. sqlc reads the same directories,
+// skipping the down files.
+package migrations
+
+import "embed"
+
+//go:embed postgresql/*.sql mysql/*.sql sqlite/*.sql
+var FS embed.FS
@@ -0,0 +1,63 @@
+CREATE TABLE `users` (
+  `id` char(36) PRIMARY KEY,
+  `login` varchar(100) UNIQUE,
+  `password` text,
+  `role` varchar(20) NOT NULL DEFAULT 'user' CHECK (`role` IN ('user', 'teacher', 'admin')),
+  `disabled_at` datetime(6),
+  `password_reset_required` boolean NOT NULL DEFAULT false
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `flashcards` (
+  `id` char(36) PRIMARY KEY,
+  `word` text,
+  `meaning` text,
+  `usage` json,
+  `owner` char(36) NOT NULL,
+  INDEX `index_flashcards_owner` (`owner`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `flashcard_decks` (
+  `deck_id` char(36) NOT NULL,
+  `flashcard_id` char(36) NOT NULL,
+  PRIMARY KEY (`deck_id`, `flashcard_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `decks` (
+  `id` char(36) PRIMARY KEY,
+  `name` varchar(200),
+  `owner` char(36) NOT NULL,
+  INDEX `index_decks_owner` (`owner`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE `flashcards` ADD FOREIGN KEY (`owner`) REFERENCES `users` (`id`) ON DELETE CASCADE;
+ALTER TABLE `decks` ADD FOREIGN KEY (`owner`) REFERENCES `users` (`id`) ON DELETE CASCADE;
+ALTER TABLE `flashcard_decks` ADD FOREIGN KEY (`deck_id`) REFERENCES `decks` (`id`) ON DELETE CASCADE;
+ALTER TABLE `flashcard_decks` ADD FOREIGN KEY (`flashcard_id`) REFERENCES `flashcards` (`id`) ON DELETE CASCADE;
+
+CREATE TABLE `reviews` (
+  `user_id` char(36) NOT NULL,
+  `flashcard_id` char(36) NOT NULL,
+  `ease_factor` double NOT NULL DEFAULT 2.5,
+  `interval_days` int NOT NULL DEFAULT 0,
+  `repetitions` int NOT NULL DEFAULT 0,
+  `lapses` int NOT NULL DEFAULT 0,
+  `due_at` datetime(6) NOT NULL,
+  `last_reviewed_at` datetime(6),
+  PRIMARY KEY (`user_id`, `flashcard_id`),
+  INDEX `index_reviews_user_due` (`user_id`, `due_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE `reviews` ADD FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;
+ALTER TABLE `reviews` ADD FOREIGN KEY (`flashcard_id`) REFERENCES `flashcards` (`id`) ON DELETE CASCADE;
+
+CREATE TABLE `sessions` (
+  `id` char(36) PRIMARY KEY,
+  `user_id` char(36) NOT NULL,
+  `refresh_hash` varchar(64) NOT NULL,
+  `created_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  `expires_at` datetime(6) NOT NULL,
+  `revoked_at` datetime(6),
+  INDEX `index_sessions_user` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE `sessions` ADD FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS `flashcard_tags`;
+
+DROP INDEX `index_flashcards_owner_updated` ON `flashcards`;
+DROP INDEX `index_flashcards_owner_created` ON `flashcards`;
+DROP INDEX `index_flashcards_owner_word` ON `flashcards`;
+
+ALTER TABLE `flashcards`
+  DROP COLUMN `updated_at`,
+  DROP COLUMN `created_at`;
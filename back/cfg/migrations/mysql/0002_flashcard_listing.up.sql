@@ -0,0 +1,18 @@
+ALTER TABLE `flashcards`
+  ADD COLUMN `created_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  ADD COLUMN `updated_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6);
+UPDATE `flashcards` SET `word` = '' WHERE `word` IS NULL;
+
+-- text columns are indexed by a prefix, the cards are still sorted by the whole word
+CREATE INDEX `index_flashcards_owner_word` ON `flashcards` (`owner`, `word`(100));
+CREATE INDEX `index_flashcards_owner_created` ON `flashcards` (`owner`, `created_at`, `id`);
+CREATE INDEX `index_flashcards_owner_updated` ON `flashcards` (`owner`, `updated_at`, `id`);
+
+CREATE TABLE `flashcard_tags` (
+  `flashcard_id` char(36) NOT NULL,
+  `tag` varchar(50) NOT NULL,
+  PRIMARY KEY (`flashcard_id`, `tag`),
+  INDEX `index_flashcard_tags_tag` (`tag`, `flashcard_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE `flashcard_tags` ADD FOREIGN KEY (`flashcard_id`) REFERENCES `flashcards` (`id`) ON DELETE CASCADE;
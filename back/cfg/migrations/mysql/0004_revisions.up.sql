@@ -0,0 +1,4 @@
+-- version is bumped by every update, writes made on an older version are
+-- rejected
+ALTER TABLE `flashcards` ADD COLUMN `version` bigint NOT NULL DEFAULT 1;
+ALTER TABLE `decks` ADD COLUMN `version` bigint NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+DELETE FROM `decks` WHERE `deleted_at` IS NOT NULL;
+DELETE FROM `flashcards` WHERE `deleted_at` IS NOT NULL;
+ALTER TABLE `decks` DROP INDEX `index_decks_deleted`, DROP COLUMN `deleted_at`;
+ALTER TABLE `flashcards` DROP INDEX `index_flashcards_deleted`, DROP COLUMN `deleted_at`;
@@ -0,0 +1,3 @@
+-- deleted cards and decks stay in the trash until the retention window passes
+ALTER TABLE `flashcards` ADD COLUMN `deleted_at` datetime(6), ADD INDEX `index_flashcards_deleted` (`deleted_at`);
+ALTER TABLE `decks` ADD COLUMN `deleted_at` datetime(6), ADD INDEX `index_decks_deleted` (`deleted_at`);
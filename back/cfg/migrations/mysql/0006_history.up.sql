@@ -0,0 +1,19 @@
+-- revisions is the append-only history of the flashcards and the decks. Every
+-- change adds a row with the field-level diff and the state after the change,
+-- the rows outlive the entity and go only with its owner.
+CREATE TABLE `revisions` (
+  `id` char(36) PRIMARY KEY,
+  `entity_type` varchar(20) NOT NULL CHECK (`entity_type` IN ('flashcard', 'deck')),
+  `entity_id` char(36) NOT NULL,
+  `owner` char(36) NOT NULL,
+  `version` bigint NOT NULL,
+  `action` varchar(20) NOT NULL CHECK (`action` IN ('create', 'update', 'delete', 'restore')),
+  `actor_id` char(36) NOT NULL,
+  `request_id` varchar(200) NOT NULL DEFAULT '',
+  `changes` text NOT NULL,
+  `snapshot` text NOT NULL,
+  `created_at` datetime(6) NOT NULL,
+  INDEX `index_revisions_entity` (`entity_id`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE `revisions` ADD FOREIGN KEY (`owner`) REFERENCES `users` (`id`) ON DELETE CASCADE;
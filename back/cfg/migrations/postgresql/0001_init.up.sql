@@ -0,0 +1,63 @@
+CREATE TABLE "users" (
+  "id" uuid PRIMARY KEY,
+  "login" varchar(100) UNIQUE,
+  "password" text,
+  "role" varchar(20) NOT NULL DEFAULT 'user' CHECK ("role" IN ('user', 'teacher', 'admin')),
+  "disabled_at" timestamptz,
+  "password_reset_required" boolean NOT NULL DEFAULT false
+);
+
+CREATE TABLE "flashcards" (
+  "id" uuid PRIMARY KEY,
+  "word" text,
+  "meaning" text,
+  "usage" text[],
+  "owner" uuid NOT NULL
+);
+CREATE INDEX "index_flashcards_owner" ON "flashcards" ("owner");
+
+CREATE TABLE "flashcard_decks" (
+  "deck_id" uuid NOT NULL,
+  "flashcard_id" uuid NOT NULL,
+  PRIMARY KEY ("deck_id", "flashcard_id")
+);
+
+CREATE TABLE "decks" (
+  "id" uuid PRIMARY KEY,
+  "name" varchar(200),
+  "owner" uuid NOT NULL
+);
+CREATE INDEX "index_decks_owner" ON "decks" ("owner");
+
+ALTER TABLE "flashcards" ADD FOREIGN KEY ("owner") REFERENCES "users" ("id") ON DELETE CASCADE;
+ALTER TABLE "decks" ADD FOREIGN KEY ("owner") REFERENCES "users" ("id") ON DELETE CASCADE;
+ALTER TABLE "flashcard_decks" ADD FOREIGN KEY ("deck_id") REFERENCES "decks" ("id") ON DELETE CASCADE;
+ALTER TABLE "flashcard_decks" ADD FOREIGN KEY ("flashcard_id") REFERENCES "flashcards" ("id") ON DELETE CASCADE;
+
+CREATE TABLE "reviews" (
+  "user_id" uuid NOT NULL,
+  "flashcard_id" uuid NOT NULL,
+  "ease_factor" double precision NOT NULL DEFAULT 2.5,
+  "interval_days" integer NOT NULL DEFAULT 0,
+  "repetitions" integer NOT NULL DEFAULT 0,
+  "lapses" integer NOT NULL DEFAULT 0,
+  "due_at" timestamptz NOT NULL,
+  "last_reviewed_at" timestamptz,
+  PRIMARY KEY ("user_id", "flashcard_id")
+);
+CREATE INDEX "index_reviews_user_due" ON "reviews" ("user_id", "due_at");
+
+ALTER TABLE "reviews" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+ALTER TABLE "reviews" ADD FOREIGN KEY ("flashcard_id") REFERENCES "flashcards" ("id") ON DELETE CASCADE;
+
+CREATE TABLE "sessions" (
+  "id" uuid PRIMARY KEY,
+  "user_id" uuid NOT NULL,
+  "refresh_hash" text NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "expires_at" timestamptz NOT NULL,
+  "revoked_at" timestamptz
+);
+CREATE INDEX "index_sessions_user" ON "sessions" ("user_id");
+
+ALTER TABLE "sessions" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
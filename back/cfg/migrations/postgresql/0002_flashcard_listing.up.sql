@@ -0,0 +1,19 @@
+ALTER TABLE "flashcards" ADD COLUMN "created_at" timestamptz NOT NULL DEFAULT now();
+ALTER TABLE "flashcards" ADD COLUMN "updated_at" timestamptz NOT NULL DEFAULT now();
+UPDATE "flashcards" SET "word" = '' WHERE "word" IS NULL;
+
+DROP INDEX IF EXISTS "index_flashcards_owner";
+CREATE INDEX "index_flashcards_owner_word" ON "flashcards" ("owner", "word", "id");
+CREATE INDEX "index_flashcards_owner_created" ON "flashcards" ("owner", "created_at", "id");
+CREATE INDEX "index_flashcards_owner_updated" ON "flashcards" ("owner", "updated_at", "id");
+CREATE INDEX "index_flashcards_owner_prefix" ON "flashcards" ("owner", lower("word") text_pattern_ops);
+
+CREATE TABLE "flashcard_tags" (
+  "flashcard_id" uuid NOT NULL,
+  "tag" varchar(50) NOT NULL,
+  PRIMARY KEY ("flashcard_id", "tag")
+);
+CREATE INDEX "index_flashcard_tags_tag" ON "flashcard_tags" ("tag", "flashcard_id");
+
+ALTER TABLE "flashcard_tags" ADD FOREIGN KEY ("flashcard_id") REFERENCES "flashcards" ("id") ON DELETE CASCADE;
+CREATE INDEX "index_flashcard_decks_flashcard" ON "flashcard_decks" ("flashcard_id");
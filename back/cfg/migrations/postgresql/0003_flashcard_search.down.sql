@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS "index_flashcards_meaning_trgm";
+DROP INDEX IF EXISTS "index_flashcards_word_trgm";
+DROP INDEX IF EXISTS "index_flashcards_document";
+
+DROP FUNCTION IF EXISTS "flashcard_document"(text, text, text[]);
+DROP FUNCTION IF EXISTS "flashcard_unaccent"(text);
+DROP TEXT SEARCH CONFIGURATION IF EXISTS "flashcards";
+
+-- the extensions are left installed, other schemas may use them
@@ -0,0 +1,28 @@
+-- The extensions are shipped with the contrib package, creating them takes
+-- a superuser or the owner of the database.
+CREATE EXTENSION IF NOT EXISTS "unaccent";
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
+
+-- Cards mix the native and the target language, so words are not stemmed,
+-- only unaccented and lowercased.
+CREATE TEXT SEARCH CONFIGURATION "flashcards" (COPY = simple);
+ALTER TEXT SEARCH CONFIGURATION "flashcards"
+    ALTER MAPPING FOR hword, hword_part, word WITH unaccent, simple;
+
+-- unaccent() depends on the search path, the wrappers pin the dictionary and
+-- the configuration so they can be indexed.
+CREATE FUNCTION "flashcard_unaccent"(text) RETURNS text
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+    AS $$ SELECT public.unaccent('public.unaccent'::regdictionary, lower($1)) $$;
+
+CREATE FUNCTION "flashcard_document"(word text, meaning text, usage text[]) RETURNS tsvector
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE
+    AS $$
+        SELECT setweight(to_tsvector('public.flashcards'::regconfig, coalesce(word, '')), 'A')
+            || setweight(to_tsvector('public.flashcards'::regconfig, coalesce(meaning, '')), 'B')
+            || setweight(to_tsvector('public.flashcards'::regconfig, coalesce(array_to_string(usage, ' '), '')), 'C')
+    $$;
+
+CREATE INDEX "index_flashcards_document" ON "flashcards" USING gin (flashcard_document("word", "meaning", "usage"));
+CREATE INDEX "index_flashcards_word_trgm" ON "flashcards" USING gin (flashcard_unaccent("word") gin_trgm_ops);
+CREATE INDEX "index_flashcards_meaning_trgm" ON "flashcards" USING gin (flashcard_unaccent("meaning") gin_trgm_ops);
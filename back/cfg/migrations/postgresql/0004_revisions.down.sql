@@ -0,0 +1,2 @@
+ALTER TABLE "decks" DROP COLUMN "version";
+ALTER TABLE "flashcards" DROP COLUMN "version";
@@ -0,0 +1,19 @@
+-- revisions is the append-only history of the flashcards and the decks. Every
+-- change adds a row with the field-level diff and the state after the change,
+-- the rows outlive the entity and go only with its owner.
+CREATE TABLE "revisions" (
+  "id" uuid PRIMARY KEY,
+  "entity_type" varchar(20) NOT NULL CHECK ("entity_type" IN ('flashcard', 'deck')),
+  "entity_id" uuid NOT NULL,
+  "owner" uuid NOT NULL,
+  "version" bigint NOT NULL,
+  "action" varchar(20) NOT NULL CHECK ("action" IN ('create', 'update', 'delete', 'restore')),
+  "actor_id" uuid NOT NULL,
+  "request_id" varchar(200) NOT NULL DEFAULT '',
+  "changes" text NOT NULL,
+  "snapshot" text NOT NULL,
+  "created_at" timestamptz NOT NULL
+);
+CREATE INDEX "index_revisions_entity" ON "revisions" ("entity_id", "created_at");
+
+ALTER TABLE "revisions" ADD FOREIGN KEY ("owner") REFERENCES "users" ("id") ON DELETE CASCADE;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS reviews;
+DROP TABLE IF EXISTS flashcard_decks;
+DROP TABLE IF EXISTS decks;
+DROP TABLE IF EXISTS flashcards;
+DROP TABLE IF EXISTS users;
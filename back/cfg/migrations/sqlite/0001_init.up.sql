@@ -0,0 +1,53 @@
+CREATE TABLE IF NOT EXISTS users (
+  id text PRIMARY KEY,
+  login text UNIQUE,
+  password text,
+  role text NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'teacher', 'admin')),
+  disabled_at datetime,
+  password_reset_required boolean NOT NULL DEFAULT false
+);
+
+CREATE TABLE IF NOT EXISTS flashcards (
+  id text PRIMARY KEY,
+  word text,
+  meaning text,
+  usage text,
+  owner text NOT NULL REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS index_flashcards_owner ON flashcards (owner);
+
+CREATE TABLE IF NOT EXISTS decks (
+  id text PRIMARY KEY,
+  name text,
+  owner text NOT NULL REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS index_decks_owner ON decks (owner);
+
+CREATE TABLE IF NOT EXISTS flashcard_decks (
+  deck_id text NOT NULL REFERENCES decks (id) ON DELETE CASCADE,
+  flashcard_id text NOT NULL REFERENCES flashcards (id) ON DELETE CASCADE,
+  PRIMARY KEY (deck_id, flashcard_id)
+);
+
+CREATE TABLE IF NOT EXISTS reviews (
+  user_id text NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  flashcard_id text NOT NULL REFERENCES flashcards (id) ON DELETE CASCADE,
+  ease_factor real NOT NULL DEFAULT 2.5,
+  interval_days integer NOT NULL DEFAULT 0,
+  repetitions integer NOT NULL DEFAULT 0,
+  lapses integer NOT NULL DEFAULT 0,
+  due_at datetime NOT NULL,
+  last_reviewed_at datetime,
+  PRIMARY KEY (user_id, flashcard_id)
+);
+CREATE INDEX IF NOT EXISTS index_reviews_user_due ON reviews (user_id, due_at);
+
+CREATE TABLE IF NOT EXISTS sessions (
+  id text PRIMARY KEY,
+  user_id text NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  refresh_hash text NOT NULL,
+  created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  expires_at datetime NOT NULL,
+  revoked_at datetime
+);
+CREATE INDEX IF NOT EXISTS index_sessions_user ON sessions (user_id);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS index_flashcard_decks_flashcard;
+DROP TABLE IF EXISTS flashcard_tags;
+
+DROP INDEX IF EXISTS index_flashcards_owner_updated;
+DROP INDEX IF EXISTS index_flashcards_owner_created;
+DROP INDEX IF EXISTS index_flashcards_owner_word;
+CREATE INDEX IF NOT EXISTS index_flashcards_owner ON flashcards (owner);
+
+ALTER TABLE flashcards DROP COLUMN updated_at;
+ALTER TABLE flashcards DROP COLUMN created_at;
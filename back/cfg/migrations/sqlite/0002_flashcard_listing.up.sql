@@ -0,0 +1,19 @@
+-- columns added to an existing table can't default to the current time, the
+-- times of new cards are always set on insert
+ALTER TABLE flashcards ADD COLUMN created_at datetime NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+ALTER TABLE flashcards ADD COLUMN updated_at datetime NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+UPDATE flashcards SET created_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'), updated_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now');
+UPDATE flashcards SET word = '' WHERE word IS NULL;
+
+DROP INDEX IF EXISTS index_flashcards_owner;
+CREATE INDEX IF NOT EXISTS index_flashcards_owner_word ON flashcards (owner, word, id);
+CREATE INDEX IF NOT EXISTS index_flashcards_owner_created ON flashcards (owner, created_at, id);
+CREATE INDEX IF NOT EXISTS index_flashcards_owner_updated ON flashcards (owner, updated_at, id);
+
+CREATE TABLE IF NOT EXISTS flashcard_tags (
+  flashcard_id text NOT NULL REFERENCES flashcards (id) ON DELETE CASCADE,
+  tag text NOT NULL,
+  PRIMARY KEY (flashcard_id, tag)
+);
+CREATE INDEX IF NOT EXISTS index_flashcard_tags_tag ON flashcard_tags (tag, flashcard_id);
+CREATE INDEX IF NOT EXISTS index_flashcard_decks_flashcard ON flashcard_decks (flashcard_id);
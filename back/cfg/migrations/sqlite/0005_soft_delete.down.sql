@@ -0,0 +1,6 @@
+DELETE FROM decks WHERE deleted_at IS NOT NULL;
+DELETE FROM flashcards WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS index_decks_deleted;
+DROP INDEX IF EXISTS index_flashcards_deleted;
+ALTER TABLE decks DROP COLUMN deleted_at;
+ALTER TABLE flashcards DROP COLUMN deleted_at;
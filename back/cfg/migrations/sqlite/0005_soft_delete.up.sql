@@ -0,0 +1,5 @@
+-- deleted cards and decks stay in the trash until the retention window passes
+ALTER TABLE flashcards ADD COLUMN deleted_at datetime;
+ALTER TABLE decks ADD COLUMN deleted_at datetime;
+CREATE INDEX IF NOT EXISTS index_flashcards_deleted ON flashcards (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS index_decks_deleted ON decks (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,17 @@
+-- revisions is the append-only history of the flashcards and the decks. Every
+-- change adds a row with the field-level diff and the state after the change,
+-- the rows outlive the entity and go only with its owner.
+CREATE TABLE IF NOT EXISTS revisions (
+  id text PRIMARY KEY,
+  entity_type text NOT NULL CHECK (entity_type IN ('flashcard', 'deck')),
+  entity_id text NOT NULL,
+  owner text NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  version integer NOT NULL,
+  action text NOT NULL CHECK (action IN ('create', 'update', 'delete', 'restore')),
+  actor_id text NOT NULL,
+  request_id text NOT NULL DEFAULT '',
+  changes text NOT NULL,
+  snapshot text NOT NULL,
+  created_at datetime NOT NULL
+);
+CREATE INDEX IF NOT EXISTS index_revisions_entity ON revisions (entity_id, created_at);
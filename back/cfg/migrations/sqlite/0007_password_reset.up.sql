@@ -0,0 +1,3 @@
+-- an administrator resets a password with a one-time token, only its hash is
+-- kept until the new password is set
+ALTER TABLE users ADD COLUMN password_reset_hash text NOT NULL DEFAULT '';
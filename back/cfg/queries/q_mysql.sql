@@ -3,85 +3,224 @@
 INSERT INTO users 
     (id, login, password) 
     VALUES 
-    ($1, $2, $3);
+    (?, ?, ?);
 
--- name: SelectUser :exec
+-- name: SelectUserByLogin :one
 SELECT * FROM users 
-    WHERE id = $1 AND login = $2;
+    WHERE login = ?;
 
--- name: UpdateUserLogin :exec
-UPDATE users SET login = $1
-    WHERE id = $2;
+-- name: SelectUserByID :one
+SELECT * FROM users 
+    WHERE id = ?;
+
+-- name: UpdateUserLogin :execrows
+UPDATE users SET login = ?
+    WHERE id = ?;
+
+-- name: UpdateUserPassword :execrows
+UPDATE users SET password = ?, password_reset_required = false, password_reset_hash = ''
+    WHERE id = ?;
+
+-- name: SelectUsers :many
+SELECT * FROM users
+    ORDER BY login
+    LIMIT ? OFFSET ?;
+
+-- name: UpdateUserRole :execrows
+UPDATE users SET role = ?
+    WHERE id = ?;
+
+-- name: UpdateUserDisabled :execrows
+UPDATE users SET disabled_at = ?
+    WHERE id = ?;
 
--- name: UpdateUserPassword :exec
-UPDATE users SET password = $1
-    WHERE id = $2;
+-- name: RequirePasswordReset :execrows
+UPDATE users SET password_reset_required = true, password_reset_hash = ?
+    WHERE id = ?;
 
 -- name: DeleteUser :exec 
 DELETE FROM users 
-    WHERE id = $1;
+    WHERE id = ?;
 
 -- Flashcards
 -- name: CreateFlashcard :exec
-INSERT INTO flashcards 
-    (id, word, meaning, usage) 
-    VALUES ($1, $2, $3, $4);
+INSERT INTO flashcards
+    (id, word, meaning, `usage`, owner, created_at, updated_at)
+    VALUES
+    (?, ?, ?, ?, ?, ?, ?);
 
--- name: SelectFlashcards :many
+-- name: SelectFlashcardByID :one
 SELECT * FROM flashcards 
-    WHERE id = $1 AND 
-    word = $2 AND 
-    meaning = $3 AND 
-    usage = $4;
+    WHERE id = ? AND owner = ? AND deleted_at IS NULL;
 
--- name: UpdateFlashcard :exec
-UPDATE flashcards SET
-    id = $1,
-    word = $2,
-    meaning = $3,
-    usage = $4
-    WHERE id = $5;
+-- name: SelectFlashcardByMeaning :many
+SELECT f.* FROM flashcards AS f
+    INNER JOIN flashcard_decks AS d
+        ON d.flashcard_id = f.id
+    WHERE d.deck_id = ? AND f.owner = ? AND f.meaning = ? AND f.deleted_at IS NULL;
+
+-- name: SelectFlashcardByWord :many
+SELECT f.* FROM flashcards AS f
+    INNER JOIN flashcard_decks AS d
+        ON d.flashcard_id = f.id
+    WHERE d.deck_id = ? AND f.owner = ? AND f.word = ? AND f.deleted_at IS NULL;
 
--- name: DeleteFlashcard :exec
-DELETE FROM flashcards 
-    WHERE id = $1;
+-- name: UpdateFlashcard :execrows
+UPDATE flashcards SET
+    word = ?,
+    meaning = ?,
+    `usage` = ?,
+    updated_at = ?,
+    version = version + 1
+    WHERE id = ? AND owner = ? AND version = ? AND deleted_at IS NULL;
+
+-- name: DeleteFlashcard :execrows
+UPDATE flashcards SET deleted_at = ?
+    WHERE id = ? AND owner = ? AND deleted_at IS NULL;
+
+-- name: RestoreFlashcard :execrows
+UPDATE flashcards SET deleted_at = NULL
+    WHERE id = ? AND owner = ? AND deleted_at IS NOT NULL;
+
+-- name: SelectDeletedFlashcards :many
+SELECT * FROM flashcards
+    WHERE owner = ? AND deleted_at IS NOT NULL
+    ORDER BY deleted_at DESC, id;
+
+-- name: SelectDeletedFlashcard :one
+SELECT * FROM flashcards
+    WHERE id = ? AND owner = ? AND deleted_at IS NOT NULL;
+
+-- name: PurgeFlashcards :execrows
+DELETE FROM flashcards
+    WHERE deleted_at < ?;
 
 -- Decks
 -- name: CreateDeck :exec
 INSERT INTO decks 
     (id, name, owner)
     VALUES
-    ($1, $2, $3);
+    (?, ?, ?);
 
 -- name: SelectOwnerDecks :many
 SELECT * FROM decks
-    WHERE owner = $1;
+    WHERE owner = ? AND deleted_at IS NULL
+    ORDER BY name;
 
 -- name: SelectDeck :one
 SELECT * FROM decks 
-    WHERE id = $1;
+    WHERE id = ? AND owner = ? AND deleted_at IS NULL;
 
 -- name: SelectDecksByName :many
 SELECT * FROM decks
-    WHERE name = $1;
+    WHERE owner = ? AND name = ? AND deleted_at IS NULL;
 
--- name: EditDeckProps :exec
+-- name: EditDeckProps :execrows
 UPDATE decks SET
-    name = $1
-    WHERE id = $2;
+    name = ?,
+    version = version + 1
+    WHERE id = ? AND owner = ? AND version = ? AND deleted_at IS NULL;
+
+-- name: DeleteDeck :execrows
+UPDATE decks SET deleted_at = ?
+    WHERE id = ? AND owner = ? AND deleted_at IS NULL;
+
+-- name: RestoreDeck :execrows
+UPDATE decks SET deleted_at = NULL
+    WHERE id = ? AND owner = ? AND deleted_at IS NOT NULL;
 
--- name: DeleteDeck :exec
+-- name: SelectDeletedDecks :many
+SELECT * FROM decks
+    WHERE owner = ? AND deleted_at IS NOT NULL
+    ORDER BY deleted_at DESC, id;
+
+-- name: SelectDeletedDeck :one
+SELECT * FROM decks
+    WHERE id = ? AND owner = ? AND deleted_at IS NOT NULL;
+
+-- name: PurgeDecks :execrows
 DELETE FROM decks
-    WHERE id = $1;
+    WHERE deleted_at < ?;
 
 -- name: AddToDeck :exec
 INSERT INTO flashcard_decks
     (deck_id, flashcard_id)
     VALUES
-    ($1, $2); 
+    (?, ?)
+    ON DUPLICATE KEY UPDATE deck_id = deck_id;
 
--- name: DeleteFromDeck :exec
+-- name: DeleteFromDeck :execrows
 DELETE FROM flashcard_decks
-    WHERE flashcard_id = $1 AND
-        deck_id = $2;
-    
\ No newline at end of file
+    WHERE flashcard_id = ? AND
+        deck_id = ?;
+
+-- name: SelectDeckFlashcards :many
+SELECT f.* FROM flashcards AS f
+    INNER JOIN flashcard_decks AS d
+        ON d.flashcard_id = f.id
+    WHERE d.deck_id = ? AND f.deleted_at IS NULL;
+
+-- History
+-- name: CreateRevision :exec
+INSERT INTO revisions
+    (id, entity_type, entity_id, owner, version, action, actor_id, request_id, changes, snapshot, created_at)
+    VALUES
+    (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: SelectRevisions :many
+SELECT * FROM revisions
+    WHERE entity_type = ? AND entity_id = ? AND owner = ?
+    ORDER BY created_at, version;
+
+-- name: SelectRevision :one
+SELECT * FROM revisions
+    WHERE id = ? AND entity_type = ? AND entity_id = ? AND owner = ?;
+
+-- Reviews
+-- name: SelectReview :one
+SELECT * FROM reviews
+    WHERE user_id = ? AND flashcard_id = ?;
+
+-- name: SelectDueReviews :many
+SELECT r.*, f.word, f.meaning, f.`usage` FROM reviews AS r
+    INNER JOIN flashcards AS f
+        ON f.id = r.flashcard_id
+    WHERE r.user_id = ? AND r.due_at <= ? AND f.deleted_at IS NULL
+    ORDER BY r.due_at
+    LIMIT ?;
+
+-- name: UpsertReview :exec
+INSERT INTO reviews
+    (user_id, flashcard_id, ease_factor, interval_days, repetitions, lapses, due_at, last_reviewed_at)
+    VALUES
+    (?, ?, ?, ?, ?, ?, ?, ?)
+    ON DUPLICATE KEY UPDATE
+        ease_factor = VALUES(ease_factor),
+        interval_days = VALUES(interval_days),
+        repetitions = VALUES(repetitions),
+        lapses = VALUES(lapses),
+        due_at = VALUES(due_at),
+        last_reviewed_at = VALUES(last_reviewed_at);
+
+-- Sessions
+-- name: CreateSession :exec
+INSERT INTO sessions
+    (id, user_id, refresh_hash, expires_at)
+    VALUES
+    (?, ?, ?, ?);
+
+-- name: SelectSession :one
+SELECT * FROM sessions
+    WHERE id = ?;
+
+-- name: RotateSession :execrows
+UPDATE sessions SET refresh_hash = ?, expires_at = ?
+    WHERE id = ? AND refresh_hash = ? AND revoked_at IS NULL;
+
+-- name: RevokeSession :exec
+UPDATE sessions SET revoked_at = NOW(6)
+    WHERE id = ? AND revoked_at IS NULL;
+
+-- name: RevokeUserSessions :exec
+UPDATE sessions SET revoked_at = NOW(6)
+    WHERE user_id = ? AND revoked_at IS NULL;
@@ -17,13 +17,29 @@ SELECT * FROM users
 SELECT * FROM users 
     WHERE id = $1;
 
--- name: UpdateUserLogin :one
+-- name: UpdateUserLogin :execrows
 UPDATE users SET login = $1
-    WHERE id = $2
-    RETURNING id, login;
+    WHERE id = $2;
+
+-- name: UpdateUserPassword :execrows
+UPDATE users SET password = $1, password_reset_required = false, password_reset_hash = ''
+    WHERE id = $2;
+
+-- name: SelectUsers :many
+SELECT * FROM users
+    ORDER BY login
+    LIMIT $1 OFFSET $2;
+
+-- name: UpdateUserRole :execrows
+UPDATE users SET role = $1
+    WHERE id = $2;
+
+-- name: UpdateUserDisabled :execrows
+UPDATE users SET disabled_at = $1
+    WHERE id = $2;
 
--- name: UpdateUserPassword :exec
-UPDATE users SET password = $1
+-- name: RequirePasswordReset :execrows
+UPDATE users SET password_reset_required = true, password_reset_hash = $1
     WHERE id = $2;
 
 -- name: DeleteUser :exec 
@@ -33,37 +49,74 @@ DELETE FROM users
 -- Flashcards
 -- name: CreateFlashcard :one
 INSERT INTO flashcards
-    (id, word, meaning, usage)
+    (id, word, meaning, usage, owner, created_at, updated_at)
     VALUES
-    ($1, $2, $3, $4)
+    ($1, $2, $3, $4, $5, $6, $7)
     RETURNING word, meaning, usage;
 
 -- name: SelectFlashcardByID :one
 SELECT * FROM flashcards 
-    WHERE id = $1;
+    WHERE id = $1 AND owner = $2 AND deleted_at IS NULL;
 
 -- name: SelectFlashcardByMeaning :many
-SELECT * FROM flashcards AS f
+SELECT f.* FROM flashcards AS f
     INNER JOIN flashcard_decks AS d
-        ON d.deck_id = $1
-    WHERE meaning = $2;
+        ON d.flashcard_id = f.id
+    WHERE d.deck_id = $1 AND f.owner = $2 AND f.meaning = $3 AND f.deleted_at IS NULL;
 
 -- name: SelectFlashcardByWord :many
-SELECT * FROM flashcards AS f
+SELECT f.* FROM flashcards AS f
     INNER JOIN flashcard_decks AS d
-        ON d.deck_id = $1
-    WHERE word = $2;
+        ON d.flashcard_id = f.id
+    WHERE d.deck_id = $1 AND f.owner = $2 AND f.word = $3 AND f.deleted_at IS NULL;
 
--- name: UpdateFlashcard :exec
+-- name: UpdateFlashcard :execrows
 UPDATE flashcards SET
     word = $1,
     meaning = $2,
-    usage = $3
-    WHERE id = $4;
-
--- name: DeleteFlashcard :exec
-DELETE FROM flashcards 
-    WHERE id = $1;
+    usage = $3,
+    updated_at = $4,
+    version = version + 1
+    WHERE id = $5 AND owner = $6 AND version = $7 AND deleted_at IS NULL;
+
+-- name: DeleteFlashcard :execrows
+UPDATE flashcards SET deleted_at = $1
+    WHERE id = $2 AND owner = $3 AND deleted_at IS NULL;
+
+-- name: RestoreFlashcard :execrows
+UPDATE flashcards SET deleted_at = NULL
+    WHERE id = $1 AND owner = $2 AND deleted_at IS NOT NULL;
+
+-- name: SelectDeletedFlashcards :many
+SELECT * FROM flashcards
+    WHERE owner = $1 AND deleted_at IS NOT NULL
+    ORDER BY deleted_at DESC, id;
+
+-- name: SelectDeletedFlashcard :one
+SELECT * FROM flashcards
+    WHERE id = $1 AND owner = $2 AND deleted_at IS NOT NULL;
+
+-- name: PurgeFlashcards :execrows
+DELETE FROM flashcards
+    WHERE deleted_at < $1;
+
+-- name: SearchFlashcards :many
+SELECT f.*,
+    (ts_rank(flashcard_document(f.word, f.meaning, f.usage), q.query)
+        + greatest(similarity(flashcard_unaccent(f.word), q.term), similarity(flashcard_unaccent(f.meaning), q.term)))::float8 AS rank,
+    ts_headline('public.flashcards',
+        replace(replace(replace(concat_ws(' ', f.word, f.meaning, array_to_string(f.usage, ' ')),
+            '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+        q.query, 'MaxFragments=2, MinWords=3, MaxWords=12')::text AS snippet
+    FROM flashcards AS f,
+        (SELECT websearch_to_tsquery('public.flashcards', sqlc.arg(search)::text) AS query,
+            flashcard_unaccent(sqlc.arg(search)::text) AS term) AS q
+    WHERE f.owner = sqlc.arg(owner) AND f.deleted_at IS NULL AND (
+        flashcard_document(f.word, f.meaning, f.usage) @@ q.query
+        OR flashcard_unaccent(f.word) % q.term
+        OR flashcard_unaccent(f.meaning) % q.term)
+    ORDER BY rank DESC, f.id
+    LIMIT sqlc.arg(max_results);
 
 -- Decks
 -- name: CreateDeck :one
@@ -75,33 +128,123 @@ INSERT INTO decks
 
 -- name: SelectOwnerDecks :many
 SELECT * FROM decks
-    WHERE owner = $1;
+    WHERE owner = $1 AND deleted_at IS NULL
+    ORDER BY name;
 
 -- name: SelectDeck :one
 SELECT * FROM decks 
-    WHERE id = $1;
+    WHERE id = $1 AND owner = $2 AND deleted_at IS NULL;
 
 -- name: SelectDecksByName :many
 SELECT * FROM decks
-    WHERE name = $1;
+    WHERE owner = $1 AND name = $2 AND deleted_at IS NULL;
 
--- name: EditDeckProps :exec
+-- name: EditDeckProps :execrows
 UPDATE decks SET
-    name = $1
-    WHERE id = $2;
+    name = $1,
+    version = version + 1
+    WHERE id = $2 AND owner = $3 AND version = $4 AND deleted_at IS NULL;
+
+-- name: DeleteDeck :execrows
+UPDATE decks SET deleted_at = $1
+    WHERE id = $2 AND owner = $3 AND deleted_at IS NULL;
 
--- name: DeleteDeck :exec
+-- name: RestoreDeck :execrows
+UPDATE decks SET deleted_at = NULL
+    WHERE id = $1 AND owner = $2 AND deleted_at IS NOT NULL;
+
+-- name: SelectDeletedDecks :many
+SELECT * FROM decks
+    WHERE owner = $1 AND deleted_at IS NOT NULL
+    ORDER BY deleted_at DESC, id;
+
+-- name: SelectDeletedDeck :one
+SELECT * FROM decks
+    WHERE id = $1 AND owner = $2 AND deleted_at IS NOT NULL;
+
+-- name: PurgeDecks :execrows
 DELETE FROM decks
-    WHERE id = $1;
+    WHERE deleted_at < $1;
 
 -- name: AddToDeck :exec
 INSERT INTO flashcard_decks
     (deck_id, flashcard_id)
     VALUES
-    ($1, $2); 
+    ($1, $2)
+    ON CONFLICT DO NOTHING;
 
--- name: DeleteFromDeck :exec
+-- name: DeleteFromDeck :execrows
 DELETE FROM flashcard_decks
     WHERE flashcard_id = $1 AND
         deck_id = $2;
-    
\ No newline at end of file
+
+-- name: SelectDeckFlashcards :many
+SELECT f.* FROM flashcards AS f
+    INNER JOIN flashcard_decks AS d
+        ON d.flashcard_id = f.id
+    WHERE d.deck_id = $1 AND f.deleted_at IS NULL;
+
+-- History
+-- name: CreateRevision :exec
+INSERT INTO revisions
+    (id, entity_type, entity_id, owner, version, action, actor_id, request_id, changes, snapshot, created_at)
+    VALUES
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+
+-- name: SelectRevisions :many
+SELECT * FROM revisions
+    WHERE entity_type = $1 AND entity_id = $2 AND owner = $3
+    ORDER BY created_at, version;
+
+-- name: SelectRevision :one
+SELECT * FROM revisions
+    WHERE id = $1 AND entity_type = $2 AND entity_id = $3 AND owner = $4;
+
+-- Reviews
+-- name: SelectReview :one
+SELECT * FROM reviews
+    WHERE user_id = $1 AND flashcard_id = $2;
+
+-- name: SelectDueReviews :many
+SELECT r.*, f.word, f.meaning, f.usage FROM reviews AS r
+    INNER JOIN flashcards AS f
+        ON f.id = r.flashcard_id
+    WHERE r.user_id = $1 AND r.due_at <= $2 AND f.deleted_at IS NULL
+    ORDER BY r.due_at
+    LIMIT $3;
+
+-- name: UpsertReview :exec
+INSERT INTO reviews
+    (user_id, flashcard_id, ease_factor, interval_days, repetitions, lapses, due_at, last_reviewed_at)
+    VALUES
+    ($1, $2, $3, $4, $5, $6, $7, $8)
+    ON CONFLICT (user_id, flashcard_id) DO UPDATE SET
+        ease_factor = EXCLUDED.ease_factor,
+        interval_days = EXCLUDED.interval_days,
+        repetitions = EXCLUDED.repetitions,
+        lapses = EXCLUDED.lapses,
+        due_at = EXCLUDED.due_at,
+        last_reviewed_at = EXCLUDED.last_reviewed_at;
+
+-- Sessions
+-- name: CreateSession :exec
+INSERT INTO sessions
+    (id, user_id, refresh_hash, expires_at)
+    VALUES
+    ($1, $2, $3, $4);
+
+-- name: SelectSession :one
+SELECT * FROM sessions
+    WHERE id = $1;
+
+-- name: RotateSession :execrows
+UPDATE sessions SET refresh_hash = $1, expires_at = $2
+    WHERE id = $3 AND refresh_hash = $4 AND revoked_at IS NULL;
+
+-- name: RevokeSession :exec
+UPDATE sessions SET revoked_at = now()
+    WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeUserSessions :exec
+UPDATE sessions SET revoked_at = now()
+    WHERE user_id = $1 AND revoked_at IS NULL;
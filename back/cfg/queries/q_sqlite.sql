@@ -0,0 +1,226 @@
+-- User 
+-- name: CreateUser :exec
+INSERT INTO users 
+    (id, login, password) 
+    VALUES 
+    (?, ?, ?);
+
+-- name: SelectUserByLogin :one
+SELECT * FROM users 
+    WHERE login = ?;
+
+-- name: SelectUserByID :one
+SELECT * FROM users 
+    WHERE id = ?;
+
+-- name: UpdateUserLogin :execrows
+UPDATE users SET login = ?
+    WHERE id = ?;
+
+-- name: UpdateUserPassword :execrows
+UPDATE users SET password = ?, password_reset_required = false, password_reset_hash = ''
+    WHERE id = ?;
+
+-- name: SelectUsers :many
+SELECT * FROM users
+    ORDER BY login
+    LIMIT ? OFFSET ?;
+
+-- name: UpdateUserRole :execrows
+UPDATE users SET role = ?
+    WHERE id = ?;
+
+-- name: UpdateUserDisabled :execrows
+UPDATE users SET disabled_at = ?
+    WHERE id = ?;
+
+-- name: RequirePasswordReset :execrows
+UPDATE users SET password_reset_required = true, password_reset_hash = ?
+    WHERE id = ?;
+
+-- name: DeleteUser :exec 
+DELETE FROM users 
+    WHERE id = ?;
+
+-- Flashcards
+-- name: CreateFlashcard :exec
+INSERT INTO flashcards
+    (id, word, meaning, usage, owner, created_at, updated_at)
+    VALUES
+    (?, ?, ?, ?, ?, ?, ?);
+
+-- name: SelectFlashcardByID :one
+SELECT * FROM flashcards 
+    WHERE id = ? AND owner = ? AND deleted_at IS NULL;
+
+-- name: SelectFlashcardByMeaning :many
+SELECT f.* FROM flashcards AS f
+    INNER JOIN flashcard_decks AS d
+        ON d.flashcard_id = f.id
+    WHERE d.deck_id = ? AND f.owner = ? AND f.meaning = ? AND f.deleted_at IS NULL;
+
+-- name: SelectFlashcardByWord :many
+SELECT f.* FROM flashcards AS f
+    INNER JOIN flashcard_decks AS d
+        ON d.flashcard_id = f.id
+    WHERE d.deck_id = ? AND f.owner = ? AND f.word = ? AND f.deleted_at IS NULL;
+
+-- name: UpdateFlashcard :execrows
+UPDATE flashcards SET
+    word = ?,
+    meaning = ?,
+    usage = ?,
+    updated_at = ?,
+    version = version + 1
+    WHERE id = ? AND owner = ? AND version = ? AND deleted_at IS NULL;
+
+-- name: DeleteFlashcard :execrows
+UPDATE flashcards SET deleted_at = ?
+    WHERE id = ? AND owner = ? AND deleted_at IS NULL;
+
+-- name: RestoreFlashcard :execrows
+UPDATE flashcards SET deleted_at = NULL
+    WHERE id = ? AND owner = ? AND deleted_at IS NOT NULL;
+
+-- name: SelectDeletedFlashcards :many
+SELECT * FROM flashcards
+    WHERE owner = ? AND deleted_at IS NOT NULL
+    ORDER BY deleted_at DESC, id;
+
+-- name: SelectDeletedFlashcard :one
+SELECT * FROM flashcards
+    WHERE id = ? AND owner = ? AND deleted_at IS NOT NULL;
+
+-- name: PurgeFlashcards :execrows
+DELETE FROM flashcards
+    WHERE deleted_at < ?;
+
+-- Decks
+-- name: CreateDeck :exec
+INSERT INTO decks 
+    (id, name, owner)
+    VALUES
+    (?, ?, ?);
+
+-- name: SelectOwnerDecks :many
+SELECT * FROM decks
+    WHERE owner = ? AND deleted_at IS NULL
+    ORDER BY name;
+
+-- name: SelectDeck :one
+SELECT * FROM decks 
+    WHERE id = ? AND owner = ? AND deleted_at IS NULL;
+
+-- name: SelectDecksByName :many
+SELECT * FROM decks
+    WHERE owner = ? AND name = ? AND deleted_at IS NULL;
+
+-- name: EditDeckProps :execrows
+UPDATE decks SET
+    name = ?,
+    version = version + 1
+    WHERE id = ? AND owner = ? AND version = ? AND deleted_at IS NULL;
+
+-- name: DeleteDeck :execrows
+UPDATE decks SET deleted_at = ?
+    WHERE id = ? AND owner = ? AND deleted_at IS NULL;
+
+-- name: RestoreDeck :execrows
+UPDATE decks SET deleted_at = NULL
+    WHERE id = ? AND owner = ? AND deleted_at IS NOT NULL;
+
+-- name: SelectDeletedDecks :many
+SELECT * FROM decks
+    WHERE owner = ? AND deleted_at IS NOT NULL
+    ORDER BY deleted_at DESC, id;
+
+-- name: SelectDeletedDeck :one
+SELECT * FROM decks
+    WHERE id = ? AND owner = ? AND deleted_at IS NOT NULL;
+
+-- name: PurgeDecks :execrows
+DELETE FROM decks
+    WHERE deleted_at < ?;
+
+-- name: AddToDeck :exec
+INSERT INTO flashcard_decks
+    (deck_id, flashcard_id)
+    VALUES
+    (?, ?)
+    ON CONFLICT DO NOTHING;
+
+-- name: DeleteFromDeck :execrows
+DELETE FROM flashcard_decks
+    WHERE flashcard_id = ? AND
+        deck_id = ?;
+
+-- name: SelectDeckFlashcards :many
+SELECT f.* FROM flashcards AS f
+    INNER JOIN flashcard_decks AS d
+        ON d.flashcard_id = f.id
+    WHERE d.deck_id = ? AND f.deleted_at IS NULL;
+
+-- History
+-- name: CreateRevision :exec
+INSERT INTO revisions
+    (id, entity_type, entity_id, owner, version, action, actor_id, request_id, changes, snapshot, created_at)
+    VALUES
+    (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: SelectRevisions :many
+SELECT * FROM revisions
+    WHERE entity_type = ? AND entity_id = ? AND owner = ?
+    ORDER BY created_at, version;
+
+-- name: SelectRevision :one
+SELECT * FROM revisions
+    WHERE id = ? AND entity_type = ? AND entity_id = ? AND owner = ?;
+
+-- Reviews
+-- name: SelectReview :one
+SELECT * FROM reviews
+    WHERE user_id = ? AND flashcard_id = ?;
+
+-- name: SelectDueReviews :many
+SELECT r.*, f.word, f.meaning, f.usage FROM reviews AS r
+    INNER JOIN flashcards AS f
+        ON f.id = r.flashcard_id
+    WHERE r.user_id = ? AND r.due_at <= ? AND f.deleted_at IS NULL
+    ORDER BY r.due_at
+    LIMIT ?;
+
+-- name: UpsertReview :exec
+INSERT INTO reviews
+    (user_id, flashcard_id, ease_factor, interval_days, repetitions, lapses, due_at, last_reviewed_at)
+    VALUES
+    (?, ?, ?, ?, ?, ?, ?, ?)
+    ON CONFLICT (user_id, flashcard_id) DO UPDATE SET
+        ease_factor = excluded.ease_factor,
+        interval_days = excluded.interval_days,
+        repetitions = excluded.repetitions,
+        lapses = excluded.lapses,
+        due_at = excluded.due_at,
+        last_reviewed_at = excluded.last_reviewed_at;
+
+-- Sessions
+-- name: CreateSession :exec
+INSERT INTO sessions
+    (id, user_id, refresh_hash, expires_at)
+    VALUES
+    (?, ?, ?, ?);
+
+-- name: SelectSession :one
+SELECT * FROM sessions
+    WHERE id = ?;
+
+-- name: RotateSession :execrows
+UPDATE sessions SET refresh_hash = ?, expires_at = ?
+    WHERE id = ? AND refresh_hash = ? AND revoked_at IS NULL;
+
+-- name: RevokeSession :exec
+UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+    WHERE id = ? AND revoked_at IS NULL;
+
+-- name: RevokeUserSessions :exec
+UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+    WHERE user_id = ? AND revoked_at IS NULL;
@@ -7,7 +7,14 @@ import (
 )
 
 func main() {
-	if err := app.StartApp(); err != nil {
+	var err error
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = app.Migrate(os.Args[2:])
+	} else {
+		err = app.StartApp()
+	}
+
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "error at application runtime: %s", err.Error())
 		os.Exit(1)
 	}
@@ -6,27 +6,68 @@ import (
 	"languago/infrastructure/logger"
 	"languago/infrastructure/logger/wrappers"
 	"languago/infrastructure/repository"
+	"languago/pkg/auth"
+	"languago/pkg/cache"
 	"log"
 	"os"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
+const (
+	defaultKeyID           = "default"
+	defaultIssuer          = "languago"
+	defaultAudience        = "languago"
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+	defaultTrashRetention  = 30 * 24 * time.Hour
+	defaultPurgeInterval   = time.Hour
+	defaultCacheEntries    = 10000
+	defaultCacheBytes      = 64 << 20
+	defaultUserCacheTTL    = 30 * time.Second
+	defaultDeckCacheTTL    = 5 * time.Minute
+	defaultCardCacheTTL    = 5 * time.Minute
+	defaultRESPAddress     = "localhost:6379"
+	defaultRESPPoolSize    = 10
+	defaultRESPTimeout     = 200 * time.Millisecond
+	defaultMaxRestarts     = 5
+	defaultMinBackoff      = time.Second
+	defaultMaxBackoff      = 30 * time.Second
+	defaultRestartReset    = time.Minute
+
+	CacheBackendMemory = "memory"
+	CacheBackendRESP   = "resp"
+)
+
 type (
 	AbstractConfig interface {
 		GetDatabaseConfig() AbstractDatabaseConfig
 		GetNodeConfig() AbstractNodeConfig
 		GetLoggerConfig() AbstractLoggerConfig
+		GetAuthConfig() AbstractAuthConfig
+		GetTrashConfig() AbstractTrashConfig
+		GetCacheConfig() AbstractCacheConfig
 	}
 
 	AbstractDatabaseConfig interface {
 		GetCredentials() repository.DBCredentials
 		IsMock() bool
+		// MigrateOnStart applies pending migrations when the node starts.
+		MigrateOnStart() bool
 	}
 
 	AbstractNodeConfig interface {
 		SetLogger(l logger.Logger)
 		GetServicesCfg() []AbstractServiceConfig
+		// GetMaxRestarts is how many crashes in a row a service is restarted
+		// after before the node is stopped, a service which ran for
+		// GetRestartReset starts the count over. The delay before a restart
+		// doubles from GetMinBackoff up to GetMaxBackoff.
+		GetMaxRestarts() int
+		GetMinBackoff() time.Duration
+		GetMaxBackoff() time.Duration
+		GetRestartReset() time.Duration
 	}
 
 	AbstractLoggerConfig interface {
@@ -37,6 +78,38 @@ type (
 		GetLogger() logger.Logger
 	}
 
+	AbstractAuthConfig interface {
+		GetCurrentKeyID() string
+		GetSigningKeys() []auth.KeyConfig
+		GetIssuer() string
+		GetAudience() string
+		GetAccessTokenTTL() time.Duration
+		GetRefreshTokenTTL() time.Duration
+		GetLeeway() time.Duration
+	}
+
+	// AbstractTrashConfig tells how long deleted cards and decks can be
+	// restored and how often the expired ones are purged.
+	AbstractTrashConfig interface {
+		GetRetention() time.Duration
+		GetPurgeInterval() time.Duration
+	}
+
+	// AbstractCacheConfig tells whether users, decks and flashcards read by
+	// ID are cached, where and how long each of them stays. The limits are
+	// those of the memory backend, the resp backend shares the cache of a
+	// Redis compatible server between the nodes.
+	AbstractCacheConfig interface {
+		Enabled() bool
+		GetBackend() string
+		GetRESPOptions() cache.RESPOptions
+		GetMaxEntries() int
+		GetMaxBytes() int64
+		GetUserTTL() time.Duration
+		GetDeckTTL() time.Duration
+		GetFlashcardTTL() time.Duration
+	}
+
 	AbstractServiceConfig interface {
 		ServiceName() string
 		GetHTTPAddress() string
@@ -46,19 +119,55 @@ type (
 		DatabaseCfg *DatabaseConfig
 		NodeCfg     *NodeConfig
 		LoggerCfg   *LoggerConfig
+		AuthCfg     *AuthConfig
+		TrashCfg    *TrashConfig
+		CacheCfg    *CacheConfig
 	}
 
 	DatabaseConfig struct {
 		isMock          bool
+		migrateOnStart  bool
 		DatabaseAddress string
 		DatabaseDriver  string
+		DatabaseName    string
+		DatabasePath    string
 		DatabaseUser    string
 		DatabaseSecret  string
 	}
 
 	NodeConfig struct {
-		Logger   logger.Logger
-		Services []AbstractServiceConfig
+		Logger       logger.Logger
+		Services     []AbstractServiceConfig
+		MaxRestarts  int
+		MinBackoff   time.Duration
+		MaxBackoff   time.Duration
+		RestartReset time.Duration
+	}
+
+	AuthConfig struct {
+		CurrentKeyID    string
+		Keys            []auth.KeyConfig
+		Issuer          string
+		Audience        string
+		AccessTokenTTL  time.Duration
+		RefreshTokenTTL time.Duration
+		Leeway          time.Duration
+	}
+
+	TrashConfig struct {
+		Retention     time.Duration
+		PurgeInterval time.Duration
+	}
+
+	CacheConfig struct {
+		enabled      bool
+		Backend      string
+		RESP         cache.RESPOptions
+		MaxEntries   int
+		MaxBytes     int64
+		UserTTL      time.Duration
+		DeckTTL      time.Duration
+		FlashcardTTL time.Duration
 	}
 
 	ServiceConfig struct {
@@ -78,6 +187,9 @@ func InitialConfiguration() AbstractConfig {
 		DatabaseCfg: new(DatabaseConfig),
 		NodeCfg:     new(NodeConfig),
 		LoggerCfg:   new(LoggerConfig),
+		AuthCfg:     new(AuthConfig),
+		TrashCfg:    new(TrashConfig),
+		CacheCfg:    new(CacheConfig),
 	}
 	CONFIG_DIR := os.Getenv("LANGUAGO_CONFIG_DIR")
 	var CONFIG_FILE string = "general.yaml"
@@ -101,9 +213,12 @@ func InitialConfiguration() AbstractConfig {
 	dbRaw := viper.GetStringMapString("database")
 	config.DatabaseCfg.DatabaseAddress = dbRaw["db_address"]
 	config.DatabaseCfg.DatabaseDriver = dbRaw["db_driver"]
+	config.DatabaseCfg.DatabaseName = dbRaw["db_name"]
+	config.DatabaseCfg.DatabasePath = dbRaw["db_path"]
 	config.DatabaseCfg.DatabaseUser = dbRaw["db_user"]
 	config.DatabaseCfg.DatabaseSecret = dbRaw["db_secret"]
 	config.DatabaseCfg.isMock = viper.GetBool("database.is_mock")
+	config.DatabaseCfg.migrateOnStart = viper.GetBool("database.migrate_on_start")
 
 	nodeRaw := viper.GetStringMap("node.services")
 	config.NodeCfg.Services = make([]AbstractServiceConfig, 0)
@@ -140,6 +255,21 @@ func InitialConfiguration() AbstractConfig {
 		}
 	}
 
+	viper.SetDefault("node.restart.max_restarts", defaultMaxRestarts)
+	viper.SetDefault("node.restart.min_backoff", defaultMinBackoff)
+	viper.SetDefault("node.restart.max_backoff", defaultMaxBackoff)
+	viper.SetDefault("node.restart.reset_after", defaultRestartReset)
+
+	config.NodeCfg.MaxRestarts = viper.GetInt("node.restart.max_restarts")
+	config.NodeCfg.MinBackoff = viper.GetDuration("node.restart.min_backoff")
+	config.NodeCfg.MaxBackoff = viper.GetDuration("node.restart.max_backoff")
+	config.NodeCfg.RestartReset = viper.GetDuration("node.restart.reset_after")
+
+	if config.NodeCfg.MaxRestarts < 0 || config.NodeCfg.MinBackoff <= 0 ||
+		config.NodeCfg.MaxBackoff < config.NodeCfg.MinBackoff || config.NodeCfg.RestartReset <= 0 {
+		panic("error invalid service restart policy")
+	}
+
 	logRaw := viper.GetStringMapString("logger")
 	var envValue wrappers.EnvParam = wrappers.MustToEnvParam(
 		viper.GetString("logger.env"),
@@ -161,6 +291,93 @@ func InitialConfiguration() AbstractConfig {
 		config.LoggerCfg.Logger = wrappers.NewZerologWrapper(viper.GetBool("logger.debug"), envValue)
 	}
 
+	config.AuthCfg.CurrentKeyID = viper.GetString("auth.current_key")
+	if err = viper.UnmarshalKey("auth.keys", &config.AuthCfg.Keys); err != nil {
+		panic("error reading auth keys: " + err.Error())
+	}
+
+	// configurations without the auth block keep signing with LANGUAGO_SECRET
+	if len(config.AuthCfg.Keys) == 0 {
+		config.AuthCfg.CurrentKeyID = defaultKeyID
+		config.AuthCfg.Keys = []auth.KeyConfig{{
+			ID:        defaultKeyID,
+			Algorithm: auth.AlgHS256,
+			SecretEnv: "LANGUAGO_SECRET",
+		}}
+	}
+
+	viper.SetDefault("auth.issuer", defaultIssuer)
+	viper.SetDefault("auth.audience", defaultAudience)
+	viper.SetDefault("auth.access_token_ttl", defaultAccessTokenTTL)
+	viper.SetDefault("auth.refresh_token_ttl", defaultRefreshTokenTTL)
+
+	config.AuthCfg.Issuer = viper.GetString("auth.issuer")
+	config.AuthCfg.Audience = viper.GetString("auth.audience")
+	config.AuthCfg.AccessTokenTTL = viper.GetDuration("auth.access_token_ttl")
+	config.AuthCfg.RefreshTokenTTL = viper.GetDuration("auth.refresh_token_ttl")
+	config.AuthCfg.Leeway = viper.GetDuration("auth.leeway")
+
+	if config.AuthCfg.AccessTokenTTL <= 0 || config.AuthCfg.RefreshTokenTTL <= 0 || config.AuthCfg.Leeway < 0 {
+		panic("error invalid auth token lifetimes")
+	}
+
+	// an empty one would match the tokens without the claim
+	if config.AuthCfg.Issuer == "" || config.AuthCfg.Audience == "" {
+		panic("error auth issuer and audience are required")
+	}
+
+	viper.SetDefault("trash.retention", defaultTrashRetention)
+	viper.SetDefault("trash.purge_interval", defaultPurgeInterval)
+
+	config.TrashCfg.Retention = viper.GetDuration("trash.retention")
+	config.TrashCfg.PurgeInterval = viper.GetDuration("trash.purge_interval")
+
+	if config.TrashCfg.Retention <= 0 || config.TrashCfg.PurgeInterval <= 0 {
+		panic("error invalid trash retention")
+	}
+
+	viper.SetDefault("cache.max_entries", defaultCacheEntries)
+	viper.SetDefault("cache.max_bytes", defaultCacheBytes)
+	viper.SetDefault("cache.ttl.user", defaultUserCacheTTL)
+	viper.SetDefault("cache.ttl.deck", defaultDeckCacheTTL)
+	viper.SetDefault("cache.ttl.flashcard", defaultCardCacheTTL)
+
+	viper.SetDefault("cache.backend", CacheBackendMemory)
+	viper.SetDefault("cache.resp.address", defaultRESPAddress)
+	viper.SetDefault("cache.resp.pool_size", defaultRESPPoolSize)
+	viper.SetDefault("cache.resp.timeout", defaultRESPTimeout)
+	// nodes of different environments can share a server
+	viper.SetDefault("cache.resp.namespace", "languago:"+viper.GetString("logger.env"))
+
+	config.CacheCfg.enabled = viper.GetBool("cache.enabled")
+	config.CacheCfg.Backend = viper.GetString("cache.backend")
+	config.CacheCfg.RESP = cache.RESPOptions{
+		Address:   viper.GetString("cache.resp.address"),
+		Password:  os.Getenv(viper.GetString("cache.resp.password_env")),
+		DB:        viper.GetInt("cache.resp.db"),
+		Namespace: viper.GetString("cache.resp.namespace"),
+		PoolSize:  viper.GetInt("cache.resp.pool_size"),
+		Timeout:   viper.GetDuration("cache.resp.timeout"),
+	}
+	config.CacheCfg.MaxEntries = viper.GetInt("cache.max_entries")
+	config.CacheCfg.MaxBytes = viper.GetInt64("cache.max_bytes")
+	config.CacheCfg.UserTTL = viper.GetDuration("cache.ttl.user")
+	config.CacheCfg.DeckTTL = viper.GetDuration("cache.ttl.deck")
+	config.CacheCfg.FlashcardTTL = viper.GetDuration("cache.ttl.flashcard")
+
+	if config.CacheCfg.MaxEntries < 0 || config.CacheCfg.MaxBytes < 0 ||
+		config.CacheCfg.UserTTL < 0 || config.CacheCfg.DeckTTL < 0 || config.CacheCfg.FlashcardTTL < 0 {
+		panic("error invalid cache limits")
+	}
+
+	if config.CacheCfg.Backend != CacheBackendMemory && config.CacheCfg.Backend != CacheBackendRESP {
+		panic("error invalid cache backend " + config.CacheCfg.Backend)
+	}
+
+	if config.CacheCfg.RESP.PoolSize <= 0 || config.CacheCfg.RESP.Timeout <= 0 || config.CacheCfg.RESP.DB < 0 {
+		panic("error invalid cache server pool")
+	}
+
 	return &config
 }
 
@@ -176,10 +393,24 @@ func (c *Config) GetLoggerConfig() AbstractLoggerConfig {
 	return c.LoggerCfg
 }
 
+func (c *Config) GetAuthConfig() AbstractAuthConfig {
+	return c.AuthCfg
+}
+
+func (c *Config) GetTrashConfig() AbstractTrashConfig {
+	return c.TrashCfg
+}
+
+func (c *Config) GetCacheConfig() AbstractCacheConfig {
+	return c.CacheCfg
+}
+
 func (c *DatabaseConfig) GetCredentials() repository.DBCredentials {
 	return &repository.DBCred{
 		DbAddress: c.DatabaseAddress,
 		Driver:    c.DatabaseDriver,
+		DBName:    c.DatabaseName,
+		Path:      c.DatabasePath,
 		User:      c.DatabaseUser,
 		Secret:    c.DatabaseSecret,
 	}
@@ -189,6 +420,10 @@ func (c *DatabaseConfig) IsMock() bool {
 	return c.isMock
 }
 
+func (c *DatabaseConfig) MigrateOnStart() bool {
+	return c.migrateOnStart
+}
+
 func (c *NodeConfig) GetServicesCfg() []AbstractServiceConfig {
 	return c.Services
 }
@@ -197,6 +432,22 @@ func (c *NodeConfig) SetLogger(l logger.Logger) {
 	c.Logger = l
 }
 
+func (c *NodeConfig) GetMaxRestarts() int {
+	return c.MaxRestarts
+}
+
+func (c *NodeConfig) GetMinBackoff() time.Duration {
+	return c.MinBackoff
+}
+
+func (c *NodeConfig) GetMaxBackoff() time.Duration {
+	return c.MaxBackoff
+}
+
+func (c *NodeConfig) GetRestartReset() time.Duration {
+	return c.RestartReset
+}
+
 func (c *LoggerConfig) GetLogger() logger.Logger {
 	return c.Logger
 }
@@ -209,6 +460,74 @@ func (c *LoggerConfig) GetEnv() logger.EnvParam {
 	return c.Env
 }
 
+func (c *AuthConfig) GetCurrentKeyID() string {
+	return c.CurrentKeyID
+}
+
+func (c *AuthConfig) GetSigningKeys() []auth.KeyConfig {
+	return c.Keys
+}
+
+func (c *AuthConfig) GetIssuer() string {
+	return c.Issuer
+}
+
+func (c *AuthConfig) GetAudience() string {
+	return c.Audience
+}
+
+func (c *AuthConfig) GetAccessTokenTTL() time.Duration {
+	return c.AccessTokenTTL
+}
+
+func (c *AuthConfig) GetRefreshTokenTTL() time.Duration {
+	return c.RefreshTokenTTL
+}
+
+func (c *AuthConfig) GetLeeway() time.Duration {
+	return c.Leeway
+}
+
+func (c *TrashConfig) GetRetention() time.Duration {
+	return c.Retention
+}
+
+func (c *TrashConfig) GetPurgeInterval() time.Duration {
+	return c.PurgeInterval
+}
+
+func (c *CacheConfig) Enabled() bool {
+	return c.enabled
+}
+
+func (c *CacheConfig) GetBackend() string {
+	return c.Backend
+}
+
+func (c *CacheConfig) GetRESPOptions() cache.RESPOptions {
+	return c.RESP
+}
+
+func (c *CacheConfig) GetMaxEntries() int {
+	return c.MaxEntries
+}
+
+func (c *CacheConfig) GetMaxBytes() int64 {
+	return c.MaxBytes
+}
+
+func (c *CacheConfig) GetUserTTL() time.Duration {
+	return c.UserTTL
+}
+
+func (c *CacheConfig) GetDeckTTL() time.Duration {
+	return c.DeckTTL
+}
+
+func (c *CacheConfig) GetFlashcardTTL() time.Duration {
+	return c.FlashcardTTL
+}
+
 func (c *ServiceConfig) ServiceName() string {
 	return c.Name
 }
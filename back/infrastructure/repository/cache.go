@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"languago/pkg/cache"
+	"languago/pkg/changes"
+	"languago/pkg/models/entities"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type (
+	// CacheTTL is how long the entities of each kind stay cached, 0 doesn't
+	// cache them.
+	CacheTTL struct {
+		User      time.Duration
+		Deck      time.Duration
+		Flashcard time.Duration
+	}
+
+	// cachedStorage reads users, decks and flashcards by ID through the cache
+	// and invalidates them when they are written through it. The writes are
+	// published on the change bus, so the caches of the other nodes drop
+	// them too. Writes made around it are seen once the entry expires.
+	// Cached users have no password hash, it is only needed by the login
+	// lookup which isn't cached.
+	cachedStorage struct {
+		Storage
+		cache cache.Cache
+		bus   changes.Bus
+		ttl   CacheTTL
+		// pending collects the changes within a transaction, they are
+		// invalidated when it ends. Reads within a transaction skip the
+		// cache, so it never holds data that isn't committed.
+		pending *[]changes.Change
+	}
+)
+
+// NewCachedInteractor returns an interactor with the storage of d wrapped in
+// a read-through cache, the connection, the migrator and the change bus are
+// shared with d. The cache drops what is published on the bus until it is
+// closed.
+func NewCachedInteractor(d DatabaseInteractor, c cache.Cache, ttl CacheTTL) DatabaseInteractor {
+	bus := d.Changes()
+	bus.Subscribe(func(change changes.Change) {
+		if change == changes.All {
+			c.Flush(context.Background())
+			return
+		}
+
+		c.Delete(context.Background(), changeKey(change))
+	})
+
+	return &databaseInteractor{
+		DB: &cachedStorage{
+			Storage: d.Database(),
+			cache:   c,
+			bus:     bus,
+			ttl:     ttl,
+		},
+		DBCred:   d.DDCredentials(),
+		migrator: d.Migrator(),
+		bus:      bus,
+	}
+}
+
+func userKey(userID uuid.UUID) string {
+	return "user:" + userID.String()
+}
+
+func deckKey(owner, deckID uuid.UUID) string {
+	return "deck:" + owner.String() + ":" + deckID.String()
+}
+
+func flashcardKey(owner, cardID uuid.UUID) string {
+	return "flashcard:" + owner.String() + ":" + cardID.String()
+}
+
+// changeKey is the key of the entity which was changed.
+func changeKey(change changes.Change) string {
+	switch change.Entity {
+	case entities.EntityUser:
+		return userKey(change.ID)
+	case entities.EntityDeck:
+		return deckKey(change.Owner, change.ID)
+	default:
+		return flashcardKey(change.Owner, change.ID)
+	}
+}
+
+func (s *cachedStorage) withTx(ctx context.Context, fn func(Storage) error) error {
+	t, ok := s.Storage.(transactor)
+	if !ok {
+		return fmt.Errorf("error storage %T doesn't support transactions", s.Storage)
+	}
+
+	var pending []changes.Change
+	defer func() {
+		s.invalidate(context.WithoutCancel(ctx), pending...)
+	}()
+
+	return t.withTx(ctx, func(tx Storage) error {
+		return fn(&cachedStorage{
+			Storage: tx,
+			cache:   s.cache,
+			bus:     s.bus,
+			ttl:     s.ttl,
+			pending: &pending,
+		})
+	})
+}
+
+// load returns the cached value of the key, on a miss it is fetched and
+// cached for ttl. Errors are never cached.
+func load[T any](ctx context.Context, s *cachedStorage, key string, ttl time.Duration, fetch func() (T, error)) (T, error) {
+	if ttl <= 0 || s.pending != nil {
+		return fetch()
+	}
+
+	var v T
+	if raw, ok := s.cache.Get(ctx, key); ok {
+		// an entry which doesn't decode is read again
+		if err := json.Unmarshal(raw, &v); err == nil {
+			return v, nil
+		}
+	}
+
+	v, err := fetch()
+	if err != nil {
+		return v, err
+	}
+
+	if raw, err := json.Marshal(v); err == nil {
+		s.cache.Set(ctx, key, raw, ttl)
+	}
+
+	return v, nil
+}
+
+// invalidate drops the entities written by a successful or failed write and
+// publishes the changes, within a transaction once it ends.
+func (s *cachedStorage) invalidate(ctx context.Context, written ...changes.Change) {
+	if s.pending != nil {
+		*s.pending = append(*s.pending, written...)
+		return
+	}
+
+	for _, change := range written {
+		s.cache.Delete(ctx, changeKey(change))
+		s.bus.Publish(ctx, change)
+	}
+}
+
+// SelectUser caches the users looked up by ID.
+func (s *cachedStorage) SelectUser(ctx context.Context, arg SelectUserParams) (*entities.User, error) {
+	if arg.ID == uuid.Nil {
+		return s.Storage.SelectUser(ctx, arg)
+	}
+
+	return load(ctx, s, userKey(arg.ID), s.ttl.User, func() (*entities.User, error) {
+		return s.Storage.SelectUser(ctx, SelectUserParams{ID: arg.ID})
+	})
+}
+
+func (s *cachedStorage) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
+	defer s.invalidate(ctx, changes.Change{Entity: entities.EntityUser, ID: arg.ID})
+	return s.Storage.UpdateUser(ctx, arg)
+}
+
+// DeleteUser drops the user, the cards and decks of the user can't be read
+// anymore and expire.
+func (s *cachedStorage) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	defer s.invalidate(ctx, changes.Change{Entity: entities.EntityUser, ID: userID})
+	return s.Storage.DeleteUser(ctx, userID)
+}
+
+// SelectDeck caches the decks looked up by ID, the name is ignored then.
+func (s *cachedStorage) SelectDeck(ctx context.Context, arg SelectDeckParams) (*entities.Deck, error) {
+	owner, err := callerID(ctx)
+	if err != nil || arg.ID == uuid.Nil {
+		return s.Storage.SelectDeck(ctx, arg)
+	}
+
+	return load(ctx, s, deckKey(owner, arg.ID), s.ttl.Deck, func() (*entities.Deck, error) {
+		return s.Storage.SelectDeck(ctx, SelectDeckParams{ID: arg.ID})
+	})
+}
+
+func (s *cachedStorage) UpdateDeck(ctx context.Context, arg UpdateDeckParams) error {
+	if owner, err := callerID(ctx); err == nil {
+		defer s.invalidate(ctx, changes.Change{Entity: entities.EntityDeck, ID: arg.ID, Owner: owner})
+	}
+
+	return s.Storage.UpdateDeck(ctx, arg)
+}
+
+func (s *cachedStorage) DeleteDeck(ctx context.Context, arg DeleteDeckParams) error {
+	if owner, err := callerID(ctx); err == nil {
+		defer s.invalidate(ctx, changes.Change{Entity: entities.EntityDeck, ID: arg.ID, Owner: owner})
+	}
+
+	return s.Storage.DeleteDeck(ctx, arg)
+}
+
+func (s *cachedStorage) RestoreDeck(ctx context.Context, arg RestoreDeckParams) error {
+	if owner, err := callerID(ctx); err == nil {
+		defer s.invalidate(ctx, changes.Change{Entity: entities.EntityDeck, ID: arg.ID, Owner: owner})
+	}
+
+	return s.Storage.RestoreDeck(ctx, arg)
+}
+
+// SelectFlashcard caches the cards looked up by ID, the other params are
+// ignored then.
+func (s *cachedStorage) SelectFlashcard(ctx context.Context, arg SelectFlashcardParams) ([]*entities.Flashcard, error) {
+	owner, err := callerID(ctx)
+	if err != nil || arg.ID == uuid.Nil {
+		return s.Storage.SelectFlashcard(ctx, arg)
+	}
+
+	return load(ctx, s, flashcardKey(owner, arg.ID), s.ttl.Flashcard, func() ([]*entities.Flashcard, error) {
+		return s.Storage.SelectFlashcard(ctx, SelectFlashcardParams{ID: arg.ID})
+	})
+}
+
+func (s *cachedStorage) UpdateFlashcard(ctx context.Context, arg UpdateFlashcardParams) error {
+	if owner, err := callerID(ctx); err == nil {
+		defer s.invalidate(ctx, changes.Change{Entity: entities.EntityFlashcard, ID: arg.ID, Owner: owner})
+	}
+
+	return s.Storage.UpdateFlashcard(ctx, arg)
+}
+
+func (s *cachedStorage) DeleteFlashcard(ctx context.Context, cardID uuid.UUID) error {
+	if owner, err := callerID(ctx); err == nil {
+		defer s.invalidate(ctx, changes.Change{Entity: entities.EntityFlashcard, ID: cardID, Owner: owner})
+	}
+
+	return s.Storage.DeleteFlashcard(ctx, cardID)
+}
+
+func (s *cachedStorage) RestoreFlashcard(ctx context.Context, cardID uuid.UUID) error {
+	if owner, err := callerID(ctx); err == nil {
+		defer s.invalidate(ctx, changes.Change{Entity: entities.EntityFlashcard, ID: cardID, Owner: owner})
+	}
+
+	return s.Storage.RestoreFlashcard(ctx, cardID)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"languago/pkg/ctxtools"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// callerID returns the id of the authenticated user the storage call is made on behalf of.
+// User-owned records are always scoped to the caller, so a missing user is an authorization error.
+func callerID(ctx context.Context) (uuid.UUID, error) {
+	user := ctxtools.User(ctx)
+	if user == nil || user.Id == uuid.Nil {
+		return uuid.Nil, fmt.Errorf("error fetch caller from context: %w", errors2.ErrUnauthorized)
+	}
+
+	return user.Id, nil
+}
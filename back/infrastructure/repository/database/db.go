@@ -11,16 +11,32 @@ import (
 	"github.com/google/uuid"
 )
 
+// DBTX is the sqlc DBTX which squirrel can also run with, *sql.DB and *sql.Tx
+// implement both.
 type DBTX interface {
-	sq.Runner
-	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	sq.StdSqlCtx
 	PrepareContext(context.Context, string) (*sql.Stmt, error)
-	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
-	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
 }
 
-type databaseController struct {
-	conn *sql.DB
+// New returns the queries built with squirrel. format is the placeholder
+// format of the database, sq.Dollar for PostgreSQL and sq.Question otherwise.
+func New(db DBTX, format sq.PlaceholderFormat) *Queries {
+	return &Queries{
+		db:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(format),
+	}
+}
+
+type Queries struct {
+	db      DBTX
+	builder sq.StatementBuilderType
+}
+
+func (c *Queries) WithTx(tx *sql.Tx) *Queries {
+	return &Queries{
+		db:      tx,
+		builder: c.builder,
+	}
 }
 
 type AddToDeckParams struct {
@@ -28,18 +44,18 @@ type AddToDeckParams struct {
 	FlashcardID uuid.UUID
 }
 
-func (c *databaseController) AddToDeck(ctx context.Context, arg AddToDeckParams) error {
+func (c *Queries) AddToDeck(ctx context.Context, arg AddToDeckParams) error {
 	if arg.DeckID == uuid.Nil || arg.FlashcardID == uuid.Nil {
 		return fmt.Errorf("error missing required params")
 	}
 
-	stmt := sq.Insert("flashcard_decks").Columns(
+	stmt := c.builder.Insert("flashcard_decks").Columns(
 		"deck_id", "flashcard_id",
 	).Values(
 		arg.DeckID, arg.FlashcardID,
 	)
 
-	_, err := stmt.RunWith(c.conn).ExecContext(ctx)
+	_, err := stmt.RunWith(c.db).ExecContext(ctx)
 	if err != nil {
 		return fmt.Errorf("error add flashcard to deck: %w", err)
 	}
@@ -54,12 +70,12 @@ type CreateDeckParams struct {
 }
 
 // Decks
-func (c *databaseController) CreateDeck(ctx context.Context, arg CreateDeckParams) error {
+func (c *Queries) CreateDeck(ctx context.Context, arg CreateDeckParams) error {
 	if arg.ID == uuid.Nil || arg.Owner == uuid.Nil {
 		return fmt.Errorf("error missing required params")
 	}
 
-	_, err := sq.Insert("decks").Columns(
+	_, err := c.builder.Insert("decks").Columns(
 		"id",
 		"name",
 		"owner",
@@ -67,7 +83,7 @@ func (c *databaseController) CreateDeck(ctx context.Context, arg CreateDeckParam
 		arg.ID,
 		arg.Name,
 		arg.Owner,
-	).RunWith(c.conn).ExecContext(ctx)
+	).RunWith(c.db).ExecContext(ctx)
 	if err != nil {
 		return fmt.Errorf("error create deck: %w", err)
 	}
@@ -91,12 +107,12 @@ type CreateFlashcardParams struct {
 }
 
 // Flashcards
-func (c *databaseController) CreateFlashcard(ctx context.Context, arg CreateFlashcardParams) error {
+func (c *Queries) CreateFlashcard(ctx context.Context, arg CreateFlashcardParams) error {
 	if arg.ID == uuid.Nil {
 		return fmt.Errorf("error missing required params")
 	}
 
-	_, err := sq.Insert("flashcards").Columns(
+	_, err := c.builder.Insert("flashcards").Columns(
 		"id",
 		"word",
 		"meaning",
@@ -106,7 +122,7 @@ func (c *databaseController) CreateFlashcard(ctx context.Context, arg CreateFlas
 		arg.Word,
 		arg.Meaning,
 		arg.Usage,
-	).RunWith(c.conn).ExecContext(ctx)
+	).RunWith(c.db).ExecContext(ctx)
 	if err != nil {
 		return fmt.Errorf("error create flashcard: %w", err)
 	}
@@ -121,12 +137,12 @@ type CreateUserParams struct {
 }
 
 // User
-func (c *databaseController) CreateUser(ctx context.Context, arg CreateUserParams) error {
+func (c *Queries) CreateUser(ctx context.Context, arg CreateUserParams) error {
 	if arg.ID == uuid.Nil || arg.Login == "" || arg.Password == "" {
 		return fmt.Errorf("error missing required params")
 	}
 
-	_, err := sq.Insert("users").Columns(
+	_, err := c.builder.Insert("users").Columns(
 		"id",
 		"login",
 		"password",
@@ -134,7 +150,7 @@ func (c *databaseController) CreateUser(ctx context.Context, arg CreateUserParam
 		arg.ID,
 		arg.Login,
 		arg.Password,
-	).RunWith(c.conn).ExecContext(ctx)
+	).RunWith(c.db).ExecContext(ctx)
 	if err != nil {
 		return fmt.Errorf("error create user: %w", err)
 	}
@@ -142,22 +158,22 @@ func (c *databaseController) CreateUser(ctx context.Context, arg CreateUserParam
 	return nil
 }
 
-func (c *databaseController) DeleteDeck(ctx context.Context, id uuid.UUID) error {
+func (c *Queries) DeleteDeck(ctx context.Context, id uuid.UUID) error {
 	if id == uuid.Nil {
 		return fmt.Errorf("error missing required param")
 	}
 
-	_, err := sq.Delete("decks").Where(sq.Eq{"id": id}).RunWith(c.conn).ExecContext(ctx)
+	_, err := c.builder.Delete("decks").Where(sq.Eq{"id": id}).RunWith(c.db).ExecContext(ctx)
 
 	return err
 }
 
-func (c *databaseController) DeleteFlashcard(ctx context.Context, id uuid.UUID) error {
+func (c *Queries) DeleteFlashcard(ctx context.Context, id uuid.UUID) error {
 	if id == uuid.Nil {
 		return fmt.Errorf("error missing required param")
 	}
 
-	_, err := sq.Delete("flashcards").Where(sq.Eq{"id": id}).RunWith(c.conn).ExecContext(ctx)
+	_, err := c.builder.Delete("flashcards").Where(sq.Eq{"id": id}).RunWith(c.db).ExecContext(ctx)
 
 	return err
 }
@@ -167,15 +183,15 @@ type DeleteFromDeckParams struct {
 	DeckID      uuid.UUID
 }
 
-func (c *databaseController) DeleteFromDeck(ctx context.Context, arg DeleteFromDeckParams) error {
+func (c *Queries) DeleteFromDeck(ctx context.Context, arg DeleteFromDeckParams) error {
 	if arg.DeckID == uuid.Nil || arg.FlashcardID == uuid.Nil {
 		return fmt.Errorf("error missing required param")
 	}
 
-	_, err := sq.Delete("flashcard_decks").Where(
+	_, err := c.builder.Delete("flashcard_decks").Where(
 		sq.Eq{"deck_id": arg.DeckID},
 		sq.Eq{"flashcard_id": arg.FlashcardID},
-	).RunWith(c.conn).ExecContext(ctx)
+	).RunWith(c.db).ExecContext(ctx)
 
 	return err
 }
@@ -185,7 +201,7 @@ func (c *databaseController) DeleteFromDeck(ctx context.Context, arg DeleteFromD
 //     WHERE id = $1
 // `
 
-// func (c *databaseController) DeleteUser(ctx context.Context, id uuid.UUID) error {
+// func (c *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
 // 	_, err := c.conn.ExecContext(ctx, deleteUser, id)
 // 	return err
 // }
@@ -201,7 +217,7 @@ func (c *databaseController) DeleteFromDeck(ctx context.Context, arg DeleteFromD
 // 	ID   uuid.UUID      `db:"id" json:"id"`
 // }
 
-// func (c *databaseController) EditDeckProps(ctx context.Context, arg EditDeckPropsParams) error {
+// func (c *Queries) EditDeckProps(ctx context.Context, arg EditDeckPropsParams) error {
 // 	_, err := c.conn.ExecContext(ctx, editDeckProps, arg.Name, arg.ID)
 // 	return err
 // }
@@ -211,7 +227,7 @@ func (c *databaseController) DeleteFromDeck(ctx context.Context, arg DeleteFromD
 //     WHERE id = $1
 // `
 
-// func (c *databaseController) SelectDeck(ctx context.Context, id uuid.UUID) (Deck, error) {
+// func (c *Queries) SelectDeck(ctx context.Context, id uuid.UUID) (Deck, error) {
 // 	row := c.conn.QueryRowContext(ctx, selectDeck, id)
 // 	var i Deck
 // 	err := row.Scan(&i.ID, &i.Name, &i.Owner)
@@ -223,7 +239,7 @@ func (c *databaseController) DeleteFromDeck(ctx context.Context, arg DeleteFromD
 //     WHERE name = $1
 // `
 
-// func (c *databaseController) SelectDecksByName(ctx context.Context, name sql.NullString) ([]Deck, error) {
+// func (c *Queries) SelectDecksByName(ctx context.Context, name sql.NullString) ([]Deck, error) {
 // 	rows, err := c.conn.QueryContext(ctx, selectDecksByName, name)
 // 	if err != nil {
 // 		return nil, err
@@ -251,7 +267,7 @@ func (c *databaseController) DeleteFromDeck(ctx context.Context, arg DeleteFromD
 //     WHERE id = $1
 // `
 
-// func (c *databaseController) SelectFlashcardByID(ctx context.Context, id uuid.UUID) (Flashcard, error) {
+// func (c *Queries) SelectFlashcardByID(ctx context.Context, id uuid.UUID) (Flashcard, error) {
 // 	row := c.conn.QueryRowContext(ctx, selectFlashcardByID, id)
 // 	var i Flashcard
 // 	err := row.Scan(
@@ -284,7 +300,7 @@ func (c *databaseController) DeleteFromDeck(ctx context.Context, arg DeleteFromD
 // 	FlashcardID uuid.NullUUID  `db:"flashcard_id" json:"flashcard_id"`
 // }
 
-// func (c *databaseController) SelectFlashcardByMeaning(ctx context.Context, arg SelectFlashcardByMeaningParams) ([]SelectFlashcardByMeaningRow, error) {
+// func (c *Queries) SelectFlashcardByMeaning(ctx context.Context, arg SelectFlashcardByMeaningParams) ([]SelectFlashcardByMeaningRow, error) {
 // 	rows, err := c.conn.QueryContext(ctx, selectFlashcardByMeaning, arg.DeckID, arg.Meaning)
 // 	if err != nil {
 // 		return nil, err
@@ -335,7 +351,7 @@ func (c *databaseController) DeleteFromDeck(ctx context.Context, arg DeleteFromD
 // 	FlashcardID uuid.NullUUID  `db:"flashcard_id" json:"flashcard_id"`
 // }
 
-// func (c *databaseController) SelectFlashcardByWord(ctx context.Context, arg SelectFlashcardByWordParams) ([]SelectFlashcardByWordRow, error) {
+// func (c *Queries) SelectFlashcardByWord(ctx context.Context, arg SelectFlashcardByWordParams) ([]SelectFlashcardByWordRow, error) {
 // 	rows, err := c.conn.QueryContext(ctx, selectFlashcardByWord, arg.DeckID, arg.Word)
 // 	if err != nil {
 // 		return nil, err
@@ -370,7 +386,7 @@ func (c *databaseController) DeleteFromDeck(ctx context.Context, arg DeleteFromD
 //     WHERE owner = $1
 // `
 
-// func (c *databaseController) SelectOwnerDecks(ctx context.Context, owner uuid.NullUUID) ([]Deck, error) {
+// func (c *Queries) SelectOwnerDecks(ctx context.Context, owner uuid.NullUUID) ([]Deck, error) {
 // 	rows, err := c.conn.QueryContext(ctx, selectOwnerDecks, owner)
 // 	if err != nil {
 // 		return nil, err
@@ -403,7 +419,7 @@ func (c *databaseController) DeleteFromDeck(ctx context.Context, arg DeleteFromD
 // 	Login sql.NullString `db:"login" json:"login"`
 // }
 
-// func (c *databaseController) SelectUser(ctx context.Context, arg SelectUserParams) (User, error) {
+// func (c *Queries) SelectUser(ctx context.Context, arg SelectUserParams) (User, error) {
 // 	row := c.conn.QueryRowContext(ctx, selectUser, arg.ID, arg.Login)
 // 	var i User
 // 	err := row.Scan(&i.ID, &i.Login, &i.Password)
@@ -415,7 +431,7 @@ func (c *databaseController) DeleteFromDeck(ctx context.Context, arg DeleteFromD
 //     WHERE id = $1
 // `
 
-// func (c *databaseController) SelectUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+// func (c *Queries) SelectUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 // 	row := c.conn.QueryRowContext(ctx, selectUserByID, id)
 // 	var i User
 // 	err := row.Scan(&i.ID, &i.Login, &i.Password)
@@ -427,7 +443,7 @@ func (c *databaseController) DeleteFromDeck(ctx context.Context, arg DeleteFromD
 //     WHERE login = $1
 // `
 
-// func (c *databaseController) SelectUserByLogin(ctx context.Context, login sql.NullString) (User, error) {
+// func (c *Queries) SelectUserByLogin(ctx context.Context, login sql.NullString) (User, error) {
 // 	row := c.conn.QueryRowContext(ctx, selectUserByLogin, login)
 // 	var i User
 // 	err := row.Scan(&i.ID, &i.Login, &i.Password)
@@ -449,7 +465,7 @@ func (c *databaseController) DeleteFromDeck(ctx context.Context, arg DeleteFromD
 // 	ID      uuid.UUID      `db:"id" json:"id"`
 // }
 
-// func (c *databaseController) UpdateFlashcard(ctx context.Context, arg UpdateFlashcardParams) error {
+// func (c *Queries) UpdateFlashcard(ctx context.Context, arg UpdateFlashcardParams) error {
 // 	_, err := c.conn.ExecContext(ctx, updateFlashcard,
 // 		arg.Word,
 // 		arg.Meaning,
@@ -475,7 +491,7 @@ func (c *databaseController) DeleteFromDeck(ctx context.Context, arg DeleteFromD
 // 	Login sql.NullString `db:"login" json:"login"`
 // }
 
-// func (c *databaseController) UpdateUserLogin(ctx context.Context, arg UpdateUserLoginParams) (UpdateUserLoginRow, error) {
+// func (c *Queries) UpdateUserLogin(ctx context.Context, arg UpdateUserLoginParams) (UpdateUserLoginRow, error) {
 // 	row := c.conn.QueryRowContext(ctx, updateUserLogin, arg.Login, arg.ID)
 // 	var i UpdateUserLoginRow
 // 	err := row.Scan(&i.ID, &i.Login)
@@ -492,7 +508,7 @@ func (c *databaseController) DeleteFromDeck(ctx context.Context, arg DeleteFromD
 // 	ID       uuid.UUID      `db:"id" json:"id"`
 // }
 
-// func (c *databaseController) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+// func (c *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
 // 	_, err := c.conn.ExecContext(ctx, updateUserPassword, arg.Password, arg.ID)
 // 	return err
 // }
@@ -0,0 +1,213 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+// likeEscape escapes the LIKE wildcards, it is the same character in every
+// database, unlike the backslash which MySQL treats as an escape in literals.
+const likeEscape = "!"
+
+// Flashcard is a row of the flashcards table. Usage is left as it is stored,
+// an array literal in PostgreSQL and JSON otherwise.
+type Flashcard struct {
+	ID        uuid.UUID
+	Word      sql.NullString
+	Meaning   sql.NullString
+	Usage     []byte
+	Owner     uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int64
+}
+
+type ListFlashcardsParams struct {
+	Owner uuid.UUID
+	// DeckID, Tag, Prefix and DueAt filter the cards if set. Prefix matches
+	// the beginning of the word regardless of case, DueAt keeps the cards
+	// with a review of the owner due by then.
+	DeckID uuid.UUID
+	Tag    string
+	Prefix string
+	DueAt  time.Time
+	// OrderBy is the column the cards are sorted by, the id breaks the ties.
+	OrderBy string
+	Desc    bool
+	// AfterKey and AfterID are the sort key and the id of the last card of
+	// the previous page, the page starts after this card.
+	AfterKey any
+	AfterID  uuid.UUID
+	// Limit of 0 returns all cards.
+	Limit uint64
+}
+
+// ListFlashcards selects a page of cards in the keyset order, so any page is
+// read from the owner index without skipping over the previous pages. The
+// cards in the trash are not listed.
+func (c *Queries) ListFlashcards(ctx context.Context, arg ListFlashcardsParams) ([]Flashcard, error) {
+	if arg.Owner == uuid.Nil {
+		return nil, fmt.Errorf("error missing required params")
+	}
+
+	switch arg.OrderBy {
+	case "word", "created_at", "updated_at":
+	default:
+		return nil, fmt.Errorf("error unknown sort column %q", arg.OrderBy)
+	}
+
+	query := c.builder.Select(
+		"f.id", "f.word", "f.meaning", "f.usage", "f.owner", "f.created_at", "f.updated_at", "f.version",
+	).From("flashcards AS f").Where(sq.Eq{"f.owner": arg.Owner, "f.deleted_at": nil})
+
+	if arg.DeckID != uuid.Nil {
+		query = query.Where(exists(c.builder.Select("1").From("flashcard_decks AS d").Where(
+			"d.flashcard_id = f.id",
+		).Where(sq.Eq{"d.deck_id": arg.DeckID})))
+	}
+
+	if arg.Tag != "" {
+		query = query.Where(exists(c.builder.Select("1").From("flashcard_tags AS t").Where(
+			"t.flashcard_id = f.id",
+		).Where(sq.Eq{"t.tag": arg.Tag})))
+	}
+
+	if !arg.DueAt.IsZero() {
+		query = query.Where(exists(c.builder.Select("1").From("reviews AS r").Where(
+			"r.flashcard_id = f.id",
+		).Where(sq.Eq{"r.user_id": arg.Owner}).Where(sq.LtOrEq{"r.due_at": arg.DueAt})))
+	}
+
+	if arg.Prefix != "" {
+		query = query.Where("LOWER(f.word) LIKE ? ESCAPE '"+likeEscape+"'", escapeLike(strings.ToLower(arg.Prefix))+"%")
+	}
+
+	column, order, cmp := "f."+arg.OrderBy, "ASC", ">"
+	if arg.Desc {
+		order, cmp = "DESC", "<"
+	}
+
+	if arg.AfterKey != nil {
+		query = query.Where(sq.Or{
+			sq.Expr(column+" "+cmp+" ?", arg.AfterKey),
+			sq.And{
+				sq.Eq{column: arg.AfterKey},
+				sq.Expr("f.id "+cmp+" ?", arg.AfterID),
+			},
+		})
+	}
+
+	query = query.OrderBy(column+" "+order, "f.id "+order)
+	if arg.Limit > 0 {
+		query = query.Limit(arg.Limit)
+	}
+
+	rows, err := query.RunWith(c.db).QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []Flashcard
+	for rows.Next() {
+		var i Flashcard
+		if err := rows.Scan(
+			&i.ID,
+			&i.Word,
+			&i.Meaning,
+			&i.Usage,
+			&i.Owner,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SetFlashcardTags replaces the tags of the card.
+func (c *Queries) SetFlashcardTags(ctx context.Context, cardID uuid.UUID, tags []string) error {
+	if cardID == uuid.Nil {
+		return fmt.Errorf("error missing required param")
+	}
+
+	_, err := c.builder.Delete("flashcard_tags").Where(sq.Eq{"flashcard_id": cardID}).RunWith(c.db).ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	stmt := c.builder.Insert("flashcard_tags").Columns("flashcard_id", "tag")
+	for _, tag := range tags {
+		stmt = stmt.Values(cardID, tag)
+	}
+
+	_, err = stmt.RunWith(c.db).ExecContext(ctx)
+	return err
+}
+
+// SelectFlashcardTags returns the sorted tags of each of the cards.
+func (c *Queries) SelectFlashcardTags(ctx context.Context, cardIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	tags := make(map[uuid.UUID][]string)
+	if len(cardIDs) == 0 {
+		return tags, nil
+	}
+
+	rows, err := c.builder.Select("flashcard_id", "tag").From("flashcard_tags").Where(
+		sq.Eq{"flashcard_id": cardIDs},
+	).OrderBy("flashcard_id", "tag").RunWith(c.db).QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id  uuid.UUID
+			tag string
+		)
+		if err := rows.Scan(&id, &tag); err != nil {
+			return nil, err
+		}
+		tags[id] = append(tags[id], tag)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// exists wraps the subquery in EXISTS, squirrel numbers the placeholders of
+// the whole statement.
+func exists(query sq.SelectBuilder) sq.Sqlizer {
+	return sq.Expr("EXISTS (?)", query)
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(
+		likeEscape, likeEscape+likeEscape,
+		"%", likeEscape+"%",
+		"_", likeEscape+"_",
+	).Replace(s)
+}
@@ -0,0 +1,1185 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	errors2 "languago/pkg/errors"
+	"languago/pkg/models"
+	"languago/pkg/models/entities"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type (
+	// memoryStorage keeps everything in memory. It is used when the database is
+	// mocked and in tests, so it enforces the same uniqueness, ownership and
+	// cascading rules as the SQL storages and returns the same errors.
+	memoryStorage struct {
+		mu         sync.RWMutex
+		users      map[uuid.UUID]entities.User
+		flashcards map[uuid.UUID]entities.Flashcard
+		decks      map[uuid.UUID]entities.Deck
+		// deckCards is the set of flashcards of each deck
+		deckCards map[uuid.UUID]map[uuid.UUID]struct{}
+		reviews   map[reviewKey]entities.Review
+		sessions  map[uuid.UUID]entities.Session
+		// revisions is the history of every card and deck, the oldest first
+		revisions []entities.Revision
+	}
+
+	reviewKey struct {
+		userID      uuid.UUID
+		flashcardID uuid.UUID
+	}
+)
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{
+		users:      make(map[uuid.UUID]entities.User),
+		flashcards: make(map[uuid.UUID]entities.Flashcard),
+		decks:      make(map[uuid.UUID]entities.Deck),
+		deckCards:  make(map[uuid.UUID]map[uuid.UUID]struct{}),
+		reviews:    make(map[reviewKey]entities.Review),
+		sessions:   make(map[uuid.UUID]entities.Session),
+	}
+}
+
+func (s *memoryStorage) PingDB() error {
+	return nil
+}
+
+func (s *memoryStorage) Close() error {
+	return nil
+}
+
+func (s *memoryStorage) CreateUser(ctx context.Context, arg CreateUserParams) error {
+	if arg.Login == "" || arg.Password == "" {
+		return fmt.Errorf("error invalid user credentials: %w", ErrInvalidData)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.ID]; ok || s.loginTaken(arg.Login) {
+		return fmt.Errorf("error create user: %w", errors2.ErrAlreadyExists)
+	}
+
+	s.users[arg.ID] = entities.User{
+		Id:       arg.ID,
+		Login:    arg.Login,
+		Password: arg.Password,
+		Role:     models.RoleUser,
+	}
+
+	return nil
+}
+
+func (s *memoryStorage) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
+	if arg.ID == uuid.Nil {
+		return fmt.Errorf("error user id is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[arg.ID]
+	if !ok {
+		return errors2.ErrNotFound
+	}
+
+	if arg.Login != "" && arg.Login != user.Login {
+		if s.loginTaken(arg.Login) {
+			return fmt.Errorf("error update user login: %w", errors2.ErrAlreadyExists)
+		}
+		user.Login = arg.Login
+	}
+
+	if arg.Password != "" {
+		user.Password = arg.Password
+		user.PasswordResetRequired = false
+		user.PasswordResetHash = ""
+	}
+
+	if arg.Role != "" {
+		user.Role = arg.Role
+	}
+
+	if arg.Disabled != nil {
+		user.DisabledAt = nil
+		if *arg.Disabled {
+			now := time.Now()
+			user.DisabledAt = &now
+		}
+	}
+
+	if arg.PasswordResetHash != "" {
+		user.PasswordResetRequired = true
+		user.PasswordResetHash = arg.PasswordResetHash
+	}
+
+	s.users[arg.ID] = user
+	return nil
+}
+
+// DeleteUser removes the user with everything the user owns.
+func (s *memoryStorage) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return fmt.Errorf("error user id is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, card := range s.flashcards {
+		if card.Owner == userID {
+			s.deleteFlashcard(id)
+		}
+	}
+
+	for id, deck := range s.decks {
+		if deck.Owner == userID {
+			s.deleteDeck(id)
+		}
+	}
+
+	for key := range s.reviews {
+		if key.userID == userID {
+			delete(s.reviews, key)
+		}
+	}
+
+	for id, session := range s.sessions {
+		if session.UserID == userID {
+			delete(s.sessions, id)
+		}
+	}
+
+	s.revisions = slices.DeleteFunc(s.revisions, func(revision entities.Revision) bool {
+		return revision.Owner == userID
+	})
+
+	delete(s.users, userID)
+	return nil
+}
+
+func (s *memoryStorage) SelectUser(ctx context.Context, arg SelectUserParams) (*entities.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	switch {
+	case arg.ID != uuid.Nil:
+		if user, ok := s.users[arg.ID]; ok {
+			return &user, nil
+		}
+	case arg.Login != "":
+		for _, user := range s.users {
+			if user.Login == arg.Login {
+				return &user, nil
+			}
+		}
+	default:
+		return nil, fmt.Errorf("error user id or login is required: %w", ErrInvalidData)
+	}
+
+	return nil, fmt.Errorf("error select user: %w", errors2.ErrNotFound)
+}
+
+func (s *memoryStorage) SelectUsers(ctx context.Context, arg SelectUsersParams) ([]*entities.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]*entities.User, 0, len(s.users))
+	for _, user := range s.users {
+		user := user
+		users = append(users, &user)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Login < users[j].Login
+	})
+
+	if arg.Offset >= len(users) {
+		return []*entities.User{}, nil
+	}
+
+	users = users[arg.Offset:]
+	if arg.Limit > 0 && arg.Limit < len(users) {
+		users = users[:arg.Limit]
+	}
+
+	return users, nil
+}
+
+func (s *memoryStorage) loginTaken(login string) bool {
+	for _, user := range s.users {
+		if user.Login == login {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *memoryStorage) CreateFlashcard(ctx context.Context, arg CreateFlashcardParams) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if arg.ID == uuid.Nil {
+		return fmt.Errorf("error flashcard id is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[owner]; !ok {
+		return fmt.Errorf("error create flashcard: %w", errors2.ErrNotFound)
+	}
+
+	if _, ok := s.flashcards[arg.ID]; ok {
+		return fmt.Errorf("error create flashcard: %w", errors2.ErrAlreadyExists)
+	}
+
+	createdAt := flashcardTime()
+	card := entities.Flashcard{
+		ID:            arg.ID,
+		Owner:         owner,
+		Meaning:       arg.Meaning,
+		Word:          arg.Word,
+		UsageExamples: copyStrings(arg.Usage),
+		Tags:          uniqueTags(arg.Tags),
+		CreatedAt:     createdAt,
+		UpdatedAt:     createdAt,
+		Version:       1,
+	}
+
+	revision, err := flashcardRevision(ctx, entities.RevisionCreate, nil, &card)
+	if err != nil {
+		return err
+	}
+
+	s.flashcards[arg.ID] = card
+	s.createRevision(revision)
+	return nil
+}
+
+func (s *memoryStorage) UpdateFlashcard(ctx context.Context, arg UpdateFlashcardParams) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if arg.ID == uuid.Nil {
+		return fmt.Errorf("error id required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	card, ok := s.flashcards[arg.ID]
+	if !ok || card.Owner != owner || card.DeletedAt != nil {
+		return errors2.ErrNotFound
+	}
+
+	if arg.Version != 0 && arg.Version != card.Version {
+		return ErrVersionConflict
+	}
+
+	if !arg.changes() {
+		return nil
+	}
+
+	updated := arg.apply(&card)
+	updated.UpdatedAt = flashcardTime()
+
+	revision, err := flashcardRevision(ctx, entities.RevisionUpdate, &card, updated)
+	if err != nil {
+		return err
+	}
+
+	s.flashcards[arg.ID] = *updated
+	s.createRevision(revision)
+	return nil
+}
+
+func (s *memoryStorage) DeleteFlashcard(ctx context.Context, cardID uuid.UUID) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if cardID == uuid.Nil {
+		return fmt.Errorf("error flashcard uuid is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	card, ok := s.flashcards[cardID]
+	if !ok || card.Owner != owner || card.DeletedAt != nil {
+		return errors2.ErrNotFound
+	}
+
+	deleted := card
+	deletedAt := flashcardTime()
+	deleted.DeletedAt = &deletedAt
+
+	revision, err := flashcardRevision(ctx, entities.RevisionDelete, &card, &deleted)
+	if err != nil {
+		return err
+	}
+
+	s.flashcards[cardID] = deleted
+	s.createRevision(revision)
+	return nil
+}
+
+// deleteFlashcard removes the card for good, from decks and reviews too. The
+// caller holds the write lock.
+func (s *memoryStorage) deleteFlashcard(cardID uuid.UUID) {
+	for _, cards := range s.deckCards {
+		delete(cards, cardID)
+	}
+
+	for key := range s.reviews {
+		if key.flashcardID == cardID {
+			delete(s.reviews, key)
+		}
+	}
+
+	delete(s.flashcards, cardID)
+}
+
+// SelectFlashcard follows sqlStorage.SelectFlashcard: a card is looked up by ID
+// if it is set, or by Word or Meaning within DeckID. Otherwise the cards are
+// listed.
+func (s *memoryStorage) SelectFlashcard(ctx context.Context, arg SelectFlashcardParams) ([]*entities.Flashcard, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if arg.ID != uuid.Nil {
+		card, ok := s.flashcards[arg.ID]
+		if !ok || card.Owner != owner || card.DeletedAt != nil {
+			return nil, fmt.Errorf("error select flashcard: %w", errors2.ErrNotFound)
+		}
+
+		return []*entities.Flashcard{copyFlashcard(card)}, nil
+	}
+
+	if arg.DeckID == uuid.Nil || arg.Word == "" && arg.Meaning == "" {
+		return s.listFlashcards(owner, arg)
+	}
+
+	resp := make([]*entities.Flashcard, 0)
+	for _, card := range s.flashcards {
+		switch {
+		case card.Owner != owner,
+			card.DeletedAt != nil,
+			!s.inDeck(arg.DeckID, card.ID),
+			arg.Word != "" && card.Word != arg.Word,
+			arg.Word == "" && card.Meaning != arg.Meaning:
+			continue
+		}
+
+		resp = append(resp, copyFlashcard(card))
+	}
+
+	sortFlashcards(resp)
+	return resp, nil
+}
+
+// listFlashcards filters, sorts and pages the cards as the squirrel query of
+// the SQL storages does. The caller holds the read lock.
+func (s *memoryStorage) listFlashcards(owner uuid.UUID, arg SelectFlashcardParams) ([]*entities.Flashcard, error) {
+	params, err := listFlashcardsParams(owner, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	if arg.DeckID != uuid.Nil {
+		deck, ok := s.decks[arg.DeckID]
+		if !ok || deck.Owner != owner || deck.DeletedAt != nil {
+			return nil, fmt.Errorf("error select deck: %w", errors2.ErrNotFound)
+		}
+	}
+
+	// compare orders the cards by the sort key and the id
+	compare := func(a, b *entities.Flashcard) int {
+		var c int
+		switch arg.Sort {
+		case SortByCreated:
+			c = a.CreatedAt.Compare(b.CreatedAt)
+		case SortByUpdated:
+			c = a.UpdatedAt.Compare(b.UpdatedAt)
+		default:
+			c = strings.Compare(a.Word, b.Word)
+		}
+
+		if c == 0 {
+			c = strings.Compare(a.ID.String(), b.ID.String())
+		}
+
+		if params.Desc {
+			return -c
+		}
+		return c
+	}
+
+	var after *entities.Flashcard
+	if arg.After != nil {
+		after = &entities.Flashcard{
+			ID:        arg.After.ID,
+			Word:      arg.After.Word,
+			CreatedAt: arg.After.Time,
+			UpdatedAt: arg.After.Time,
+		}
+	}
+
+	prefix := strings.ToLower(params.Prefix)
+
+	resp := make([]*entities.Flashcard, 0)
+	for _, card := range s.flashcards {
+		switch {
+		case card.Owner != owner,
+			card.DeletedAt != nil,
+			params.DeckID != uuid.Nil && !s.inDeck(params.DeckID, card.ID),
+			params.Tag != "" && !slices.Contains(card.Tags, params.Tag),
+			!strings.HasPrefix(strings.ToLower(card.Word), prefix),
+			!params.DueAt.IsZero() && !s.dueBy(owner, card.ID, params.DueAt),
+			after != nil && compare(&card, after) <= 0:
+			continue
+		}
+
+		resp = append(resp, copyFlashcard(card))
+	}
+
+	sort.Slice(resp, func(i, j int) bool {
+		return compare(resp[i], resp[j]) < 0
+	})
+
+	if params.Limit > 0 && len(resp) > int(params.Limit) {
+		resp = resp[:params.Limit]
+	}
+
+	return resp, nil
+}
+
+// dueBy reports whether the user has a review of the card due by t.
+func (s *memoryStorage) dueBy(userID, cardID uuid.UUID, t time.Time) bool {
+	review, ok := s.reviews[reviewKey{userID: userID, flashcardID: cardID}]
+	return ok && !review.DueAt.After(t)
+}
+
+func (s *memoryStorage) SearchFlashcards(ctx context.Context, arg SearchFlashcardsParams) ([]*entities.FlashcardMatch, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	terms, err := searchTerms(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cards, err := s.listFlashcards(owner, SelectFlashcardParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	return matchFlashcards(terms, cards, arg.Limit), nil
+}
+
+func (s *memoryStorage) CreateDeck(ctx context.Context, arg CreateDeckParams) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if arg.ID == uuid.Nil {
+		return fmt.Errorf("error deck uuid is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[owner]; !ok {
+		return fmt.Errorf("error create deck: %w", errors2.ErrNotFound)
+	}
+
+	if _, ok := s.decks[arg.ID]; ok {
+		return fmt.Errorf("error create deck: %w", errors2.ErrAlreadyExists)
+	}
+
+	deck := entities.Deck{
+		Id:      arg.ID,
+		Name:    arg.Name,
+		Owner:   owner,
+		Version: 1,
+	}
+
+	revision, err := deckRevision(ctx, entities.RevisionCreate, nil, &deck)
+	if err != nil {
+		return err
+	}
+
+	s.decks[arg.ID] = deck
+	s.deckCards[arg.ID] = make(map[uuid.UUID]struct{})
+	s.createRevision(revision)
+
+	return nil
+}
+
+func (s *memoryStorage) UpdateDeck(ctx context.Context, arg UpdateDeckParams) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if arg.ID == uuid.Nil {
+		return fmt.Errorf("error deck uuid is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deck, ok := s.decks[arg.ID]
+	if !ok || deck.Owner != owner || deck.DeletedAt != nil {
+		return errors2.ErrNotFound
+	}
+
+	if arg.Version != 0 && arg.Version != deck.Version {
+		return ErrVersionConflict
+	}
+
+	updated := deck
+	updated.Name = arg.Name
+	updated.Version++
+
+	revision, err := deckRevision(ctx, entities.RevisionUpdate, &deck, &updated)
+	if err != nil {
+		return err
+	}
+
+	s.decks[arg.ID] = updated
+	s.createRevision(revision)
+
+	return nil
+}
+
+func (s *memoryStorage) DeleteDeck(ctx context.Context, arg DeleteDeckParams) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if arg.ID == uuid.Nil {
+		return fmt.Errorf("error deck uuid is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deck, ok := s.decks[arg.ID]
+	if !ok || deck.Owner != owner || deck.DeletedAt != nil {
+		return errors2.ErrNotFound
+	}
+
+	deleted := deck
+	deletedAt := flashcardTime()
+	deleted.DeletedAt = &deletedAt
+
+	revision, err := deckRevision(ctx, entities.RevisionDelete, &deck, &deleted)
+	if err != nil {
+		return err
+	}
+
+	s.decks[arg.ID] = deleted
+	s.createRevision(revision)
+	return nil
+}
+
+// deleteDeck removes the deck for good. The caller holds the write lock.
+func (s *memoryStorage) deleteDeck(deckID uuid.UUID) {
+	delete(s.deckCards, deckID)
+	delete(s.decks, deckID)
+}
+
+// SelectDeck returns a single deck of the caller, found by id or, if id is not set, by name.
+func (s *memoryStorage) SelectDeck(ctx context.Context, arg SelectDeckParams) (*entities.Deck, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if arg.ID == uuid.Nil {
+		decks, err := s.SelectDecks(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(decks) == 0 {
+			return nil, errors2.ErrNotFound
+		}
+
+		return decks[0], nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deck, ok := s.decks[arg.ID]
+	if !ok || deck.Owner != owner || deck.DeletedAt != nil {
+		return nil, fmt.Errorf("error select deck: %w", errors2.ErrNotFound)
+	}
+
+	return &deck, nil
+}
+
+// SelectDecks returns all decks of the caller ordered by name. If name is set, only decks with this name are returned.
+func (s *memoryStorage) SelectDecks(ctx context.Context, arg SelectDeckParams) ([]*entities.Deck, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resp := make([]*entities.Deck, 0)
+	for _, deck := range s.decks {
+		if deck.Owner != owner || deck.DeletedAt != nil || (arg.Name != "" && deck.Name != arg.Name) {
+			continue
+		}
+
+		deck := deck
+		resp = append(resp, &deck)
+	}
+
+	sort.Slice(resp, func(i, j int) bool {
+		if resp[i].Name != resp[j].Name {
+			return resp[i].Name < resp[j].Name
+		}
+		return resp[i].Id.String() < resp[j].Id.String()
+	})
+
+	return resp, nil
+}
+
+func (s *memoryStorage) AddToDeck(ctx context.Context, arg AddToDeckParams) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if arg.DeckID == uuid.Nil || arg.FlashcardID == uuid.Nil {
+		return fmt.Errorf("error deck id and flashcard id are required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deck, ok := s.decks[arg.DeckID]
+	if !ok || deck.Owner != owner || deck.DeletedAt != nil {
+		return fmt.Errorf("error select deck: %w", errors2.ErrNotFound)
+	}
+
+	// cards can be added only to decks of the same owner
+	card, ok := s.flashcards[arg.FlashcardID]
+	if !ok || card.Owner != owner || card.DeletedAt != nil {
+		return fmt.Errorf("error select flashcard: %w", errors2.ErrNotFound)
+	}
+
+	s.deckCards[arg.DeckID][arg.FlashcardID] = struct{}{}
+	return nil
+}
+
+func (s *memoryStorage) DeleteFromDeck(ctx context.Context, arg DeleteFromDeckParams) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if arg.DeckID == uuid.Nil || arg.FlashcardID == uuid.Nil {
+		return fmt.Errorf("error deck id and flashcard id are required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deck, ok := s.decks[arg.DeckID]
+	if !ok || deck.Owner != owner || deck.DeletedAt != nil {
+		return fmt.Errorf("error select deck: %w", errors2.ErrNotFound)
+	}
+
+	if !s.inDeck(arg.DeckID, arg.FlashcardID) {
+		return errors2.ErrNotFound
+	}
+
+	delete(s.deckCards[arg.DeckID], arg.FlashcardID)
+	return nil
+}
+
+// SelectFromDeck returns flashcards of the deck. Optional CardID, Word and WordMeaning
+// narrow the result down to matching cards.
+func (s *memoryStorage) SelectFromDeck(ctx context.Context, arg SelectFromDeckParams) ([]*entities.Flashcard, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if arg.DeckID == uuid.Nil {
+		return nil, fmt.Errorf("error deck id is required")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deck, ok := s.decks[arg.DeckID]
+	if !ok || deck.Owner != owner || deck.DeletedAt != nil {
+		return nil, fmt.Errorf("error select deck: %w", errors2.ErrNotFound)
+	}
+
+	resp := make([]*entities.Flashcard, 0, len(s.deckCards[arg.DeckID]))
+	for id := range s.deckCards[arg.DeckID] {
+		card := s.flashcards[id]
+		switch {
+		case card.DeletedAt != nil,
+			arg.CardID != uuid.Nil && card.ID != arg.CardID,
+			arg.Word != "" && card.Word != arg.Word,
+			arg.WordMeaning != "" && card.Meaning != arg.WordMeaning:
+			continue
+		}
+
+		resp = append(resp, copyFlashcard(card))
+	}
+
+	sortFlashcards(resp)
+	return resp, nil
+}
+
+func (s *memoryStorage) inDeck(deckID, cardID uuid.UUID) bool {
+	_, ok := s.deckCards[deckID][cardID]
+	return ok
+}
+
+func (s *memoryStorage) SelectDeletedFlashcards(ctx context.Context) ([]*entities.Flashcard, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resp := make([]*entities.Flashcard, 0)
+	for _, card := range s.flashcards {
+		if card.Owner == owner && card.DeletedAt != nil {
+			resp = append(resp, copyFlashcard(card))
+		}
+	}
+
+	sort.Slice(resp, func(i, j int) bool {
+		return deletedFirst(*resp[i].DeletedAt, *resp[j].DeletedAt, resp[i].ID, resp[j].ID)
+	})
+
+	return resp, nil
+}
+
+func (s *memoryStorage) SelectDeletedDecks(ctx context.Context) ([]*entities.Deck, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resp := make([]*entities.Deck, 0)
+	for _, deck := range s.decks {
+		if deck.Owner == owner && deck.DeletedAt != nil {
+			deck := deck
+			resp = append(resp, &deck)
+		}
+	}
+
+	sort.Slice(resp, func(i, j int) bool {
+		return deletedFirst(*resp[i].DeletedAt, *resp[j].DeletedAt, resp[i].Id, resp[j].Id)
+	})
+
+	return resp, nil
+}
+
+// deletedFirst orders the trash as the SQL storages do, the most recently
+// deleted first and then by the id.
+func deletedFirst(a, b time.Time, aID, bID uuid.UUID) bool {
+	if !a.Equal(b) {
+		return a.After(b)
+	}
+	return aID.String() < bID.String()
+}
+
+func (s *memoryStorage) RestoreFlashcard(ctx context.Context, cardID uuid.UUID) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if cardID == uuid.Nil {
+		return fmt.Errorf("error flashcard uuid is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	card, ok := s.flashcards[cardID]
+	if !ok || card.Owner != owner || card.DeletedAt == nil {
+		return errors2.ErrNotFound
+	}
+
+	restored := card
+	restored.DeletedAt = nil
+
+	revision, err := flashcardRevision(ctx, entities.RevisionRestore, &card, &restored)
+	if err != nil {
+		return err
+	}
+
+	s.flashcards[cardID] = restored
+	s.createRevision(revision)
+	return nil
+}
+
+func (s *memoryStorage) RestoreDeck(ctx context.Context, arg RestoreDeckParams) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if arg.ID == uuid.Nil {
+		return fmt.Errorf("error deck uuid is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deck, ok := s.decks[arg.ID]
+	if !ok || deck.Owner != owner || deck.DeletedAt == nil {
+		return errors2.ErrNotFound
+	}
+
+	restored := deck
+	restored.DeletedAt = nil
+
+	revision, err := deckRevision(ctx, entities.RevisionRestore, &deck, &restored)
+	if err != nil {
+		return err
+	}
+
+	s.decks[arg.ID] = restored
+	s.createRevision(revision)
+	return nil
+}
+
+func (s *memoryStorage) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, card := range s.flashcards {
+		if card.DeletedAt != nil && card.DeletedAt.Before(before) {
+			s.deleteFlashcard(id)
+			purged++
+		}
+	}
+
+	for id, deck := range s.decks {
+		if deck.DeletedAt != nil && deck.DeletedAt.Before(before) {
+			s.deleteDeck(id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+func (s *memoryStorage) SelectRevisions(ctx context.Context, arg SelectRevisionsParams) ([]*entities.Revision, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resp := make([]*entities.Revision, 0)
+	for _, revision := range s.revisions {
+		if revision.Owner == owner && revision.EntityType == arg.EntityType && revision.EntityID == arg.EntityID {
+			resp = append(resp, copyRevision(revision))
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *memoryStorage) SelectRevision(ctx context.Context, arg SelectRevisionsParams) (*entities.Revision, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if arg.ID == uuid.Nil {
+		return nil, fmt.Errorf("error revision id is required")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, revision := range s.revisions {
+		if revision.ID == arg.ID && revision.Owner == owner && revision.EntityType == arg.EntityType && revision.EntityID == arg.EntityID {
+			return copyRevision(revision), nil
+		}
+	}
+
+	return nil, errors2.ErrNotFound
+}
+
+func (s *memoryStorage) UpsertReview(ctx context.Context, arg UpsertReviewParams) error {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if arg.FlashcardID == uuid.Nil {
+		return fmt.Errorf("error flashcard uuid is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, userOk := s.users[userID]
+	_, cardOk := s.flashcards[arg.FlashcardID]
+	if !userOk || !cardOk {
+		return fmt.Errorf("error upsert review: %w", errors2.ErrNotFound)
+	}
+
+	review := entities.Review{
+		UserID:      userID,
+		FlashcardID: arg.FlashcardID,
+		EaseFactor:  arg.EaseFactor,
+		Interval:    arg.Interval,
+		Repetitions: arg.Repetitions,
+		Lapses:      arg.Lapses,
+		DueAt:       arg.DueAt,
+	}
+	if arg.LastReviewedAt != nil {
+		lastReviewedAt := *arg.LastReviewedAt
+		review.LastReviewedAt = &lastReviewedAt
+	}
+
+	s.reviews[reviewKey{userID: userID, flashcardID: arg.FlashcardID}] = review
+	return nil
+}
+
+func (s *memoryStorage) SelectReview(ctx context.Context, arg SelectReviewParams) (*entities.Review, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	review, ok := s.reviews[reviewKey{userID: userID, flashcardID: arg.FlashcardID}]
+	if !ok {
+		return nil, fmt.Errorf("error select review: %w", errors2.ErrNotFound)
+	}
+
+	return &review, nil
+}
+
+// SelectDueReviews returns reviews of the caller due at arg.DueAt, the most
+// overdue first.
+func (s *memoryStorage) SelectDueReviews(ctx context.Context, arg SelectDueReviewsParams) ([]*entities.Review, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resp := make([]*entities.Review, 0)
+	for key, review := range s.reviews {
+		card := s.flashcards[key.flashcardID]
+		if key.userID != userID || review.DueAt.After(arg.DueAt) || card.DeletedAt != nil {
+			continue
+		}
+
+		review := review
+		review.Flashcard = &entities.Flashcard{
+			ID:            card.ID,
+			Word:          card.Word,
+			Meaning:       card.Meaning,
+			UsageExamples: copyStrings(card.UsageExamples),
+		}
+		resp = append(resp, &review)
+	}
+
+	sort.Slice(resp, func(i, j int) bool {
+		return resp[i].DueAt.Before(resp[j].DueAt)
+	})
+
+	if len(resp) > arg.Limit {
+		resp = resp[:arg.Limit]
+	}
+
+	return resp, nil
+}
+
+func (s *memoryStorage) CreateSession(ctx context.Context, arg CreateSessionParams) error {
+	if arg.ID == uuid.Nil || arg.UserID == uuid.Nil || arg.RefreshHash == "" {
+		return fmt.Errorf("error invalid session: %w", ErrInvalidData)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return fmt.Errorf("error create session: %w", errors2.ErrNotFound)
+	}
+
+	if _, ok := s.sessions[arg.ID]; ok {
+		return fmt.Errorf("error create session: %w", errors2.ErrAlreadyExists)
+	}
+
+	s.sessions[arg.ID] = entities.Session{
+		ID:          arg.ID,
+		UserID:      arg.UserID,
+		RefreshHash: arg.RefreshHash,
+		CreatedAt:   time.Now(),
+		ExpiresAt:   arg.ExpiresAt,
+	}
+
+	return nil
+}
+
+func (s *memoryStorage) SelectSession(ctx context.Context, sessionID uuid.UUID) (*entities.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("error select session: %w", errors2.ErrNotFound)
+	}
+
+	return &session, nil
+}
+
+func (s *memoryStorage) RotateSession(ctx context.Context, arg RotateSessionParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[arg.ID]
+	if !ok || session.RevokedAt != nil || session.RefreshHash != arg.OldHash {
+		return errors2.ErrNotFound
+	}
+
+	session.RefreshHash = arg.RefreshHash
+	session.ExpiresAt = arg.ExpiresAt
+	s.sessions[arg.ID] = session
+
+	return nil
+}
+
+func (s *memoryStorage) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok || session.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	session.RevokedAt = &now
+	s.sessions[sessionID] = session
+
+	return nil
+}
+
+func (s *memoryStorage) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			s.sessions[id] = session
+		}
+	}
+
+	return nil
+}
+
+// copyFlashcard returns a copy that doesn't share the usage examples and tags
+// with the stored card, so callers can't change it without the lock.
+func copyFlashcard(card entities.Flashcard) *entities.Flashcard {
+	card.UsageExamples = copyStrings(card.UsageExamples)
+	card.Tags = copyStrings(card.Tags)
+	if card.DeletedAt != nil {
+		deletedAt := *card.DeletedAt
+		card.DeletedAt = &deletedAt
+	}
+	return &card
+}
+
+// createRevision follows sqlStorage.createRevision. The caller holds the write
+// lock.
+func (s *memoryStorage) createRevision(revision *entities.Revision) {
+	if len(revision.Changes) == 0 {
+		return
+	}
+
+	s.revisions = append(s.revisions, *revision)
+}
+
+// copyRevision returns a copy that doesn't share the changes and the snapshot
+// with the stored revision.
+func copyRevision(revision entities.Revision) *entities.Revision {
+	revision.Changes = maps.Clone(revision.Changes)
+	revision.Snapshot = slices.Clone(revision.Snapshot)
+	return &revision
+}
+
+func copyStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+
+	return append(make([]string, 0, len(s)), s...)
+}
+
+func sortFlashcards(cards []*entities.Flashcard) {
+	sort.Slice(cards, func(i, j int) bool {
+		if cards[i].Word != cards[j].Word {
+			return cards[i].Word < cards[j].Word
+		}
+		return cards[i].ID.String() < cards[j].ID.String()
+	})
+}
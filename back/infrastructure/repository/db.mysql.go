@@ -0,0 +1,351 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"languago/infrastructure/repository/database"
+	"languago/infrastructure/repository/mysql"
+	"languago/pkg/models/entities"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+// mysqlQueries are the statements of MySQL, usage examples are stored as a JSON
+// column and there is no full text search, see matchFlashcards.
+type mysqlQueries struct {
+	db *mysql.Queries
+}
+
+// Storage implementation for MySQL database
+func newMySQLStorage(db *sql.DB) *sqlStorage {
+	return newSQLStorage(db, mysqlQueries{db: mysql.New(db)}, database.New(db, sq.Question))
+}
+
+func (q mysqlQueries) withTx(tx *sql.Tx) sqlQueries {
+	return mysqlQueries{db: q.db.WithTx(tx)}
+}
+
+func (q mysqlQueries) createUser(ctx context.Context, id uuid.UUID, login, password string) error {
+	return q.db.CreateUser(ctx, mysql.CreateUserParams{
+		ID:       id,
+		Login:    sql.NullString{String: login, Valid: true},
+		Password: sql.NullString{String: password, Valid: true},
+	})
+}
+
+func (q mysqlQueries) updateUserLogin(ctx context.Context, id uuid.UUID, login string) (int64, error) {
+	return q.db.UpdateUserLogin(ctx, mysql.UpdateUserLoginParams{
+		ID:    id,
+		Login: sql.NullString{String: login, Valid: true},
+	})
+}
+
+func (q mysqlQueries) updateUserPassword(ctx context.Context, id uuid.UUID, password string) (int64, error) {
+	return q.db.UpdateUserPassword(ctx, mysql.UpdateUserPasswordParams{
+		ID:       id,
+		Password: sql.NullString{String: password, Valid: true},
+	})
+}
+
+func (q mysqlQueries) updateUserRole(ctx context.Context, id uuid.UUID, role string) (int64, error) {
+	return q.db.UpdateUserRole(ctx, mysql.UpdateUserRoleParams{ID: id, Role: role})
+}
+
+func (q mysqlQueries) updateUserDisabled(ctx context.Context, id uuid.UUID, disabledAt sql.NullTime) (int64, error) {
+	return q.db.UpdateUserDisabled(ctx, mysql.UpdateUserDisabledParams{ID: id, DisabledAt: disabledAt})
+}
+
+func (q mysqlQueries) requirePasswordReset(ctx context.Context, id uuid.UUID, hash string) (int64, error) {
+	return q.db.RequirePasswordReset(ctx, mysql.RequirePasswordResetParams{ID: id, PasswordResetHash: hash})
+}
+
+func (q mysqlQueries) deleteUser(ctx context.Context, id uuid.UUID) error {
+	return q.db.DeleteUser(ctx, id)
+}
+
+func (q mysqlQueries) selectUsers(ctx context.Context, limit, offset int) ([]*entities.User, error) {
+	return fromRows(entities.UserFromMySQL)(q.db.SelectUsers(ctx, mysql.SelectUsersParams{
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	}))
+}
+
+func (q mysqlQueries) selectUserByID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
+	return fromRow(entities.UserFromMySQL)(q.db.SelectUserByID(ctx, id))
+}
+
+func (q mysqlQueries) selectUserByLogin(ctx context.Context, login string) (*entities.User, error) {
+	return fromRow(entities.UserFromMySQL)(q.db.SelectUserByLogin(ctx, sql.NullString{String: login, Valid: true}))
+}
+
+func (q mysqlQueries) createFlashcard(ctx context.Context, card *entities.Flashcard) error {
+	usage, err := jsonUsage(card.UsageExamples)
+	if err != nil {
+		return fmt.Errorf("error encode usage: %w", err)
+	}
+
+	return q.db.CreateFlashcard(ctx, mysql.CreateFlashcardParams{
+		ID:        card.ID,
+		Word:      sql.NullString{String: card.Word, Valid: true},
+		Meaning:   sql.NullString{String: card.Meaning, Valid: true},
+		Usage:     usage,
+		Owner:     card.Owner,
+		CreatedAt: card.CreatedAt,
+		UpdatedAt: card.UpdatedAt,
+	})
+}
+
+func (q mysqlQueries) updateFlashcard(ctx context.Context, card *entities.Flashcard, version int64) (int64, error) {
+	usage, err := jsonUsage(card.UsageExamples)
+	if err != nil {
+		return 0, fmt.Errorf("error encode usage: %w", err)
+	}
+
+	return q.db.UpdateFlashcard(ctx, mysql.UpdateFlashcardParams{
+		Word:      sql.NullString{String: card.Word, Valid: true},
+		Meaning:   sql.NullString{String: card.Meaning, Valid: true},
+		Usage:     usage,
+		UpdatedAt: card.UpdatedAt,
+		ID:        card.ID,
+		Owner:     card.Owner,
+		Version:   version,
+	})
+}
+
+func (q mysqlQueries) deleteFlashcard(ctx context.Context, id, owner uuid.UUID, deletedAt time.Time) (int64, error) {
+	return q.db.DeleteFlashcard(ctx, mysql.DeleteFlashcardParams{
+		ID:        id,
+		Owner:     owner,
+		DeletedAt: sql.NullTime{Time: deletedAt, Valid: true},
+	})
+}
+
+func (q mysqlQueries) selectFlashcard(ctx context.Context, id, owner uuid.UUID) (*entities.Flashcard, error) {
+	return fromRow(entities.FlashcardFromMySQL)(q.db.SelectFlashcardByID(ctx, mysql.SelectFlashcardByIDParams{
+		ID:    id,
+		Owner: owner,
+	}))
+}
+
+func (q mysqlQueries) selectFlashcardsByWord(ctx context.Context, deckID, owner uuid.UUID, word string) ([]*entities.Flashcard, error) {
+	return fromRows(entities.FlashcardFromMySQL)(q.db.SelectFlashcardByWord(ctx, mysql.SelectFlashcardByWordParams{
+		DeckID: deckID,
+		Owner:  owner,
+		Word:   sql.NullString{String: word, Valid: true},
+	}))
+}
+
+func (q mysqlQueries) selectFlashcardsByMeaning(ctx context.Context, deckID, owner uuid.UUID, meaning string) ([]*entities.Flashcard, error) {
+	return fromRows(entities.FlashcardFromMySQL)(q.db.SelectFlashcardByMeaning(ctx, mysql.SelectFlashcardByMeaningParams{
+		DeckID:  deckID,
+		Owner:   owner,
+		Meaning: sql.NullString{String: meaning, Valid: true},
+	}))
+}
+
+func (q mysqlQueries) decodeUsage(raw []byte) ([]string, error) {
+	return entities.UsageFromJSON(raw), nil
+}
+
+func (q mysqlQueries) createDeck(ctx context.Context, id, owner uuid.UUID, name string) error {
+	return q.db.CreateDeck(ctx, mysql.CreateDeckParams{
+		ID:    id,
+		Name:  sql.NullString{String: name, Valid: true},
+		Owner: owner,
+	})
+}
+
+func (q mysqlQueries) updateDeck(ctx context.Context, id, owner uuid.UUID, name string, version int64) (int64, error) {
+	return q.db.EditDeckProps(ctx, mysql.EditDeckPropsParams{
+		ID:      id,
+		Name:    sql.NullString{String: name, Valid: true},
+		Owner:   owner,
+		Version: version,
+	})
+}
+
+func (q mysqlQueries) deleteDeck(ctx context.Context, id, owner uuid.UUID, deletedAt time.Time) (int64, error) {
+	return q.db.DeleteDeck(ctx, mysql.DeleteDeckParams{
+		ID:        id,
+		Owner:     owner,
+		DeletedAt: sql.NullTime{Time: deletedAt, Valid: true},
+	})
+}
+
+func (q mysqlQueries) selectDeck(ctx context.Context, id, owner uuid.UUID) (*entities.Deck, error) {
+	return fromRow(entities.DeckFromMySQL)(q.db.SelectDeck(ctx, mysql.SelectDeckParams{ID: id, Owner: owner}))
+}
+
+func (q mysqlQueries) selectDecks(ctx context.Context, owner uuid.UUID) ([]*entities.Deck, error) {
+	return fromRows(entities.DeckFromMySQL)(q.db.SelectOwnerDecks(ctx, owner))
+}
+
+func (q mysqlQueries) selectDecksByName(ctx context.Context, owner uuid.UUID, name string) ([]*entities.Deck, error) {
+	return fromRows(entities.DeckFromMySQL)(q.db.SelectDecksByName(ctx, mysql.SelectDecksByNameParams{
+		Owner: owner,
+		Name:  sql.NullString{String: name, Valid: true},
+	}))
+}
+
+func (q mysqlQueries) addToDeck(ctx context.Context, deckID, cardID uuid.UUID) error {
+	return q.db.AddToDeck(ctx, mysql.AddToDeckParams{DeckID: deckID, FlashcardID: cardID})
+}
+
+func (q mysqlQueries) deleteFromDeck(ctx context.Context, deckID, cardID uuid.UUID) (int64, error) {
+	return q.db.DeleteFromDeck(ctx, mysql.DeleteFromDeckParams{DeckID: deckID, FlashcardID: cardID})
+}
+
+func (q mysqlQueries) selectDeckFlashcards(ctx context.Context, deckID uuid.UUID) ([]*entities.Flashcard, error) {
+	return fromRows(entities.FlashcardFromMySQL)(q.db.SelectDeckFlashcards(ctx, deckID))
+}
+
+func (q mysqlQueries) selectDeletedFlashcards(ctx context.Context, owner uuid.UUID) ([]*entities.Flashcard, error) {
+	return fromRows(entities.FlashcardFromMySQL)(q.db.SelectDeletedFlashcards(ctx, owner))
+}
+
+func (q mysqlQueries) selectDeletedFlashcard(ctx context.Context, id, owner uuid.UUID) (*entities.Flashcard, error) {
+	return fromRow(entities.FlashcardFromMySQL)(q.db.SelectDeletedFlashcard(ctx, mysql.SelectDeletedFlashcardParams{
+		ID:    id,
+		Owner: owner,
+	}))
+}
+
+func (q mysqlQueries) restoreFlashcard(ctx context.Context, id, owner uuid.UUID) (int64, error) {
+	return q.db.RestoreFlashcard(ctx, mysql.RestoreFlashcardParams{ID: id, Owner: owner})
+}
+
+func (q mysqlQueries) selectDeletedDecks(ctx context.Context, owner uuid.UUID) ([]*entities.Deck, error) {
+	return fromRows(entities.DeckFromMySQL)(q.db.SelectDeletedDecks(ctx, owner))
+}
+
+func (q mysqlQueries) selectDeletedDeck(ctx context.Context, id, owner uuid.UUID) (*entities.Deck, error) {
+	return fromRow(entities.DeckFromMySQL)(q.db.SelectDeletedDeck(ctx, mysql.SelectDeletedDeckParams{ID: id, Owner: owner}))
+}
+
+func (q mysqlQueries) restoreDeck(ctx context.Context, id, owner uuid.UUID) (int64, error) {
+	return q.db.RestoreDeck(ctx, mysql.RestoreDeckParams{ID: id, Owner: owner})
+}
+
+func (q mysqlQueries) purgeFlashcards(ctx context.Context, before time.Time) (int64, error) {
+	return q.db.PurgeFlashcards(ctx, sql.NullTime{Time: before, Valid: true})
+}
+
+func (q mysqlQueries) purgeDecks(ctx context.Context, before time.Time) (int64, error) {
+	return q.db.PurgeDecks(ctx, sql.NullTime{Time: before, Valid: true})
+}
+
+func (q mysqlQueries) createRevision(ctx context.Context, revision *entities.Revision, changes string) error {
+	return q.db.CreateRevision(ctx, mysql.CreateRevisionParams{
+		ID:         revision.ID,
+		EntityType: string(revision.EntityType),
+		EntityID:   revision.EntityID,
+		Owner:      revision.Owner,
+		Version:    revision.Version,
+		Action:     string(revision.Action),
+		ActorID:    revision.ActorID,
+		RequestID:  revision.RequestID,
+		Changes:    changes,
+		Snapshot:   string(revision.Snapshot),
+		CreatedAt:  revision.CreatedAt,
+	})
+}
+
+func (q mysqlQueries) selectRevisions(ctx context.Context, entityType entities.EntityType, entityID, owner uuid.UUID) ([]*entities.Revision, error) {
+	return fromRows(entities.RevisionFromMySQL)(q.db.SelectRevisions(ctx, mysql.SelectRevisionsParams{
+		EntityType: string(entityType),
+		EntityID:   entityID,
+		Owner:      owner,
+	}))
+}
+
+func (q mysqlQueries) selectRevision(ctx context.Context, id uuid.UUID, entityType entities.EntityType, entityID, owner uuid.UUID) (*entities.Revision, error) {
+	return fromRow(entities.RevisionFromMySQL)(q.db.SelectRevision(ctx, mysql.SelectRevisionParams{
+		ID:         id,
+		EntityType: string(entityType),
+		EntityID:   entityID,
+		Owner:      owner,
+	}))
+}
+
+func (q mysqlQueries) upsertReview(ctx context.Context, userID uuid.UUID, arg UpsertReviewParams) error {
+	params := mysql.UpsertReviewParams{
+		UserID:       userID,
+		FlashcardID:  arg.FlashcardID,
+		EaseFactor:   arg.EaseFactor,
+		IntervalDays: int32(arg.Interval),
+		Repetitions:  int32(arg.Repetitions),
+		Lapses:       int32(arg.Lapses),
+		DueAt:        arg.DueAt,
+	}
+	if arg.LastReviewedAt != nil {
+		params.LastReviewedAt = sql.NullTime{Time: *arg.LastReviewedAt, Valid: true}
+	}
+
+	return q.db.UpsertReview(ctx, params)
+}
+
+func (q mysqlQueries) selectReview(ctx context.Context, userID, cardID uuid.UUID) (*entities.Review, error) {
+	return fromRow(entities.ReviewFromMySQL)(q.db.SelectReview(ctx, mysql.SelectReviewParams{
+		UserID:      userID,
+		FlashcardID: cardID,
+	}))
+}
+
+func (q mysqlQueries) selectDueReviews(ctx context.Context, userID uuid.UUID, dueAt time.Time, limit int) ([]*entities.Review, error) {
+	return fromRows(func(row mysql.SelectDueReviewsRow) *entities.Review {
+		review := entities.ReviewFromMySQL(mysql.Review{
+			UserID:         row.UserID,
+			FlashcardID:    row.FlashcardID,
+			EaseFactor:     row.EaseFactor,
+			IntervalDays:   row.IntervalDays,
+			Repetitions:    row.Repetitions,
+			Lapses:         row.Lapses,
+			DueAt:          row.DueAt,
+			LastReviewedAt: row.LastReviewedAt,
+		})
+		review.Flashcard = &entities.Flashcard{
+			ID:            row.FlashcardID,
+			Word:          row.Word.String,
+			Meaning:       row.Meaning.String,
+			UsageExamples: entities.UsageFromJSON([]byte(row.Usage.String)),
+		}
+		return review
+	})(q.db.SelectDueReviews(ctx, mysql.SelectDueReviewsParams{
+		UserID: userID,
+		DueAt:  dueAt,
+		Limit:  int32(limit),
+	}))
+}
+
+func (q mysqlQueries) createSession(ctx context.Context, arg CreateSessionParams) error {
+	return q.db.CreateSession(ctx, mysql.CreateSessionParams{
+		ID:          arg.ID,
+		UserID:      arg.UserID,
+		RefreshHash: arg.RefreshHash,
+		ExpiresAt:   arg.ExpiresAt,
+	})
+}
+
+func (q mysqlQueries) selectSession(ctx context.Context, id uuid.UUID) (*entities.Session, error) {
+	return fromRow(entities.SessionFromMySQL)(q.db.SelectSession(ctx, id))
+}
+
+func (q mysqlQueries) rotateSession(ctx context.Context, arg RotateSessionParams) (int64, error) {
+	return q.db.RotateSession(ctx, mysql.RotateSessionParams{
+		RefreshHash:   arg.RefreshHash,
+		ExpiresAt:     arg.ExpiresAt,
+		ID:            arg.ID,
+		RefreshHash_2: arg.OldHash,
+	})
+}
+
+func (q mysqlQueries) revokeSession(ctx context.Context, id uuid.UUID) error {
+	return q.db.RevokeSession(ctx, id)
+}
+
+func (q mysqlQueries) revokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	return q.db.RevokeUserSessions(ctx, userID)
+}
@@ -0,0 +1,386 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"languago/infrastructure/repository/database"
+	"languago/infrastructure/repository/postgresql"
+	"languago/pkg/models/entities"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// pgQueries are the statements of PostgreSQL, usage examples are stored as a
+// text array.
+type pgQueries struct {
+	db *postgresql.Queries
+}
+
+// Storage implementation for PostgreSQL database
+func newPGStorage(db *sql.DB) *sqlStorage {
+	return newSQLStorage(db, pgQueries{db: postgresql.New(db)}, database.New(db, sq.Dollar))
+}
+
+func (q pgQueries) withTx(tx *sql.Tx) sqlQueries {
+	return pgQueries{db: q.db.WithTx(tx)}
+}
+
+func (q pgQueries) createUser(ctx context.Context, id uuid.UUID, login, password string) error {
+	return q.db.CreateUser(ctx, postgresql.CreateUserParams{
+		ID:       id,
+		Login:    sql.NullString{String: login, Valid: true},
+		Password: sql.NullString{String: password, Valid: true},
+	})
+}
+
+func (q pgQueries) updateUserLogin(ctx context.Context, id uuid.UUID, login string) (int64, error) {
+	return q.db.UpdateUserLogin(ctx, postgresql.UpdateUserLoginParams{
+		ID:    id,
+		Login: sql.NullString{String: login, Valid: true},
+	})
+}
+
+func (q pgQueries) updateUserPassword(ctx context.Context, id uuid.UUID, password string) (int64, error) {
+	return q.db.UpdateUserPassword(ctx, postgresql.UpdateUserPasswordParams{
+		ID:       id,
+		Password: sql.NullString{String: password, Valid: true},
+	})
+}
+
+func (q pgQueries) updateUserRole(ctx context.Context, id uuid.UUID, role string) (int64, error) {
+	return q.db.UpdateUserRole(ctx, postgresql.UpdateUserRoleParams{ID: id, Role: role})
+}
+
+func (q pgQueries) updateUserDisabled(ctx context.Context, id uuid.UUID, disabledAt sql.NullTime) (int64, error) {
+	return q.db.UpdateUserDisabled(ctx, postgresql.UpdateUserDisabledParams{ID: id, DisabledAt: disabledAt})
+}
+
+func (q pgQueries) requirePasswordReset(ctx context.Context, id uuid.UUID, hash string) (int64, error) {
+	return q.db.RequirePasswordReset(ctx, postgresql.RequirePasswordResetParams{ID: id, PasswordResetHash: hash})
+}
+
+func (q pgQueries) deleteUser(ctx context.Context, id uuid.UUID) error {
+	return q.db.DeleteUser(ctx, id)
+}
+
+func (q pgQueries) selectUsers(ctx context.Context, limit, offset int) ([]*entities.User, error) {
+	return fromRows(entities.UserFromPG)(q.db.SelectUsers(ctx, postgresql.SelectUsersParams{
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	}))
+}
+
+func (q pgQueries) selectUserByID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
+	return fromRow(entities.UserFromPG)(q.db.SelectUserByID(ctx, id))
+}
+
+func (q pgQueries) selectUserByLogin(ctx context.Context, login string) (*entities.User, error) {
+	return fromRow(entities.UserFromPG)(q.db.SelectUserByLogin(ctx, sql.NullString{String: login, Valid: true}))
+}
+
+func (q pgQueries) createFlashcard(ctx context.Context, card *entities.Flashcard) error {
+	_, err := q.db.CreateFlashcard(ctx, postgresql.CreateFlashcardParams{
+		ID:        card.ID,
+		Word:      sql.NullString{String: card.Word, Valid: true},
+		Meaning:   sql.NullString{String: card.Meaning, Valid: true},
+		Usage:     card.UsageExamples,
+		Owner:     card.Owner,
+		CreatedAt: card.CreatedAt,
+		UpdatedAt: card.UpdatedAt,
+	})
+	return err
+}
+
+func (q pgQueries) updateFlashcard(ctx context.Context, card *entities.Flashcard, version int64) (int64, error) {
+	return q.db.UpdateFlashcard(ctx, postgresql.UpdateFlashcardParams{
+		Word:      sql.NullString{String: card.Word, Valid: true},
+		Meaning:   sql.NullString{String: card.Meaning, Valid: true},
+		Usage:     card.UsageExamples,
+		UpdatedAt: card.UpdatedAt,
+		ID:        card.ID,
+		Owner:     card.Owner,
+		Version:   version,
+	})
+}
+
+func (q pgQueries) deleteFlashcard(ctx context.Context, id, owner uuid.UUID, deletedAt time.Time) (int64, error) {
+	return q.db.DeleteFlashcard(ctx, postgresql.DeleteFlashcardParams{
+		ID:        id,
+		Owner:     owner,
+		DeletedAt: sql.NullTime{Time: deletedAt, Valid: true},
+	})
+}
+
+func (q pgQueries) selectFlashcard(ctx context.Context, id, owner uuid.UUID) (*entities.Flashcard, error) {
+	return fromRow(entities.FlashcardFromPG)(q.db.SelectFlashcardByID(ctx, postgresql.SelectFlashcardByIDParams{
+		ID:    id,
+		Owner: owner,
+	}))
+}
+
+func (q pgQueries) selectFlashcardsByWord(ctx context.Context, deckID, owner uuid.UUID, word string) ([]*entities.Flashcard, error) {
+	return fromRows(entities.FlashcardFromPG)(q.db.SelectFlashcardByWord(ctx, postgresql.SelectFlashcardByWordParams{
+		DeckID: deckID,
+		Owner:  owner,
+		Word:   sql.NullString{String: word, Valid: true},
+	}))
+}
+
+func (q pgQueries) selectFlashcardsByMeaning(ctx context.Context, deckID, owner uuid.UUID, meaning string) ([]*entities.Flashcard, error) {
+	return fromRows(entities.FlashcardFromPG)(q.db.SelectFlashcardByMeaning(ctx, postgresql.SelectFlashcardByMeaningParams{
+		DeckID:  deckID,
+		Owner:   owner,
+		Meaning: sql.NullString{String: meaning, Valid: true},
+	}))
+}
+
+func (q pgQueries) decodeUsage(raw []byte) ([]string, error) {
+	var usage pq.StringArray
+	if raw == nil {
+		return usage, nil
+	}
+
+	if err := usage.Scan(raw); err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// searchFlashcards matches the full text of the cards, see the flashcards text
+// search configuration, and the word and the meaning by trigram similarity,
+// which tolerates typos.
+func (q pgQueries) searchFlashcards(ctx context.Context, owner uuid.UUID, query string, limit int) ([]*entities.FlashcardMatch, error) {
+	rows, err := q.db.SearchFlashcards(ctx, postgresql.SearchFlashcardsParams{
+		Search:     query,
+		Owner:      owner,
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]*entities.FlashcardMatch, 0, len(rows))
+	for _, row := range rows {
+		resp = append(resp, &entities.FlashcardMatch{
+			Flashcard: entities.FlashcardFromPG(postgresql.Flashcard{
+				ID:        row.ID,
+				Word:      row.Word,
+				Meaning:   row.Meaning,
+				Usage:     row.Usage,
+				Owner:     row.Owner,
+				CreatedAt: row.CreatedAt,
+				UpdatedAt: row.UpdatedAt,
+				Version:   row.Version,
+			}),
+			Rank:    row.Rank,
+			Snippet: row.Snippet,
+		})
+	}
+
+	return resp, nil
+}
+
+func (q pgQueries) createDeck(ctx context.Context, id, owner uuid.UUID, name string) error {
+	_, err := q.db.CreateDeck(ctx, postgresql.CreateDeckParams{
+		ID:    id,
+		Name:  sql.NullString{String: name, Valid: true},
+		Owner: owner,
+	})
+	return err
+}
+
+func (q pgQueries) updateDeck(ctx context.Context, id, owner uuid.UUID, name string, version int64) (int64, error) {
+	return q.db.EditDeckProps(ctx, postgresql.EditDeckPropsParams{
+		ID:      id,
+		Name:    sql.NullString{String: name, Valid: true},
+		Owner:   owner,
+		Version: version,
+	})
+}
+
+func (q pgQueries) deleteDeck(ctx context.Context, id, owner uuid.UUID, deletedAt time.Time) (int64, error) {
+	return q.db.DeleteDeck(ctx, postgresql.DeleteDeckParams{
+		ID:        id,
+		Owner:     owner,
+		DeletedAt: sql.NullTime{Time: deletedAt, Valid: true},
+	})
+}
+
+func (q pgQueries) selectDeck(ctx context.Context, id, owner uuid.UUID) (*entities.Deck, error) {
+	return fromRow(entities.DeckFromPG)(q.db.SelectDeck(ctx, postgresql.SelectDeckParams{ID: id, Owner: owner}))
+}
+
+func (q pgQueries) selectDecks(ctx context.Context, owner uuid.UUID) ([]*entities.Deck, error) {
+	return fromRows(entities.DeckFromPG)(q.db.SelectOwnerDecks(ctx, owner))
+}
+
+func (q pgQueries) selectDecksByName(ctx context.Context, owner uuid.UUID, name string) ([]*entities.Deck, error) {
+	return fromRows(entities.DeckFromPG)(q.db.SelectDecksByName(ctx, postgresql.SelectDecksByNameParams{
+		Owner: owner,
+		Name:  sql.NullString{String: name, Valid: true},
+	}))
+}
+
+func (q pgQueries) addToDeck(ctx context.Context, deckID, cardID uuid.UUID) error {
+	return q.db.AddToDeck(ctx, postgresql.AddToDeckParams{DeckID: deckID, FlashcardID: cardID})
+}
+
+func (q pgQueries) deleteFromDeck(ctx context.Context, deckID, cardID uuid.UUID) (int64, error) {
+	return q.db.DeleteFromDeck(ctx, postgresql.DeleteFromDeckParams{DeckID: deckID, FlashcardID: cardID})
+}
+
+func (q pgQueries) selectDeckFlashcards(ctx context.Context, deckID uuid.UUID) ([]*entities.Flashcard, error) {
+	return fromRows(entities.FlashcardFromPG)(q.db.SelectDeckFlashcards(ctx, deckID))
+}
+
+func (q pgQueries) selectDeletedFlashcards(ctx context.Context, owner uuid.UUID) ([]*entities.Flashcard, error) {
+	return fromRows(entities.FlashcardFromPG)(q.db.SelectDeletedFlashcards(ctx, owner))
+}
+
+func (q pgQueries) selectDeletedFlashcard(ctx context.Context, id, owner uuid.UUID) (*entities.Flashcard, error) {
+	return fromRow(entities.FlashcardFromPG)(q.db.SelectDeletedFlashcard(ctx, postgresql.SelectDeletedFlashcardParams{
+		ID:    id,
+		Owner: owner,
+	}))
+}
+
+func (q pgQueries) restoreFlashcard(ctx context.Context, id, owner uuid.UUID) (int64, error) {
+	return q.db.RestoreFlashcard(ctx, postgresql.RestoreFlashcardParams{ID: id, Owner: owner})
+}
+
+func (q pgQueries) selectDeletedDecks(ctx context.Context, owner uuid.UUID) ([]*entities.Deck, error) {
+	return fromRows(entities.DeckFromPG)(q.db.SelectDeletedDecks(ctx, owner))
+}
+
+func (q pgQueries) selectDeletedDeck(ctx context.Context, id, owner uuid.UUID) (*entities.Deck, error) {
+	return fromRow(entities.DeckFromPG)(q.db.SelectDeletedDeck(ctx, postgresql.SelectDeletedDeckParams{ID: id, Owner: owner}))
+}
+
+func (q pgQueries) restoreDeck(ctx context.Context, id, owner uuid.UUID) (int64, error) {
+	return q.db.RestoreDeck(ctx, postgresql.RestoreDeckParams{ID: id, Owner: owner})
+}
+
+func (q pgQueries) purgeFlashcards(ctx context.Context, before time.Time) (int64, error) {
+	return q.db.PurgeFlashcards(ctx, sql.NullTime{Time: before, Valid: true})
+}
+
+func (q pgQueries) purgeDecks(ctx context.Context, before time.Time) (int64, error) {
+	return q.db.PurgeDecks(ctx, sql.NullTime{Time: before, Valid: true})
+}
+
+func (q pgQueries) createRevision(ctx context.Context, revision *entities.Revision, changes string) error {
+	return q.db.CreateRevision(ctx, postgresql.CreateRevisionParams{
+		ID:         revision.ID,
+		EntityType: string(revision.EntityType),
+		EntityID:   revision.EntityID,
+		Owner:      revision.Owner,
+		Version:    revision.Version,
+		Action:     string(revision.Action),
+		ActorID:    revision.ActorID,
+		RequestID:  revision.RequestID,
+		Changes:    changes,
+		Snapshot:   string(revision.Snapshot),
+		CreatedAt:  revision.CreatedAt,
+	})
+}
+
+func (q pgQueries) selectRevisions(ctx context.Context, entityType entities.EntityType, entityID, owner uuid.UUID) ([]*entities.Revision, error) {
+	return fromRows(entities.RevisionFromPG)(q.db.SelectRevisions(ctx, postgresql.SelectRevisionsParams{
+		EntityType: string(entityType),
+		EntityID:   entityID,
+		Owner:      owner,
+	}))
+}
+
+func (q pgQueries) selectRevision(ctx context.Context, id uuid.UUID, entityType entities.EntityType, entityID, owner uuid.UUID) (*entities.Revision, error) {
+	return fromRow(entities.RevisionFromPG)(q.db.SelectRevision(ctx, postgresql.SelectRevisionParams{
+		ID:         id,
+		EntityType: string(entityType),
+		EntityID:   entityID,
+		Owner:      owner,
+	}))
+}
+
+func (q pgQueries) upsertReview(ctx context.Context, userID uuid.UUID, arg UpsertReviewParams) error {
+	params := postgresql.UpsertReviewParams{
+		UserID:       userID,
+		FlashcardID:  arg.FlashcardID,
+		EaseFactor:   arg.EaseFactor,
+		IntervalDays: int32(arg.Interval),
+		Repetitions:  int32(arg.Repetitions),
+		Lapses:       int32(arg.Lapses),
+		DueAt:        arg.DueAt,
+	}
+	if arg.LastReviewedAt != nil {
+		params.LastReviewedAt = sql.NullTime{Time: *arg.LastReviewedAt, Valid: true}
+	}
+
+	return q.db.UpsertReview(ctx, params)
+}
+
+func (q pgQueries) selectReview(ctx context.Context, userID, cardID uuid.UUID) (*entities.Review, error) {
+	return fromRow(entities.ReviewFromPG)(q.db.SelectReview(ctx, postgresql.SelectReviewParams{
+		UserID:      userID,
+		FlashcardID: cardID,
+	}))
+}
+
+func (q pgQueries) selectDueReviews(ctx context.Context, userID uuid.UUID, dueAt time.Time, limit int) ([]*entities.Review, error) {
+	return fromRows(func(row postgresql.SelectDueReviewsRow) *entities.Review {
+		review := entities.ReviewFromPG(postgresql.Review{
+			UserID:         row.UserID,
+			FlashcardID:    row.FlashcardID,
+			EaseFactor:     row.EaseFactor,
+			IntervalDays:   row.IntervalDays,
+			Repetitions:    row.Repetitions,
+			Lapses:         row.Lapses,
+			DueAt:          row.DueAt,
+			LastReviewedAt: row.LastReviewedAt,
+		})
+		review.Flashcard = &entities.Flashcard{
+			ID:            row.FlashcardID,
+			Word:          row.Word.String,
+			Meaning:       row.Meaning.String,
+			UsageExamples: row.Usage,
+		}
+		return review
+	})(q.db.SelectDueReviews(ctx, postgresql.SelectDueReviewsParams{
+		UserID: userID,
+		DueAt:  dueAt,
+		Limit:  int32(limit),
+	}))
+}
+
+func (q pgQueries) createSession(ctx context.Context, arg CreateSessionParams) error {
+	return q.db.CreateSession(ctx, postgresql.CreateSessionParams{
+		ID:          arg.ID,
+		UserID:      arg.UserID,
+		RefreshHash: arg.RefreshHash,
+		ExpiresAt:   arg.ExpiresAt,
+	})
+}
+
+func (q pgQueries) selectSession(ctx context.Context, id uuid.UUID) (*entities.Session, error) {
+	return fromRow(entities.SessionFromPG)(q.db.SelectSession(ctx, id))
+}
+
+func (q pgQueries) rotateSession(ctx context.Context, arg RotateSessionParams) (int64, error) {
+	return q.db.RotateSession(ctx, postgresql.RotateSessionParams{
+		RefreshHash:   arg.RefreshHash,
+		ExpiresAt:     arg.ExpiresAt,
+		ID:            arg.ID,
+		RefreshHash_2: arg.OldHash,
+	})
+}
+
+func (q pgQueries) revokeSession(ctx context.Context, id uuid.UUID) error {
+	return q.db.RevokeSession(ctx, id)
+}
+
+func (q pgQueries) revokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	return q.db.RevokeUserSessions(ctx, userID)
+}
@@ -0,0 +1,1036 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"languago/infrastructure/repository/database"
+	errors2 "languago/pkg/errors"
+	"languago/pkg/models/entities"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type (
+	// sqlQueries are the statements of one SQL dialect. They only convert the
+	// params and the rows of the generated queries, the rules shared by the
+	// databases are kept in sqlStorage. Errors are returned as the driver
+	// reports them, sqlStorage translates them with handleError.
+	sqlQueries interface {
+		// withTx returns the queries running in tx
+		withTx(tx *sql.Tx) sqlQueries
+
+		createUser(ctx context.Context, id uuid.UUID, login, password string) error
+		updateUserLogin(ctx context.Context, id uuid.UUID, login string) (int64, error)
+		updateUserPassword(ctx context.Context, id uuid.UUID, password string) (int64, error)
+		updateUserRole(ctx context.Context, id uuid.UUID, role string) (int64, error)
+		updateUserDisabled(ctx context.Context, id uuid.UUID, disabledAt sql.NullTime) (int64, error)
+		requirePasswordReset(ctx context.Context, id uuid.UUID, hash string) (int64, error)
+		deleteUser(ctx context.Context, id uuid.UUID) error
+		selectUsers(ctx context.Context, limit, offset int) ([]*entities.User, error)
+		selectUserByID(ctx context.Context, id uuid.UUID) (*entities.User, error)
+		selectUserByLogin(ctx context.Context, login string) (*entities.User, error)
+
+		createFlashcard(ctx context.Context, card *entities.Flashcard) error
+		// updateFlashcard writes the word, the meaning, the usage and the
+		// update time of card if the stored version is still version
+		updateFlashcard(ctx context.Context, card *entities.Flashcard, version int64) (int64, error)
+		deleteFlashcard(ctx context.Context, id, owner uuid.UUID, deletedAt time.Time) (int64, error)
+		selectFlashcard(ctx context.Context, id, owner uuid.UUID) (*entities.Flashcard, error)
+		selectFlashcardsByWord(ctx context.Context, deckID, owner uuid.UUID, word string) ([]*entities.Flashcard, error)
+		selectFlashcardsByMeaning(ctx context.Context, deckID, owner uuid.UUID, meaning string) ([]*entities.Flashcard, error)
+		// decodeUsage decodes the usage column of the squirrel queries
+		decodeUsage(raw []byte) ([]string, error)
+
+		createDeck(ctx context.Context, id, owner uuid.UUID, name string) error
+		// updateDeck renames the deck if the stored version is still version
+		updateDeck(ctx context.Context, id, owner uuid.UUID, name string, version int64) (int64, error)
+		deleteDeck(ctx context.Context, id, owner uuid.UUID, deletedAt time.Time) (int64, error)
+		selectDeck(ctx context.Context, id, owner uuid.UUID) (*entities.Deck, error)
+		selectDecks(ctx context.Context, owner uuid.UUID) ([]*entities.Deck, error)
+		selectDecksByName(ctx context.Context, owner uuid.UUID, name string) ([]*entities.Deck, error)
+		addToDeck(ctx context.Context, deckID, cardID uuid.UUID) error
+		deleteFromDeck(ctx context.Context, deckID, cardID uuid.UUID) (int64, error)
+		selectDeckFlashcards(ctx context.Context, deckID uuid.UUID) ([]*entities.Flashcard, error)
+
+		selectDeletedFlashcards(ctx context.Context, owner uuid.UUID) ([]*entities.Flashcard, error)
+		selectDeletedFlashcard(ctx context.Context, id, owner uuid.UUID) (*entities.Flashcard, error)
+		restoreFlashcard(ctx context.Context, id, owner uuid.UUID) (int64, error)
+		selectDeletedDecks(ctx context.Context, owner uuid.UUID) ([]*entities.Deck, error)
+		selectDeletedDeck(ctx context.Context, id, owner uuid.UUID) (*entities.Deck, error)
+		restoreDeck(ctx context.Context, id, owner uuid.UUID) (int64, error)
+		purgeFlashcards(ctx context.Context, before time.Time) (int64, error)
+		purgeDecks(ctx context.Context, before time.Time) (int64, error)
+
+		// createRevision stores the revision with its changes encoded by
+		// encodeChanges
+		createRevision(ctx context.Context, revision *entities.Revision, changes string) error
+		selectRevisions(ctx context.Context, entityType entities.EntityType, entityID, owner uuid.UUID) ([]*entities.Revision, error)
+		selectRevision(ctx context.Context, id uuid.UUID, entityType entities.EntityType, entityID, owner uuid.UUID) (*entities.Revision, error)
+
+		upsertReview(ctx context.Context, userID uuid.UUID, arg UpsertReviewParams) error
+		selectReview(ctx context.Context, userID, cardID uuid.UUID) (*entities.Review, error)
+		// selectDueReviews returns the reviews with the word, the meaning and
+		// the usage of their cards
+		selectDueReviews(ctx context.Context, userID uuid.UUID, dueAt time.Time, limit int) ([]*entities.Review, error)
+
+		createSession(ctx context.Context, arg CreateSessionParams) error
+		selectSession(ctx context.Context, id uuid.UUID) (*entities.Session, error)
+		rotateSession(ctx context.Context, arg RotateSessionParams) (int64, error)
+		revokeSession(ctx context.Context, id uuid.UUID) error
+		revokeUserSessions(ctx context.Context, userID uuid.UUID) error
+	}
+
+	// flashcardSearcher is implemented by the dialects which search the cards
+	// in the database, the cards of the others are matched by matchFlashcards.
+	flashcardSearcher interface {
+		searchFlashcards(ctx context.Context, owner uuid.UUID, query string, limit int) ([]*entities.FlashcardMatch, error)
+	}
+
+	// sqlStorage is the Storage of the SQL databases. It checks the owners and
+	// the versions, keeps the trash and records the history, the statements
+	// are run by the queries of the dialect.
+	sqlStorage struct {
+		conn *sql.DB
+		// tx is set when the storage is bound to a transaction, see atomic
+		tx *sql.Tx
+		q  sqlQueries
+		// dynamic are the queries built with squirrel, see database.Queries
+		dynamic *database.Queries
+	}
+)
+
+func newSQLStorage(conn *sql.DB, q sqlQueries, dynamic *database.Queries) *sqlStorage {
+	return &sqlStorage{
+		conn:    conn,
+		q:       q,
+		dynamic: dynamic,
+	}
+}
+
+func (s *sqlStorage) PingDB() error {
+	if err := s.conn.Ping(); err != nil {
+		return fmt.Errorf("error pinging database: %w", err)
+	}
+	return nil
+}
+
+func (s *sqlStorage) Close() error {
+	return s.conn.Close()
+}
+
+func (s *sqlStorage) CreateUser(ctx context.Context, arg CreateUserParams) error {
+	if arg.Login == "" || arg.Password == "" {
+		return fmt.Errorf("error invalid user credentials: %w", ErrInvalidData)
+	}
+
+	if err := s.q.createUser(ctx, arg.ID, arg.Login, arg.Password); err != nil {
+		return fmt.Errorf("error create user: %w", handleError(err))
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
+	if arg.ID == uuid.Nil {
+		return fmt.Errorf("error user id is required")
+	}
+
+	return s.atomic(ctx, func(s *sqlStorage) error {
+		if arg.Login != "" {
+			rows, err := s.q.updateUserLogin(ctx, arg.ID, arg.Login)
+			if err != nil {
+				return fmt.Errorf("error update user login: %w", handleError(err))
+			}
+
+			if rows == 0 {
+				return errors2.ErrNotFound
+			}
+		}
+
+		if arg.Password != "" {
+			rows, err := s.q.updateUserPassword(ctx, arg.ID, arg.Password)
+			if err != nil {
+				return fmt.Errorf("error update user password: %w", handleError(err))
+			}
+
+			if rows == 0 {
+				return errors2.ErrNotFound
+			}
+		}
+
+		if arg.Role != "" {
+			rows, err := s.q.updateUserRole(ctx, arg.ID, string(arg.Role))
+			if err != nil {
+				return fmt.Errorf("error update user role: %w", handleError(err))
+			}
+
+			if rows == 0 {
+				return errors2.ErrNotFound
+			}
+		}
+
+		if arg.Disabled != nil {
+			rows, err := s.q.updateUserDisabled(ctx, arg.ID, sql.NullTime{Time: time.Now(), Valid: *arg.Disabled})
+			if err != nil {
+				return fmt.Errorf("error update user disabled: %w", handleError(err))
+			}
+
+			if rows == 0 {
+				return errors2.ErrNotFound
+			}
+		}
+
+		if arg.PasswordResetHash != "" {
+			rows, err := s.q.requirePasswordReset(ctx, arg.ID, arg.PasswordResetHash)
+			if err != nil {
+				return fmt.Errorf("error require password reset: %w", handleError(err))
+			}
+
+			if rows == 0 {
+				return errors2.ErrNotFound
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *sqlStorage) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return fmt.Errorf("error user id is required")
+	}
+
+	if err := s.q.deleteUser(ctx, userID); err != nil {
+		return fmt.Errorf("error delete user: %w", handleError(err))
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) SelectUsers(ctx context.Context, arg SelectUsersParams) ([]*entities.User, error) {
+	users, err := s.q.selectUsers(ctx, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("error select users: %w", handleError(err))
+	}
+
+	return users, nil
+}
+
+func (s *sqlStorage) SelectUser(ctx context.Context, arg SelectUserParams) (*entities.User, error) {
+	var user *entities.User
+	var err error
+
+	switch {
+	case arg.ID != uuid.Nil:
+		user, err = s.q.selectUserByID(ctx, arg.ID)
+	case arg.Login != "":
+		user, err = s.q.selectUserByLogin(ctx, arg.Login)
+	default:
+		return nil, fmt.Errorf("error user id or login is required: %w", ErrInvalidData)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error select user: %w", handleError(err))
+	}
+
+	return user, nil
+}
+
+// CreateFlashcard creates a new flashcard owned by the caller.
+func (s *sqlStorage) CreateFlashcard(ctx context.Context, arg CreateFlashcardParams) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if arg.ID == uuid.Nil {
+		return fmt.Errorf("error flashcard id is required")
+	}
+
+	createdAt := flashcardTime()
+	card := &entities.Flashcard{
+		ID:            arg.ID,
+		Word:          arg.Word,
+		Meaning:       arg.Meaning,
+		UsageExamples: arg.Usage,
+		Tags:          arg.Tags,
+		Owner:         owner,
+		CreatedAt:     createdAt,
+		UpdatedAt:     createdAt,
+		Version:       1,
+	}
+
+	return s.atomic(ctx, func(s *sqlStorage) error {
+		if err := s.q.createFlashcard(ctx, card); err != nil {
+			return fmt.Errorf("error create flashcard: %w", handleError(err))
+		}
+
+		if len(arg.Tags) != 0 {
+			if err := s.dynamic.SetFlashcardTags(ctx, arg.ID, uniqueTags(arg.Tags)); err != nil {
+				return fmt.Errorf("error set flashcard tags: %w", handleError(err))
+			}
+		}
+
+		revision, err := flashcardRevision(ctx, entities.RevisionCreate, nil, card)
+		if err != nil {
+			return err
+		}
+
+		return s.createRevision(ctx, revision)
+	})
+}
+
+func (s *sqlStorage) UpdateFlashcard(ctx context.Context, arg UpdateFlashcardParams) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if arg.ID == uuid.Nil {
+		return fmt.Errorf("error id required")
+	}
+
+	return s.atomic(ctx, func(s *sqlStorage) error {
+		before, err := s.q.selectFlashcard(ctx, arg.ID, owner)
+		if err != nil {
+			return fmt.Errorf("error selecting flashcard: %w", handleError(err))
+		}
+
+		if arg.Version != 0 && arg.Version != before.Version {
+			return ErrVersionConflict
+		}
+
+		if !arg.changes() {
+			return nil
+		}
+
+		if err := attachTags(ctx, s.dynamic, []*entities.Flashcard{before}); err != nil {
+			return err
+		}
+
+		after := arg.apply(before)
+		after.UpdatedAt = flashcardTime()
+
+		affected, err := s.q.updateFlashcard(ctx, after, before.Version)
+		if err != nil {
+			return fmt.Errorf("error updating flashcard: %w", handleError(err))
+		}
+
+		// the card was changed since it was selected
+		if affected == 0 {
+			return ErrVersionConflict
+		}
+
+		if arg.Tags != nil {
+			if err := s.dynamic.SetFlashcardTags(ctx, arg.ID, uniqueTags(arg.Tags)); err != nil {
+				return fmt.Errorf("error set flashcard tags: %w", handleError(err))
+			}
+		}
+
+		revision, err := flashcardRevision(ctx, entities.RevisionUpdate, before, after)
+		if err != nil {
+			return err
+		}
+
+		return s.createRevision(ctx, revision)
+	})
+}
+
+func (s *sqlStorage) DeleteFlashcard(ctx context.Context, cardID uuid.UUID) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if cardID == uuid.Nil {
+		return fmt.Errorf("error flashcard uuid is required")
+	}
+
+	return s.atomic(ctx, func(s *sqlStorage) error {
+		cards, err := s.SelectFlashcard(ctx, SelectFlashcardParams{ID: cardID})
+		if err != nil {
+			return err
+		}
+
+		deletedAt := flashcardTime()
+		affected, err := s.q.deleteFlashcard(ctx, cardID, owner, deletedAt)
+		if err != nil {
+			return fmt.Errorf("error delete flashcard: %w", handleError(err))
+		}
+
+		if affected == 0 {
+			return errors2.ErrNotFound
+		}
+
+		deleted := *cards[0]
+		deleted.DeletedAt = &deletedAt
+		revision, err := flashcardRevision(ctx, entities.RevisionDelete, cards[0], &deleted)
+		if err != nil {
+			return err
+		}
+
+		return s.createRevision(ctx, revision)
+	})
+}
+
+// SelectFlashcard returns the caller's flashcards. A card is looked up by ID if it is set,
+// or by Word or Meaning within DeckID. Otherwise the cards are listed, filtered by DeckID,
+// Tag, Prefix and DueAt. Without any params all of the caller's cards are returned.
+func (s *sqlStorage) SelectFlashcard(ctx context.Context, arg SelectFlashcardParams) ([]*entities.Flashcard, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var cards []*entities.Flashcard
+
+	switch {
+	case arg.ID != uuid.Nil:
+		var card *entities.Flashcard
+		card, err = s.q.selectFlashcard(ctx, arg.ID, owner)
+		cards = append(cards, card)
+	case arg.DeckID != uuid.Nil && arg.Word != "":
+		cards, err = s.q.selectFlashcardsByWord(ctx, arg.DeckID, owner, arg.Word)
+	case arg.DeckID != uuid.Nil && arg.Meaning != "":
+		cards, err = s.q.selectFlashcardsByMeaning(ctx, arg.DeckID, owner, arg.Meaning)
+	default:
+		return s.listFlashcards(ctx, owner, arg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error select flashcard: %w", handleError(err))
+	}
+
+	if err := attachTags(ctx, s.dynamic, cards); err != nil {
+		return nil, err
+	}
+
+	return cards, nil
+}
+
+// listFlashcards returns the caller's cards filtered, sorted and paged as
+// requested, see database.Queries.ListFlashcards.
+func (s *sqlStorage) listFlashcards(ctx context.Context, owner uuid.UUID, arg SelectFlashcardParams) ([]*entities.Flashcard, error) {
+	params, err := listFlashcardsParams(owner, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	// listing a deck of another user is a not found, not an empty deck
+	if arg.DeckID != uuid.Nil {
+		if _, err := s.SelectDeck(ctx, SelectDeckParams{ID: arg.DeckID}); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := s.dynamic.ListFlashcards(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("error list flashcards: %w", handleError(err))
+	}
+
+	resp := make([]*entities.Flashcard, 0, len(rows))
+	for _, row := range rows {
+		usage, err := s.q.decodeUsage(row.Usage)
+		if err != nil {
+			return nil, fmt.Errorf("error decode usage: %w", err)
+		}
+
+		resp = append(resp, flashcardFromRow(row, usage))
+	}
+
+	if err := attachTags(ctx, s.dynamic, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// SearchFlashcards searches the cards in the database if the dialect can,
+// otherwise all of the caller's cards are matched, see matchFlashcards.
+func (s *sqlStorage) SearchFlashcards(ctx context.Context, arg SearchFlashcardsParams) ([]*entities.FlashcardMatch, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	terms, err := searchTerms(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	searcher, ok := s.q.(flashcardSearcher)
+	if !ok {
+		cards, err := s.listFlashcards(ctx, owner, SelectFlashcardParams{})
+		if err != nil {
+			return nil, err
+		}
+
+		return matchFlashcards(terms, cards, arg.Limit), nil
+	}
+
+	matches, err := searcher.searchFlashcards(ctx, owner, arg.Query, arg.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("error search flashcards: %w", handleError(err))
+	}
+
+	cards := make([]*entities.Flashcard, 0, len(matches))
+	for _, match := range matches {
+		cards = append(cards, match.Flashcard)
+	}
+
+	if err := attachTags(ctx, s.dynamic, cards); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+func (s *sqlStorage) CreateDeck(ctx context.Context, arg CreateDeckParams) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if arg.ID == uuid.Nil {
+		return fmt.Errorf("error deck uuid is required")
+	}
+
+	return s.atomic(ctx, func(s *sqlStorage) error {
+		if err := s.q.createDeck(ctx, arg.ID, owner, arg.Name); err != nil {
+			return fmt.Errorf("error create deck: %w", handleError(err))
+		}
+
+		revision, err := deckRevision(ctx, entities.RevisionCreate, nil, &entities.Deck{
+			Id:      arg.ID,
+			Name:    arg.Name,
+			Owner:   owner,
+			Version: 1,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.createRevision(ctx, revision)
+	})
+}
+
+func (s *sqlStorage) UpdateDeck(ctx context.Context, arg UpdateDeckParams) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if arg.ID == uuid.Nil {
+		return fmt.Errorf("error deck uuid is required")
+	}
+
+	return s.atomic(ctx, func(s *sqlStorage) error {
+		before, err := s.q.selectDeck(ctx, arg.ID, owner)
+		if err != nil {
+			return fmt.Errorf("error select deck: %w", handleError(err))
+		}
+
+		if arg.Version != 0 && arg.Version != before.Version {
+			return ErrVersionConflict
+		}
+
+		affected, err := s.q.updateDeck(ctx, arg.ID, owner, arg.Name, before.Version)
+		if err != nil {
+			return fmt.Errorf("error update deck: %w", handleError(err))
+		}
+
+		// the deck was changed since it was selected
+		if affected == 0 {
+			return ErrVersionConflict
+		}
+
+		after := *before
+		after.Name = arg.Name
+		after.Version++
+		revision, err := deckRevision(ctx, entities.RevisionUpdate, before, &after)
+		if err != nil {
+			return err
+		}
+
+		return s.createRevision(ctx, revision)
+	})
+}
+
+func (s *sqlStorage) DeleteDeck(ctx context.Context, arg DeleteDeckParams) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if arg.ID == uuid.Nil {
+		return fmt.Errorf("error deck uuid is required")
+	}
+
+	return s.atomic(ctx, func(s *sqlStorage) error {
+		before, err := s.q.selectDeck(ctx, arg.ID, owner)
+		if err != nil {
+			return fmt.Errorf("error select deck: %w", handleError(err))
+		}
+
+		deletedAt := flashcardTime()
+		affected, err := s.q.deleteDeck(ctx, arg.ID, owner, deletedAt)
+		if err != nil {
+			return fmt.Errorf("error delete deck: %w", handleError(err))
+		}
+
+		if affected == 0 {
+			return errors2.ErrNotFound
+		}
+
+		after := *before
+		after.DeletedAt = &deletedAt
+		revision, err := deckRevision(ctx, entities.RevisionDelete, before, &after)
+		if err != nil {
+			return err
+		}
+
+		return s.createRevision(ctx, revision)
+	})
+}
+
+// SelectDeck returns a single deck of the caller, found by id or, if id is not set, by name.
+func (s *sqlStorage) SelectDeck(ctx context.Context, arg SelectDeckParams) (*entities.Deck, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if arg.ID == uuid.Nil {
+		decks, err := s.SelectDecks(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(decks) == 0 {
+			return nil, errors2.ErrNotFound
+		}
+
+		return decks[0], nil
+	}
+
+	deck, err := s.q.selectDeck(ctx, arg.ID, owner)
+	if err != nil {
+		return nil, fmt.Errorf("error select deck: %w", handleError(err))
+	}
+
+	return deck, nil
+}
+
+// SelectDecks returns all decks of the caller. If name is set, only decks with this name are returned.
+func (s *sqlStorage) SelectDecks(ctx context.Context, arg SelectDeckParams) ([]*entities.Deck, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var decks []*entities.Deck
+	if arg.Name != "" {
+		decks, err = s.q.selectDecksByName(ctx, owner, arg.Name)
+	} else {
+		decks, err = s.q.selectDecks(ctx, owner)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error select decks: %w", handleError(err))
+	}
+
+	return decks, nil
+}
+
+func (s *sqlStorage) AddToDeck(ctx context.Context, arg AddToDeckParams) error {
+	if arg.DeckID == uuid.Nil || arg.FlashcardID == uuid.Nil {
+		return fmt.Errorf("error deck id and flashcard id are required")
+	}
+
+	deck, err := s.SelectDeck(ctx, SelectDeckParams{ID: arg.DeckID})
+	if err != nil {
+		return err
+	}
+
+	// cards can be added only to decks of the same owner
+	if _, err := s.q.selectFlashcard(ctx, arg.FlashcardID, deck.Owner); err != nil {
+		return fmt.Errorf("error select flashcard: %w", handleError(err))
+	}
+
+	if err := s.q.addToDeck(ctx, arg.DeckID, arg.FlashcardID); err != nil {
+		return fmt.Errorf("error add flashcard to deck: %w", handleError(err))
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) DeleteFromDeck(ctx context.Context, arg DeleteFromDeckParams) error {
+	if arg.DeckID == uuid.Nil || arg.FlashcardID == uuid.Nil {
+		return fmt.Errorf("error deck id and flashcard id are required")
+	}
+
+	_, err := s.SelectDeck(ctx, SelectDeckParams{ID: arg.DeckID})
+	if err != nil {
+		return err
+	}
+
+	affected, err := s.q.deleteFromDeck(ctx, arg.DeckID, arg.FlashcardID)
+	if err != nil {
+		return fmt.Errorf("error delete flashcard from deck: %w", handleError(err))
+	}
+
+	if affected == 0 {
+		return errors2.ErrNotFound
+	}
+
+	return nil
+}
+
+// SelectFromDeck returns flashcards of the deck. Optional CardID, Word and WordMeaning
+// narrow the result down to matching cards.
+func (s *sqlStorage) SelectFromDeck(ctx context.Context, arg SelectFromDeckParams) ([]*entities.Flashcard, error) {
+	if arg.DeckID == uuid.Nil {
+		return nil, fmt.Errorf("error deck id is required")
+	}
+
+	_, err := s.SelectDeck(ctx, SelectDeckParams{ID: arg.DeckID})
+	if err != nil {
+		return nil, err
+	}
+
+	cards, err := s.q.selectDeckFlashcards(ctx, arg.DeckID)
+	if err != nil {
+		return nil, fmt.Errorf("error select deck flashcards: %w", handleError(err))
+	}
+
+	resp := make([]*entities.Flashcard, 0, len(cards))
+	for _, card := range cards {
+		switch {
+		case arg.CardID != uuid.Nil && card.ID != arg.CardID,
+			arg.Word != "" && card.Word != arg.Word,
+			arg.WordMeaning != "" && card.Meaning != arg.WordMeaning:
+			continue
+		}
+
+		resp = append(resp, card)
+	}
+
+	if err := attachTags(ctx, s.dynamic, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// SelectDeletedFlashcards returns the caller's cards in the trash, the most
+// recently deleted first.
+func (s *sqlStorage) SelectDeletedFlashcards(ctx context.Context) ([]*entities.Flashcard, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cards, err := s.q.selectDeletedFlashcards(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("error select deleted flashcards: %w", handleError(err))
+	}
+
+	if err := attachTags(ctx, s.dynamic, cards); err != nil {
+		return nil, err
+	}
+
+	return cards, nil
+}
+
+// SelectDeletedDecks returns the caller's decks in the trash, the most recently
+// deleted first.
+func (s *sqlStorage) SelectDeletedDecks(ctx context.Context) ([]*entities.Deck, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	decks, err := s.q.selectDeletedDecks(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("error select deleted decks: %w", handleError(err))
+	}
+
+	return decks, nil
+}
+
+func (s *sqlStorage) RestoreFlashcard(ctx context.Context, cardID uuid.UUID) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if cardID == uuid.Nil {
+		return fmt.Errorf("error flashcard uuid is required")
+	}
+
+	return s.atomic(ctx, func(s *sqlStorage) error {
+		deleted, err := s.q.selectDeletedFlashcard(ctx, cardID, owner)
+		if err != nil {
+			return fmt.Errorf("error select deleted flashcard: %w", handleError(err))
+		}
+
+		affected, err := s.q.restoreFlashcard(ctx, cardID, owner)
+		if err != nil {
+			return fmt.Errorf("error restore flashcard: %w", handleError(err))
+		}
+
+		if affected == 0 {
+			return errors2.ErrNotFound
+		}
+
+		if err := attachTags(ctx, s.dynamic, []*entities.Flashcard{deleted}); err != nil {
+			return err
+		}
+
+		restored := *deleted
+		restored.DeletedAt = nil
+		revision, err := flashcardRevision(ctx, entities.RevisionRestore, deleted, &restored)
+		if err != nil {
+			return err
+		}
+
+		return s.createRevision(ctx, revision)
+	})
+}
+
+func (s *sqlStorage) RestoreDeck(ctx context.Context, arg RestoreDeckParams) error {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if arg.ID == uuid.Nil {
+		return fmt.Errorf("error deck uuid is required")
+	}
+
+	return s.atomic(ctx, func(s *sqlStorage) error {
+		before, err := s.q.selectDeletedDeck(ctx, arg.ID, owner)
+		if err != nil {
+			return fmt.Errorf("error select deleted deck: %w", handleError(err))
+		}
+
+		affected, err := s.q.restoreDeck(ctx, arg.ID, owner)
+		if err != nil {
+			return fmt.Errorf("error restore deck: %w", handleError(err))
+		}
+
+		if affected == 0 {
+			return errors2.ErrNotFound
+		}
+
+		after := *before
+		after.DeletedAt = nil
+		revision, err := deckRevision(ctx, entities.RevisionRestore, before, &after)
+		if err != nil {
+			return err
+		}
+
+		return s.createRevision(ctx, revision)
+	})
+}
+
+// PurgeDeleted removes the cards and decks deleted before the given time for
+// good, their reviews, tags and deck entries go with them.
+func (s *sqlStorage) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+
+	err := s.atomic(ctx, func(s *sqlStorage) error {
+		cards, err := s.q.purgeFlashcards(ctx, before.UTC())
+		if err != nil {
+			return fmt.Errorf("error purge flashcards: %w", handleError(err))
+		}
+
+		decks, err := s.q.purgeDecks(ctx, before.UTC())
+		if err != nil {
+			return fmt.Errorf("error purge decks: %w", handleError(err))
+		}
+
+		purged = cards + decks
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// createRevision appends the revision to the history, an update which changes
+// none of the fields is left out.
+func (s *sqlStorage) createRevision(ctx context.Context, revision *entities.Revision) error {
+	if len(revision.Changes) == 0 {
+		return nil
+	}
+
+	changes, err := encodeChanges(revision)
+	if err != nil {
+		return err
+	}
+
+	if err := s.q.createRevision(ctx, revision, changes); err != nil {
+		return fmt.Errorf("error create revision: %w", handleError(err))
+	}
+
+	return nil
+}
+
+// SelectRevisions returns the history of the caller's card or deck, the oldest
+// revision first.
+func (s *sqlStorage) SelectRevisions(ctx context.Context, arg SelectRevisionsParams) ([]*entities.Revision, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.q.selectRevisions(ctx, arg.EntityType, arg.EntityID, owner)
+	if err != nil {
+		return nil, fmt.Errorf("error select revisions: %w", handleError(err))
+	}
+
+	return revisions, nil
+}
+
+func (s *sqlStorage) SelectRevision(ctx context.Context, arg SelectRevisionsParams) (*entities.Revision, error) {
+	owner, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if arg.ID == uuid.Nil {
+		return nil, fmt.Errorf("error revision id is required")
+	}
+
+	revision, err := s.q.selectRevision(ctx, arg.ID, arg.EntityType, arg.EntityID, owner)
+	if err != nil {
+		return nil, fmt.Errorf("error select revision: %w", handleError(err))
+	}
+
+	return revision, nil
+}
+
+func (s *sqlStorage) UpsertReview(ctx context.Context, arg UpsertReviewParams) error {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if arg.FlashcardID == uuid.Nil {
+		return fmt.Errorf("error flashcard uuid is required")
+	}
+
+	if err := s.q.upsertReview(ctx, userID, arg); err != nil {
+		return fmt.Errorf("error upsert review: %w", handleError(err))
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) SelectReview(ctx context.Context, arg SelectReviewParams) (*entities.Review, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	review, err := s.q.selectReview(ctx, userID, arg.FlashcardID)
+	if err != nil {
+		return nil, fmt.Errorf("error select review: %w", handleError(err))
+	}
+
+	return review, nil
+}
+
+func (s *sqlStorage) SelectDueReviews(ctx context.Context, arg SelectDueReviewsParams) ([]*entities.Review, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := s.q.selectDueReviews(ctx, userID, arg.DueAt, arg.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("error select due reviews: %w", handleError(err))
+	}
+
+	return reviews, nil
+}
+
+func (s *sqlStorage) CreateSession(ctx context.Context, arg CreateSessionParams) error {
+	if arg.ID == uuid.Nil || arg.UserID == uuid.Nil || arg.RefreshHash == "" {
+		return fmt.Errorf("error invalid session: %w", ErrInvalidData)
+	}
+
+	if err := s.q.createSession(ctx, arg); err != nil {
+		return fmt.Errorf("error create session: %w", handleError(err))
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) SelectSession(ctx context.Context, sessionID uuid.UUID) (*entities.Session, error) {
+	session, err := s.q.selectSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("error select session: %w", handleError(err))
+	}
+
+	return session, nil
+}
+
+func (s *sqlStorage) RotateSession(ctx context.Context, arg RotateSessionParams) error {
+	rows, err := s.q.rotateSession(ctx, arg)
+	if err != nil {
+		return fmt.Errorf("error rotate session: %w", handleError(err))
+	}
+
+	if rows == 0 {
+		return errors2.ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	if err := s.q.revokeSession(ctx, sessionID); err != nil {
+		return fmt.Errorf("error revoke session: %w", handleError(err))
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	if err := s.q.revokeUserSessions(ctx, userID); err != nil {
+		return fmt.Errorf("error revoke user sessions: %w", handleError(err))
+	}
+
+	return nil
+}
+
+// fromRow converts the row of a generated query with fn unless the query
+// failed, fromRow(fn)(q.Select(...)) is the usual way to call it.
+func fromRow[R, E any](fn func(R) *E) func(R, error) (*E, error) {
+	return func(row R, err error) (*E, error) {
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(row), nil
+	}
+}
+
+// fromRows converts the rows of a generated query like fromRow.
+func fromRows[R, E any](fn func(R) *E) func([]R, error) ([]*E, error) {
+	return func(rows []R, err error) ([]*E, error) {
+		if err != nil {
+			return nil, err
+		}
+
+		resp := make([]*E, 0, len(rows))
+		for _, row := range rows {
+			resp = append(resp, fn(row))
+		}
+
+		return resp, nil
+	}
+}
@@ -0,0 +1,356 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"languago/infrastructure/repository/database"
+	"languago/infrastructure/repository/sqlite"
+	"languago/pkg/models/entities"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+// sqliteQueries are the statements of SQLite, usage examples are stored as JSON
+// text and there is no full text search, see matchFlashcards. Times are stored
+// as text, they are written in UTC so that comparing them compares the
+// instants.
+type sqliteQueries struct {
+	db *sqlite.Queries
+}
+
+// Storage implementation for SQLite database
+func newSQLiteStorage(db *sql.DB) *sqlStorage {
+	return newSQLStorage(db, sqliteQueries{db: sqlite.New(db)}, database.New(db, sq.Question))
+}
+
+func (q sqliteQueries) withTx(tx *sql.Tx) sqlQueries {
+	return sqliteQueries{db: q.db.WithTx(tx)}
+}
+
+func (q sqliteQueries) createUser(ctx context.Context, id uuid.UUID, login, password string) error {
+	return q.db.CreateUser(ctx, sqlite.CreateUserParams{
+		ID:       id,
+		Login:    sql.NullString{String: login, Valid: true},
+		Password: sql.NullString{String: password, Valid: true},
+	})
+}
+
+func (q sqliteQueries) updateUserLogin(ctx context.Context, id uuid.UUID, login string) (int64, error) {
+	return q.db.UpdateUserLogin(ctx, sqlite.UpdateUserLoginParams{
+		ID:    id,
+		Login: sql.NullString{String: login, Valid: true},
+	})
+}
+
+func (q sqliteQueries) updateUserPassword(ctx context.Context, id uuid.UUID, password string) (int64, error) {
+	return q.db.UpdateUserPassword(ctx, sqlite.UpdateUserPasswordParams{
+		ID:       id,
+		Password: sql.NullString{String: password, Valid: true},
+	})
+}
+
+func (q sqliteQueries) updateUserRole(ctx context.Context, id uuid.UUID, role string) (int64, error) {
+	return q.db.UpdateUserRole(ctx, sqlite.UpdateUserRoleParams{ID: id, Role: role})
+}
+
+func (q sqliteQueries) updateUserDisabled(ctx context.Context, id uuid.UUID, disabledAt sql.NullTime) (int64, error) {
+	return q.db.UpdateUserDisabled(ctx, sqlite.UpdateUserDisabledParams{
+		ID:         id,
+		DisabledAt: sql.NullTime{Time: disabledAt.Time.UTC(), Valid: disabledAt.Valid},
+	})
+}
+
+func (q sqliteQueries) requirePasswordReset(ctx context.Context, id uuid.UUID, hash string) (int64, error) {
+	return q.db.RequirePasswordReset(ctx, sqlite.RequirePasswordResetParams{ID: id, PasswordResetHash: hash})
+}
+
+func (q sqliteQueries) deleteUser(ctx context.Context, id uuid.UUID) error {
+	return q.db.DeleteUser(ctx, id)
+}
+
+func (q sqliteQueries) selectUsers(ctx context.Context, limit, offset int) ([]*entities.User, error) {
+	return fromRows(entities.UserFromSQLite)(q.db.SelectUsers(ctx, sqlite.SelectUsersParams{
+		Limit:  int64(limit),
+		Offset: int64(offset),
+	}))
+}
+
+func (q sqliteQueries) selectUserByID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
+	return fromRow(entities.UserFromSQLite)(q.db.SelectUserByID(ctx, id))
+}
+
+func (q sqliteQueries) selectUserByLogin(ctx context.Context, login string) (*entities.User, error) {
+	return fromRow(entities.UserFromSQLite)(q.db.SelectUserByLogin(ctx, sql.NullString{String: login, Valid: true}))
+}
+
+func (q sqliteQueries) createFlashcard(ctx context.Context, card *entities.Flashcard) error {
+	usage, err := jsonUsage(card.UsageExamples)
+	if err != nil {
+		return fmt.Errorf("error encode usage: %w", err)
+	}
+
+	return q.db.CreateFlashcard(ctx, sqlite.CreateFlashcardParams{
+		ID:        card.ID,
+		Word:      sql.NullString{String: card.Word, Valid: true},
+		Meaning:   sql.NullString{String: card.Meaning, Valid: true},
+		Usage:     usage,
+		Owner:     card.Owner,
+		CreatedAt: card.CreatedAt,
+		UpdatedAt: card.UpdatedAt,
+	})
+}
+
+func (q sqliteQueries) updateFlashcard(ctx context.Context, card *entities.Flashcard, version int64) (int64, error) {
+	usage, err := jsonUsage(card.UsageExamples)
+	if err != nil {
+		return 0, fmt.Errorf("error encode usage: %w", err)
+	}
+
+	return q.db.UpdateFlashcard(ctx, sqlite.UpdateFlashcardParams{
+		Word:      sql.NullString{String: card.Word, Valid: true},
+		Meaning:   sql.NullString{String: card.Meaning, Valid: true},
+		Usage:     usage,
+		UpdatedAt: card.UpdatedAt,
+		ID:        card.ID,
+		Owner:     card.Owner,
+		Version:   version,
+	})
+}
+
+func (q sqliteQueries) deleteFlashcard(ctx context.Context, id, owner uuid.UUID, deletedAt time.Time) (int64, error) {
+	return q.db.DeleteFlashcard(ctx, sqlite.DeleteFlashcardParams{
+		ID:        id,
+		Owner:     owner,
+		DeletedAt: sql.NullTime{Time: deletedAt, Valid: true},
+	})
+}
+
+func (q sqliteQueries) selectFlashcard(ctx context.Context, id, owner uuid.UUID) (*entities.Flashcard, error) {
+	return fromRow(entities.FlashcardFromSQLite)(q.db.SelectFlashcardByID(ctx, sqlite.SelectFlashcardByIDParams{
+		ID:    id,
+		Owner: owner,
+	}))
+}
+
+func (q sqliteQueries) selectFlashcardsByWord(ctx context.Context, deckID, owner uuid.UUID, word string) ([]*entities.Flashcard, error) {
+	return fromRows(entities.FlashcardFromSQLite)(q.db.SelectFlashcardByWord(ctx, sqlite.SelectFlashcardByWordParams{
+		DeckID: deckID,
+		Owner:  owner,
+		Word:   sql.NullString{String: word, Valid: true},
+	}))
+}
+
+func (q sqliteQueries) selectFlashcardsByMeaning(ctx context.Context, deckID, owner uuid.UUID, meaning string) ([]*entities.Flashcard, error) {
+	return fromRows(entities.FlashcardFromSQLite)(q.db.SelectFlashcardByMeaning(ctx, sqlite.SelectFlashcardByMeaningParams{
+		DeckID:  deckID,
+		Owner:   owner,
+		Meaning: sql.NullString{String: meaning, Valid: true},
+	}))
+}
+
+func (q sqliteQueries) decodeUsage(raw []byte) ([]string, error) {
+	return entities.UsageFromJSON(raw), nil
+}
+
+func (q sqliteQueries) createDeck(ctx context.Context, id, owner uuid.UUID, name string) error {
+	return q.db.CreateDeck(ctx, sqlite.CreateDeckParams{
+		ID:    id,
+		Name:  sql.NullString{String: name, Valid: true},
+		Owner: owner,
+	})
+}
+
+func (q sqliteQueries) updateDeck(ctx context.Context, id, owner uuid.UUID, name string, version int64) (int64, error) {
+	return q.db.EditDeckProps(ctx, sqlite.EditDeckPropsParams{
+		ID:      id,
+		Name:    sql.NullString{String: name, Valid: true},
+		Owner:   owner,
+		Version: version,
+	})
+}
+
+func (q sqliteQueries) deleteDeck(ctx context.Context, id, owner uuid.UUID, deletedAt time.Time) (int64, error) {
+	return q.db.DeleteDeck(ctx, sqlite.DeleteDeckParams{
+		ID:        id,
+		Owner:     owner,
+		DeletedAt: sql.NullTime{Time: deletedAt, Valid: true},
+	})
+}
+
+func (q sqliteQueries) selectDeck(ctx context.Context, id, owner uuid.UUID) (*entities.Deck, error) {
+	return fromRow(entities.DeckFromSQLite)(q.db.SelectDeck(ctx, sqlite.SelectDeckParams{ID: id, Owner: owner}))
+}
+
+func (q sqliteQueries) selectDecks(ctx context.Context, owner uuid.UUID) ([]*entities.Deck, error) {
+	return fromRows(entities.DeckFromSQLite)(q.db.SelectOwnerDecks(ctx, owner))
+}
+
+func (q sqliteQueries) selectDecksByName(ctx context.Context, owner uuid.UUID, name string) ([]*entities.Deck, error) {
+	return fromRows(entities.DeckFromSQLite)(q.db.SelectDecksByName(ctx, sqlite.SelectDecksByNameParams{
+		Owner: owner,
+		Name:  sql.NullString{String: name, Valid: true},
+	}))
+}
+
+func (q sqliteQueries) addToDeck(ctx context.Context, deckID, cardID uuid.UUID) error {
+	return q.db.AddToDeck(ctx, sqlite.AddToDeckParams{DeckID: deckID, FlashcardID: cardID})
+}
+
+func (q sqliteQueries) deleteFromDeck(ctx context.Context, deckID, cardID uuid.UUID) (int64, error) {
+	return q.db.DeleteFromDeck(ctx, sqlite.DeleteFromDeckParams{DeckID: deckID, FlashcardID: cardID})
+}
+
+func (q sqliteQueries) selectDeckFlashcards(ctx context.Context, deckID uuid.UUID) ([]*entities.Flashcard, error) {
+	return fromRows(entities.FlashcardFromSQLite)(q.db.SelectDeckFlashcards(ctx, deckID))
+}
+
+func (q sqliteQueries) selectDeletedFlashcards(ctx context.Context, owner uuid.UUID) ([]*entities.Flashcard, error) {
+	return fromRows(entities.FlashcardFromSQLite)(q.db.SelectDeletedFlashcards(ctx, owner))
+}
+
+func (q sqliteQueries) selectDeletedFlashcard(ctx context.Context, id, owner uuid.UUID) (*entities.Flashcard, error) {
+	return fromRow(entities.FlashcardFromSQLite)(q.db.SelectDeletedFlashcard(ctx, sqlite.SelectDeletedFlashcardParams{
+		ID:    id,
+		Owner: owner,
+	}))
+}
+
+func (q sqliteQueries) restoreFlashcard(ctx context.Context, id, owner uuid.UUID) (int64, error) {
+	return q.db.RestoreFlashcard(ctx, sqlite.RestoreFlashcardParams{ID: id, Owner: owner})
+}
+
+func (q sqliteQueries) selectDeletedDecks(ctx context.Context, owner uuid.UUID) ([]*entities.Deck, error) {
+	return fromRows(entities.DeckFromSQLite)(q.db.SelectDeletedDecks(ctx, owner))
+}
+
+func (q sqliteQueries) selectDeletedDeck(ctx context.Context, id, owner uuid.UUID) (*entities.Deck, error) {
+	return fromRow(entities.DeckFromSQLite)(q.db.SelectDeletedDeck(ctx, sqlite.SelectDeletedDeckParams{ID: id, Owner: owner}))
+}
+
+func (q sqliteQueries) restoreDeck(ctx context.Context, id, owner uuid.UUID) (int64, error) {
+	return q.db.RestoreDeck(ctx, sqlite.RestoreDeckParams{ID: id, Owner: owner})
+}
+
+func (q sqliteQueries) purgeFlashcards(ctx context.Context, before time.Time) (int64, error) {
+	return q.db.PurgeFlashcards(ctx, sql.NullTime{Time: before, Valid: true})
+}
+
+func (q sqliteQueries) purgeDecks(ctx context.Context, before time.Time) (int64, error) {
+	return q.db.PurgeDecks(ctx, sql.NullTime{Time: before, Valid: true})
+}
+
+func (q sqliteQueries) createRevision(ctx context.Context, revision *entities.Revision, changes string) error {
+	return q.db.CreateRevision(ctx, sqlite.CreateRevisionParams{
+		ID:         revision.ID,
+		EntityType: string(revision.EntityType),
+		EntityID:   revision.EntityID,
+		Owner:      revision.Owner,
+		Version:    revision.Version,
+		Action:     string(revision.Action),
+		ActorID:    revision.ActorID,
+		RequestID:  revision.RequestID,
+		Changes:    changes,
+		Snapshot:   string(revision.Snapshot),
+		CreatedAt:  revision.CreatedAt,
+	})
+}
+
+func (q sqliteQueries) selectRevisions(ctx context.Context, entityType entities.EntityType, entityID, owner uuid.UUID) ([]*entities.Revision, error) {
+	return fromRows(entities.RevisionFromSQLite)(q.db.SelectRevisions(ctx, sqlite.SelectRevisionsParams{
+		EntityType: string(entityType),
+		EntityID:   entityID,
+		Owner:      owner,
+	}))
+}
+
+func (q sqliteQueries) selectRevision(ctx context.Context, id uuid.UUID, entityType entities.EntityType, entityID, owner uuid.UUID) (*entities.Revision, error) {
+	return fromRow(entities.RevisionFromSQLite)(q.db.SelectRevision(ctx, sqlite.SelectRevisionParams{
+		ID:         id,
+		EntityType: string(entityType),
+		EntityID:   entityID,
+		Owner:      owner,
+	}))
+}
+
+func (q sqliteQueries) upsertReview(ctx context.Context, userID uuid.UUID, arg UpsertReviewParams) error {
+	params := sqlite.UpsertReviewParams{
+		UserID:       userID,
+		FlashcardID:  arg.FlashcardID,
+		EaseFactor:   arg.EaseFactor,
+		IntervalDays: int64(arg.Interval),
+		Repetitions:  int64(arg.Repetitions),
+		Lapses:       int64(arg.Lapses),
+		DueAt:        arg.DueAt.UTC(),
+	}
+	if arg.LastReviewedAt != nil {
+		params.LastReviewedAt = sql.NullTime{Time: arg.LastReviewedAt.UTC(), Valid: true}
+	}
+
+	return q.db.UpsertReview(ctx, params)
+}
+
+func (q sqliteQueries) selectReview(ctx context.Context, userID, cardID uuid.UUID) (*entities.Review, error) {
+	return fromRow(entities.ReviewFromSQLite)(q.db.SelectReview(ctx, sqlite.SelectReviewParams{
+		UserID:      userID,
+		FlashcardID: cardID,
+	}))
+}
+
+func (q sqliteQueries) selectDueReviews(ctx context.Context, userID uuid.UUID, dueAt time.Time, limit int) ([]*entities.Review, error) {
+	return fromRows(func(row sqlite.SelectDueReviewsRow) *entities.Review {
+		review := entities.ReviewFromSQLite(sqlite.Review{
+			UserID:         row.UserID,
+			FlashcardID:    row.FlashcardID,
+			EaseFactor:     row.EaseFactor,
+			IntervalDays:   row.IntervalDays,
+			Repetitions:    row.Repetitions,
+			Lapses:         row.Lapses,
+			DueAt:          row.DueAt,
+			LastReviewedAt: row.LastReviewedAt,
+		})
+		review.Flashcard = &entities.Flashcard{
+			ID:            row.FlashcardID,
+			Word:          row.Word.String,
+			Meaning:       row.Meaning.String,
+			UsageExamples: entities.UsageFromJSON([]byte(row.Usage.String)),
+		}
+		return review
+	})(q.db.SelectDueReviews(ctx, sqlite.SelectDueReviewsParams{
+		UserID: userID,
+		DueAt:  dueAt.UTC(),
+		Limit:  int64(limit),
+	}))
+}
+
+func (q sqliteQueries) createSession(ctx context.Context, arg CreateSessionParams) error {
+	return q.db.CreateSession(ctx, sqlite.CreateSessionParams{
+		ID:          arg.ID,
+		UserID:      arg.UserID,
+		RefreshHash: arg.RefreshHash,
+		ExpiresAt:   arg.ExpiresAt.UTC(),
+	})
+}
+
+func (q sqliteQueries) selectSession(ctx context.Context, id uuid.UUID) (*entities.Session, error) {
+	return fromRow(entities.SessionFromSQLite)(q.db.SelectSession(ctx, id))
+}
+
+func (q sqliteQueries) rotateSession(ctx context.Context, arg RotateSessionParams) (int64, error) {
+	return q.db.RotateSession(ctx, sqlite.RotateSessionParams{
+		RefreshHash:   arg.RefreshHash,
+		ExpiresAt:     arg.ExpiresAt.UTC(),
+		ID:            arg.ID,
+		RefreshHash_2: arg.OldHash,
+	})
+}
+
+func (q sqliteQueries) revokeSession(ctx context.Context, id uuid.UUID) error {
+	return q.db.RevokeSession(ctx, id)
+}
+
+func (q sqliteQueries) revokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	return q.db.RevokeUserSessions(ctx, userID)
+}
@@ -2,18 +2,47 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	errors2 "languago/pkg/errors"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
 var (
 	ErrChannelAlreadyOpen = errors2.New(500, "error channel alreay open", errors2.ErrInternalServerError)
 	ErrChannelNotOpen     = errors2.New(500, "error channel not open", errors2.ErrInternalServerError)
 	ErrInvalidData        = errors2.New(404, "error invalid data", errors2.ErrValidation)
+	ErrUnknownMigration   = errors2.New(500, "error database has a migration unknown to this build", errors2.ErrInternalServerError)
+	ErrMigrationChecksum  = errors2.New(500, "error applied migration was changed", errors2.ErrInternalServerError)
+	ErrVersionConflict    = errors2.New(412, "error entity was changed since the given version", errors2.ErrPreconditionFailed)
 )
 
+const (
+	pqForeignKeyViolation pq.ErrorCode = "23503"
+	pqUniqueViolation     pq.ErrorCode = "23505"
+
+	mysqlDuplicateEntry  uint16 = 1062
+	mysqlNoReferencedRow uint16 = 1452
+)
+
+func sqliteCode(err error) int {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code()
+	}
+
+	return 0
+}
+
 func handleError(err error) error {
+	var (
+		pqErr    *pq.Error
+		mysqlErr *mysql.MySQLError
+	)
+
 	switch {
 	case err == nil:
 		return nil
@@ -28,6 +57,20 @@ func handleError(err error) error {
 		return ErrChannelAlreadyOpen
 	case err == pq.ErrChannelNotOpen:
 		return ErrChannelNotOpen
+	case errors.As(err, &pqErr) && pqErr.Code == pqForeignKeyViolation:
+		return errors2.ErrNotFound
+	case errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation:
+		return errors2.ErrAlreadyExists
+	case errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlNoReferencedRow:
+		return errors2.ErrNotFound
+	case errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry:
+		return errors2.ErrAlreadyExists
+	case sqliteCode(err) == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return errors2.ErrNotFound
+	case sqliteCode(err) == sqlite3.SQLITE_CONSTRAINT_UNIQUE ||
+		sqliteCode(err) == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+
+		return errors2.ErrAlreadyExists
 	default:
 		return err
 	}
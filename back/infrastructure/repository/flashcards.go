@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"languago/infrastructure/repository/database"
+	"languago/pkg/models/entities"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FlashcardSort is the order of listed flashcards, ties are broken by the id.
+type FlashcardSort string
+
+const (
+	SortByWord    FlashcardSort = "word"
+	SortByCreated FlashcardSort = "created"
+	SortByUpdated FlashcardSort = "updated"
+)
+
+func (s FlashcardSort) Valid() bool {
+	switch s {
+	case SortByWord, SortByCreated, SortByUpdated:
+		return true
+	}
+	return false
+}
+
+func (s FlashcardSort) column() string {
+	switch s {
+	case SortByCreated:
+		return "created_at"
+	case SortByUpdated:
+		return "updated_at"
+	default:
+		return "word"
+	}
+}
+
+// NewFlashcardCursor returns the position of the card in the given order, the
+// next page is listed from there.
+func NewFlashcardCursor(card *entities.Flashcard, sort FlashcardSort) *FlashcardCursor {
+	cursor := &FlashcardCursor{ID: card.ID}
+
+	switch sort {
+	case SortByCreated:
+		cursor.Time = card.CreatedAt
+	case SortByUpdated:
+		cursor.Time = card.UpdatedAt
+	default:
+		cursor.Word = card.Word
+	}
+
+	return cursor
+}
+
+// key is the sort key of the cursor.
+func (c *FlashcardCursor) key(sort FlashcardSort) any {
+	if sort == SortByCreated || sort == SortByUpdated {
+		return c.Time
+	}
+	return c.Word
+}
+
+// changes reports whether arg changes any field of the card.
+func (arg UpdateFlashcardParams) changes() bool {
+	return arg.Word != nil || arg.Meaning != nil || arg.Usage != nil || arg.Tags != nil
+}
+
+// listFlashcardsParams translates the listing part of arg for the squirrel
+// queries.
+func listFlashcardsParams(owner uuid.UUID, arg SelectFlashcardParams) (database.ListFlashcardsParams, error) {
+	if arg.Sort != "" && !arg.Sort.Valid() {
+		return database.ListFlashcardsParams{}, fmt.Errorf("error unknown flashcard sort %q: %w", arg.Sort, ErrInvalidData)
+	}
+
+	if arg.Limit < 0 {
+		return database.ListFlashcardsParams{}, fmt.Errorf("error negative limit: %w", ErrInvalidData)
+	}
+
+	params := database.ListFlashcardsParams{
+		Owner:   owner,
+		DeckID:  arg.DeckID,
+		Tag:     arg.Tag,
+		Prefix:  arg.Prefix,
+		DueAt:   arg.DueAt,
+		OrderBy: arg.Sort.column(),
+		Desc:    arg.Desc,
+		Limit:   uint64(arg.Limit),
+	}
+
+	if arg.After != nil {
+		params.AfterKey = arg.After.key(arg.Sort)
+		params.AfterID = arg.After.ID
+	}
+
+	return params, nil
+}
+
+// flashcardFromRow converts a row of the squirrel queries, usage is decoded
+// by the storage.
+func flashcardFromRow(row database.Flashcard, usage []string) *entities.Flashcard {
+	return &entities.Flashcard{
+		ID:            row.ID,
+		Owner:         row.Owner,
+		Word:          row.Word.String,
+		Meaning:       row.Meaning.String,
+		UsageExamples: usage,
+		CreatedAt:     row.CreatedAt,
+		UpdatedAt:     row.UpdatedAt,
+		Version:       row.Version,
+	}
+}
+
+// attachTags sets the tags of the cards.
+func attachTags(ctx context.Context, q *database.Queries, cards []*entities.Flashcard) error {
+	ids := make([]uuid.UUID, 0, len(cards))
+	for _, card := range cards {
+		ids = append(ids, card.ID)
+	}
+
+	tags, err := q.SelectFlashcardTags(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("error select flashcard tags: %w", handleError(err))
+	}
+
+	for _, card := range cards {
+		card.Tags = tags[card.ID]
+	}
+
+	return nil
+}
+
+// uniqueTags returns the sorted tags without duplicates.
+func uniqueTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	resp := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		if _, ok := seen[tag]; ok || tag == "" {
+			continue
+		}
+		seen[tag] = struct{}{}
+		resp = append(resp, tag)
+	}
+
+	sort.Strings(resp)
+	return resp
+}
+
+// flashcardTime is the time written to created_at, updated_at and deleted_at.
+// It is in UTC with the precision of the databases, so a card reads back as it
+// was written.
+func flashcardTime() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// apply returns a copy of the card with the changes of arg and the next
+// version.
+func (arg UpdateFlashcardParams) apply(card *entities.Flashcard) *entities.Flashcard {
+	updated := *card
+	if arg.Word != nil {
+		updated.Word = *arg.Word
+	}
+	if arg.Meaning != nil {
+		updated.Meaning = *arg.Meaning
+	}
+	if arg.Usage != nil {
+		updated.UsageExamples = copyStrings(arg.Usage)
+	}
+	if arg.Tags != nil {
+		updated.Tags = uniqueTags(arg.Tags)
+	}
+
+	updated.Version++
+	return &updated
+}
+
+// jsonUsage encodes usage examples for the JSON text column of MySQL and
+// SQLite, no examples are stored as NULL.
+func jsonUsage(usage []string) (sql.NullString, error) {
+	raw, err := entities.UsageToJSON(usage)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(raw), Valid: raw != nil}, nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"languago/pkg/ctxtools"
+	"languago/pkg/models/entities"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type (
+	// FlashcardSnapshot is what the history keeps of a card, the snapshot of
+	// a flashcard revision decodes into it.
+	FlashcardSnapshot struct {
+		Word      string     `json:"word"`
+		Meaning   string     `json:"meaning"`
+		Usage     []string   `json:"usage"`
+		Tags      []string   `json:"tags"`
+		DeletedAt *time.Time `json:"deleted_at"`
+	}
+
+	// DeckSnapshot is what the history keeps of a deck.
+	DeckSnapshot struct {
+		Name      string     `json:"name"`
+		DeletedAt *time.Time `json:"deleted_at"`
+	}
+)
+
+func flashcardSnapshot(card *entities.Flashcard) FlashcardSnapshot {
+	snapshot := FlashcardSnapshot{
+		Word:      card.Word,
+		Meaning:   card.Meaning,
+		Usage:     copyStrings(card.UsageExamples),
+		Tags:      uniqueTags(card.Tags),
+		DeletedAt: card.DeletedAt,
+	}
+
+	// a card without usage or tags reads back with nil or empty slices
+	// depending on the storage, they are the same in the history
+	if snapshot.Usage == nil {
+		snapshot.Usage = []string{}
+	}
+	if snapshot.Tags == nil {
+		snapshot.Tags = []string{}
+	}
+
+	return snapshot
+}
+
+// flashcardRevision describes the change of a card from before to after,
+// before is nil for a created card.
+func flashcardRevision(ctx context.Context, action entities.RevisionAction, before, after *entities.Flashcard) (*entities.Revision, error) {
+	var old any
+	if before != nil {
+		old = flashcardSnapshot(before)
+	}
+
+	return newRevision(ctx, entities.EntityFlashcard, after.ID, after.Owner, after.Version, action, old, flashcardSnapshot(after))
+}
+
+// deckRevision describes the change of a deck from before to after, before is
+// nil for a created deck.
+func deckRevision(ctx context.Context, action entities.RevisionAction, before, after *entities.Deck) (*entities.Revision, error) {
+	var old any
+	if before != nil {
+		old = DeckSnapshot{Name: before.Name, DeletedAt: before.DeletedAt}
+	}
+
+	return newRevision(ctx, entities.EntityDeck, after.Id, after.Owner, after.Version, action, old, DeckSnapshot{Name: after.Name, DeletedAt: after.DeletedAt})
+}
+
+// newRevision returns the revision of the entity made by the caller within the
+// current request. Version is the version of the entity after the change.
+func newRevision(
+	ctx context.Context,
+	entityType entities.EntityType,
+	entityID, owner uuid.UUID,
+	version int64,
+	action entities.RevisionAction,
+	before, after any,
+) (*entities.Revision, error) {
+	snapshot, err := json.Marshal(after)
+	if err != nil {
+		return nil, fmt.Errorf("error encode revision snapshot: %w", err)
+	}
+
+	changes, err := diff(before, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	var actorID uuid.UUID
+	if user := ctxtools.User(ctx); user != nil {
+		actorID = user.Id
+	}
+
+	return &entities.Revision{
+		ID:         uuid.New(),
+		EntityType: entityType,
+		EntityID:   entityID,
+		Owner:      owner,
+		Version:    version,
+		Action:     action,
+		ActorID:    actorID,
+		RequestID:  ctxtools.RequestId(ctx),
+		Changes:    changes,
+		Snapshot:   snapshot,
+		CreatedAt:  flashcardTime(),
+	}, nil
+}
+
+// diff compares the fields of before with the encoded snapshot after, a field
+// missing in before counts as null.
+func diff(before any, after json.RawMessage) (map[string]entities.FieldChange, error) {
+	oldFields := make(map[string]json.RawMessage)
+	if before != nil {
+		raw, err := json.Marshal(before)
+		if err != nil {
+			return nil, fmt.Errorf("error encode revision snapshot: %w", err)
+		}
+
+		if err := json.Unmarshal(raw, &oldFields); err != nil {
+			return nil, fmt.Errorf("error decode revision snapshot: %w", err)
+		}
+	}
+
+	newFields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(after, &newFields); err != nil {
+		return nil, fmt.Errorf("error decode revision snapshot: %w", err)
+	}
+
+	changes := make(map[string]entities.FieldChange)
+	for name, value := range newFields {
+		old, ok := oldFields[name]
+		if !ok {
+			old = json.RawMessage("null")
+		}
+
+		if !bytes.Equal(old, value) {
+			changes[name] = entities.FieldChange{Old: old, New: value}
+		}
+	}
+
+	return changes, nil
+}
+
+// encodeChanges returns the changes of the revision as they are stored.
+func encodeChanges(revision *entities.Revision) (string, error) {
+	changes, err := json.Marshal(revision.Changes)
+	if err != nil {
+		return "", fmt.Errorf("error encode revision changes: %w", err)
+	}
+
+	return string(changes), nil
+}
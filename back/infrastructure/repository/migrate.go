@@ -0,0 +1,484 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"languago/cfg/migrations"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const migrationsTable = "schema_migrations"
+
+// pgMigrationLock is the key of the advisory lock held while migrating, an
+// arbitrary number shared by all nodes.
+const pgMigrationLock int64 = 7311022840
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type (
+	// Migration is a versioned schema change. Migrations are applied in the
+	// order of versions, Down reverts Up.
+	Migration struct {
+		Version  int64
+		Name     string
+		Up       string
+		Down     string
+		Checksum string
+	}
+
+	MigrationStatus struct {
+		Version int64
+		Name    string
+		// AppliedAt is nil for pending migrations.
+		AppliedAt *time.Time
+	}
+
+	// Migrator applies the migrations embedded for the database driver. The
+	// applied ones are recorded in the schema_migrations table with the
+	// checksum of the up script, a changed script is refused. Nodes take a
+	// database lock while migrating, so only one of them does it.
+	Migrator interface {
+		// Up applies all pending migrations.
+		Up(ctx context.Context) error
+		// Down reverts the last n applied migrations.
+		Down(ctx context.Context, n int) error
+		Status(ctx context.Context) ([]MigrationStatus, error)
+	}
+
+	migrator struct {
+		db         *sql.DB
+		dialect    migrationDialect
+		migrations []Migration
+	}
+
+	migrationDialect struct {
+		dir         string
+		createTable string
+		insert      string
+		delete      string
+		// lock is taken on the connection the migrations run on. It is
+		// released by unlock, which gets the error of the run.
+		lock   func(ctx context.Context, conn *sql.Conn) error
+		unlock func(conn *sql.Conn, err error) error
+		// txPerMigration runs each migration in its own transaction, for
+		// databases with transactional DDL.
+		txPerMigration bool
+		// splitStatements executes scripts statement by statement, for
+		// drivers that run a single statement per call.
+		splitStatements bool
+	}
+
+	appliedMigration struct {
+		name      string
+		checksum  string
+		appliedAt time.Time
+	}
+
+	// nopMigrator is the migrator of the in-memory storage, which has no
+	// schema.
+	nopMigrator struct{}
+)
+
+var migrationDialects = map[string]migrationDialect{
+	"postgres": {
+		dir: "postgresql",
+		createTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint PRIMARY KEY,
+			name text NOT NULL,
+			checksum text NOT NULL,
+			applied_at timestamptz NOT NULL DEFAULT now()
+		)`,
+		insert: "INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+		delete: "DELETE FROM schema_migrations WHERE version = $1",
+		lock: func(ctx context.Context, conn *sql.Conn) error {
+			_, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", pgMigrationLock)
+			return err
+		},
+		unlock: func(conn *sql.Conn, _ error) error {
+			_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", pgMigrationLock)
+			return err
+		},
+		txPerMigration: true,
+	},
+	// DDL commits implicitly in MySQL, a failed migration may be left half
+	// applied and has to be cleaned up by hand.
+	"mysql": {
+		dir: "mysql",
+		createTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint PRIMARY KEY,
+			name varchar(255) NOT NULL,
+			checksum char(64) NOT NULL,
+			applied_at datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+		insert: "INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)",
+		delete: "DELETE FROM schema_migrations WHERE version = ?",
+		lock: func(ctx context.Context, conn *sql.Conn) error {
+			var locked sql.NullInt64
+			err := conn.QueryRowContext(ctx, "SELECT GET_LOCK('languago_migrations', -1)").Scan(&locked)
+			if err == nil && locked.Int64 != 1 {
+				err = fmt.Errorf("error lock not granted")
+			}
+			return err
+		},
+		unlock: func(conn *sql.Conn, _ error) error {
+			_, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK('languago_migrations')")
+			return err
+		},
+		splitStatements: true,
+	},
+	// SQLite has no locks of its own, the whole run is a single immediate
+	// transaction, which holds the write lock of the database file. It is
+	// applied or rolled back as a whole.
+	"sqlite": {
+		dir: "sqlite",
+		createTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version integer PRIMARY KEY,
+			name text NOT NULL,
+			checksum text NOT NULL,
+			applied_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		insert: "INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)",
+		delete: "DELETE FROM schema_migrations WHERE version = ?",
+		lock: func(ctx context.Context, conn *sql.Conn) error {
+			_, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE")
+			return err
+		},
+		unlock: func(conn *sql.Conn, err error) error {
+			end := "COMMIT"
+			if err != nil {
+				end = "ROLLBACK"
+			}
+
+			_, err = conn.ExecContext(context.Background(), end)
+			return err
+		},
+	},
+}
+
+func newMigrator(db *sql.DB, driver string) (*migrator, error) {
+	dialect, ok := migrationDialects[driver]
+	if !ok {
+		return nil, fmt.Errorf("error no migrations for driver %s", driver)
+	}
+
+	list, err := loadMigrations(migrations.FS, dialect.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: list,
+	}, nil
+}
+
+// loadMigrations reads the migrations of the directory ordered by version.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error migration %s version: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("error duplicate migration version %d", version)
+		}
+
+		raw, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error read migration %s: %w", entry.Name(), err)
+		}
+
+		if match[3] == "up" {
+			m.Up = string(raw)
+			m.Checksum = checksum(m.Up)
+		} else {
+			m.Down = string(raw)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("error migration %d has no up script", m.Version)
+		}
+		list = append(list, *m)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+
+	return list, nil
+}
+
+func checksum(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
+
+func (m *migrator) Up(ctx context.Context) error {
+	return m.locked(ctx, func(conn *sql.Conn, applied map[int64]appliedMigration) error {
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			err := m.apply(ctx, conn, migration.Up, m.dialect.insert,
+				migration.Version, migration.Name, migration.Checksum)
+			if err != nil {
+				return fmt.Errorf("error apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (m *migrator) Down(ctx context.Context, n int) error {
+	return m.locked(ctx, func(conn *sql.Conn, applied map[int64]appliedMigration) error {
+		for i := len(m.migrations) - 1; i >= 0 && n > 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+
+			if migration.Down == "" {
+				return fmt.Errorf("error migration %d_%s can't be reverted", migration.Version, migration.Name)
+			}
+
+			err := m.apply(ctx, conn, migration.Down, m.dialect.delete, migration.Version)
+			if err != nil {
+				return fmt.Errorf("error revert migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			n--
+		}
+
+		return nil
+	})
+}
+
+func (m *migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var status []MigrationStatus
+
+	err := m.locked(ctx, func(conn *sql.Conn, applied map[int64]appliedMigration) error {
+		status = make([]MigrationStatus, 0, len(m.migrations))
+		for _, migration := range m.migrations {
+			s := MigrationStatus{
+				Version: migration.Version,
+				Name:    migration.Name,
+			}
+			if a, ok := applied[migration.Version]; ok {
+				appliedAt := a.appliedAt
+				s.AppliedAt = &appliedAt
+			}
+			status = append(status, s)
+		}
+
+		return nil
+	})
+
+	return status, err
+}
+
+// locked runs fn on a single connection holding the migration lock. fn gets
+// the migrations already applied, after they are checked against the
+// embedded ones.
+func (m *migrator) locked(ctx context.Context, fn func(conn *sql.Conn, applied map[int64]appliedMigration) error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if err := m.dialect.lock(ctx, conn); err != nil {
+		return fmt.Errorf("error take migration lock: %w", err)
+	}
+	defer func() {
+		if unlockErr := m.dialect.unlock(conn, err); unlockErr != nil && err == nil {
+			err = fmt.Errorf("error release migration lock: %w", unlockErr)
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, m.dialect.createTable); err != nil {
+		return fmt.Errorf("error create %s table: %w", migrationsTable, err)
+	}
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	if err := m.verify(applied); err != nil {
+		return err
+	}
+
+	return fn(conn, applied)
+}
+
+func (m *migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("error select applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var (
+			version int64
+			a       appliedMigration
+		)
+		if err := rows.Scan(&version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+			return nil, fmt.Errorf("error scan applied migration: %w", err)
+		}
+		applied[version] = a
+	}
+
+	return applied, rows.Err()
+}
+
+// verify checks that the database has no migrations unknown to this build and
+// that the applied ones were not changed afterwards.
+func (m *migrator) verify(applied map[int64]appliedMigration) error {
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	for version, a := range applied {
+		migration, ok := known[version]
+		if !ok {
+			return fmt.Errorf("error migration %d_%s: %w", version, a.name, ErrUnknownMigration)
+		}
+
+		if migration.Checksum != a.checksum {
+			return fmt.Errorf("error migration %d_%s: %w", version, a.name, ErrMigrationChecksum)
+		}
+	}
+
+	return nil
+}
+
+// apply runs the script and records it with the statement and args.
+func (m *migrator) apply(ctx context.Context, conn *sql.Conn, script, record string, args ...any) error {
+	if !m.dialect.txPerMigration {
+		if err := m.exec(ctx, conn.ExecContext, script); err != nil {
+			return err
+		}
+
+		_, err := conn.ExecContext(ctx, record, args...)
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.exec(ctx, tx.ExecContext, script); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *migrator) exec(ctx context.Context, exec func(context.Context, string, ...any) (sql.Result, error), script string) error {
+	statements := []string{script}
+	if m.dialect.splitStatements {
+		statements = splitStatements(script)
+	}
+
+	for _, statement := range statements {
+		if _, err := exec(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitStatements splits the script on semicolons outside of quotes and
+// comments. Empty statements are dropped.
+func splitStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		quote      rune
+		comment    bool
+	)
+
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case comment:
+			if r == '\n' {
+				comment = false
+			}
+		case quote != 0:
+			current.WriteRune(r)
+			if r == '\\' && i+1 < len(runes) {
+				i++
+				current.WriteRune(runes[i])
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-', r == '#':
+			comment = true
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+			current.WriteRune(r)
+		case r == ';':
+			if s := strings.TrimSpace(current.String()); s != "" {
+				statements = append(statements, s)
+			}
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if s := strings.TrimSpace(current.String()); s != "" {
+		statements = append(statements, s)
+	}
+
+	return statements
+}
+
+func (nopMigrator) Up(ctx context.Context) error { return nil }
+
+func (nopMigrator) Down(ctx context.Context, n int) error { return nil }
+
+func (nopMigrator) Status(ctx context.Context) ([]MigrationStatus, error) { return nil, nil }
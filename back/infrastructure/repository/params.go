@@ -1,6 +1,10 @@
 package repository
 
 import (
+	"languago/pkg/models"
+	"languago/pkg/models/entities"
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -18,9 +22,8 @@ type (
 	}
 
 	CreateDeckParams struct {
-		ID    uuid.UUID `db:"id" json:"id"`
-		Name  string    `db:"name" json:"name"`
-		Owner uuid.UUID `db:"owner" json:"owner"`
+		ID   uuid.UUID `db:"id" json:"id"`
+		Name string    `db:"name" json:"name"`
 	}
 
 	CreateFlashcardParams struct {
@@ -28,6 +31,7 @@ type (
 		Word    string    `db:"word" json:"word"`
 		Meaning string    `db:"meaning" json:"meaning"`
 		Usage   []string  `db:"usage" json:"usage"`
+		Tags    []string  `db:"tags" json:"tags"`
 	}
 
 	DeleteFromDeckParams struct {
@@ -38,6 +42,24 @@ type (
 	UpdateDeckParams struct {
 		Name string    `db:"name" json:"name"`
 		ID   uuid.UUID `db:"id" json:"id"`
+		// Version the change is based on, 0 updates any version
+		Version int64 `db:"version" json:"version"`
+	}
+
+	DeleteDeckParams struct {
+		ID uuid.UUID `db:"id" json:"id"`
+	}
+
+	// SelectRevisionsParams selects the history of the caller's card or
+	// deck, ID narrows it down to a single revision.
+	SelectRevisionsParams struct {
+		ID         uuid.UUID           `db:"id" json:"id"`
+		EntityType entities.EntityType `db:"entity_type" json:"entity_type"`
+		EntityID   uuid.UUID           `db:"entity_id" json:"entity_id"`
+	}
+
+	RestoreDeckParams struct {
+		ID uuid.UUID `db:"id" json:"id"`
 	}
 
 	SelectFlashcardParams struct {
@@ -47,6 +69,34 @@ type (
 		Usage       []string  `db:"usage" json:"usage"`
 		DeckID      uuid.UUID `db:"deck_id" json:"deck_id"`
 		FlashcardID uuid.UUID `db:"flashcard_id" json:"flashcard_id"`
+
+		// Listing, used unless the card is looked up by ID or by Word or
+		// Meaning within DeckID. Prefix matches the beginning of the word
+		// regardless of case, DueAt keeps the cards with a review due by then.
+		Tag    string        `db:"tag" json:"tag"`
+		Prefix string        `db:"prefix" json:"prefix"`
+		DueAt  time.Time     `db:"due_at" json:"due_at"`
+		Sort   FlashcardSort `db:"sort" json:"sort"`
+		Desc   bool          `db:"desc" json:"desc"`
+		// After is the last card of the previous page
+		After *FlashcardCursor `db:"after" json:"after"`
+		// Limit of 0 returns all cards
+		Limit int `db:"limit" json:"limit"`
+	}
+
+	// FlashcardCursor is the position of a card in the listing order. Only the
+	// key of the sort order is used besides ID.
+	FlashcardCursor struct {
+		ID   uuid.UUID `json:"id"`
+		Word string    `json:"word,omitempty"`
+		Time time.Time `json:"time,omitempty"`
+	}
+
+	// SearchFlashcardsParams searches the caller's cards for Query in the
+	// word, the meaning and the usage examples. Limit is required.
+	SearchFlashcardsParams struct {
+		Query string `db:"query" json:"query"`
+		Limit int    `db:"limit" json:"limit"`
 	}
 
 	SelectUserParams struct {
@@ -55,30 +105,81 @@ type (
 	}
 
 	SelectDeckParams struct {
-		ID    uuid.UUID `db:"id" json:"id"`
-		Name  string    `db:"name" json:"name"`
-		Owner uuid.UUID `db:"owner" json:"owner"`
+		ID   uuid.UUID `db:"id" json:"id"`
+		Name string    `db:"name" json:"name"`
 	}
 
+	// UpdateFlashcardParams changes every field which is set, the others are
+	// left as they are. An empty string or list clears the field.
 	UpdateFlashcardParams struct {
-		Word    string    `db:"word" json:"word"`
-		Meaning string    `db:"meaning" json:"meaning"`
-		Usage   []string  `db:"usage" json:"usage"`
-		ID      uuid.UUID `db:"id" json:"id"`
+		ID uuid.UUID `db:"id" json:"id"`
+		// Word and Meaning are changed unless nil
+		Word    *string `db:"word" json:"word"`
+		Meaning *string `db:"meaning" json:"meaning"`
+		// Usage and Tags are replaced unless nil
+		Usage []string `db:"usage" json:"usage"`
+		Tags  []string `db:"tags" json:"tags"`
+		// Version the change is based on, 0 updates any version
+		Version int64 `db:"version" json:"version"`
 	}
 
 	SelectFromDeckParams struct {
 		CardID      uuid.UUID `db:"card_id" json:"card_id"`
 		DeckID      uuid.UUID `db:"deck_id" json:"deck_id"`
-		DeckOwner   uuid.UUID `db:"deck_owner" json:"deck_owner"`
 		WordMeaning string    `db:"word_meaning" json:"word_meaning"`
 		Word        string    `db:"word" json:"word"`
 		Usage       []string  `db:"usage" json:"usage"`
 	}
 
+	// UpdateUserParams changes only the fields that are set. Setting a
+	// password clears a pending password reset.
 	UpdateUserParams struct {
-		Login    string    `db:"login" json:"login"`
-		ID       uuid.UUID `db:"id" json:"id"`
-		Password string    `db:"password" json:"password"`
+		Login    string      `db:"login" json:"login"`
+		ID       uuid.UUID   `db:"id" json:"id"`
+		Password string      `db:"password" json:"password"`
+		Role     models.Role `db:"role" json:"role"`
+		// Disabled disables (true) or enables (false) the account when set.
+		Disabled *bool `db:"disabled_at" json:"disabled"`
+		// PasswordResetHash requires a password reset when set, the new
+		// password can be set only with the token it is the hash of.
+		PasswordResetHash string `db:"password_reset_hash" json:"-"`
+	}
+
+	SelectUsersParams struct {
+		Limit  int `db:"limit" json:"limit"`
+		Offset int `db:"offset" json:"offset"`
+	}
+
+	UpsertReviewParams struct {
+		FlashcardID    uuid.UUID  `db:"flashcard_id" json:"flashcard_id"`
+		EaseFactor     float64    `db:"ease_factor" json:"ease_factor"`
+		Interval       int        `db:"interval_days" json:"interval_days"`
+		Repetitions    int        `db:"repetitions" json:"repetitions"`
+		Lapses         int        `db:"lapses" json:"lapses"`
+		DueAt          time.Time  `db:"due_at" json:"due_at"`
+		LastReviewedAt *time.Time `db:"last_reviewed_at" json:"last_reviewed_at"`
+	}
+
+	SelectReviewParams struct {
+		FlashcardID uuid.UUID `db:"flashcard_id" json:"flashcard_id"`
+	}
+
+	SelectDueReviewsParams struct {
+		DueAt time.Time `db:"due_at" json:"due_at"`
+		Limit int       `db:"limit" json:"limit"`
+	}
+
+	CreateSessionParams struct {
+		ID          uuid.UUID `db:"id" json:"id"`
+		UserID      uuid.UUID `db:"user_id" json:"user_id"`
+		RefreshHash string    `db:"refresh_hash" json:"refresh_hash"`
+		ExpiresAt   time.Time `db:"expires_at" json:"expires_at"`
+	}
+
+	RotateSessionParams struct {
+		ID          uuid.UUID `db:"id" json:"id"`
+		OldHash     string    `db:"old_hash" json:"old_hash"`
+		RefreshHash string    `db:"refresh_hash" json:"refresh_hash"`
+		ExpiresAt   time.Time `db:"expires_at" json:"expires_at"`
 	}
 )
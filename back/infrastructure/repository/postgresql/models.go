@@ -6,25 +6,71 @@ package postgresql
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Deck struct {
-	ID    uuid.UUID      `db:"id" json:"id"`
-	Name  sql.NullString `db:"name" json:"name"`
-	Owner uuid.NullUUID  `db:"owner" json:"owner"`
+	ID        uuid.UUID      `db:"id" json:"id"`
+	Name      sql.NullString `db:"name" json:"name"`
+	Owner     uuid.UUID      `db:"owner" json:"owner"`
+	Version   int64          `db:"version" json:"version"`
+	DeletedAt sql.NullTime   `db:"deleted_at" json:"deleted_at"`
 }
 
 type Flashcard struct {
-	ID      uuid.UUID      `db:"id" json:"id"`
-	Word    sql.NullString `db:"word" json:"word"`
-	Meaning sql.NullString `db:"meaning" json:"meaning"`
-	Usage   []string       `db:"usage" json:"usage"`
+	ID        uuid.UUID      `db:"id" json:"id"`
+	Word      sql.NullString `db:"word" json:"word"`
+	Meaning   sql.NullString `db:"meaning" json:"meaning"`
+	Usage     []string       `db:"usage" json:"usage"`
+	Owner     uuid.UUID      `db:"owner" json:"owner"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+	Version   int64          `db:"version" json:"version"`
+	DeletedAt sql.NullTime   `db:"deleted_at" json:"deleted_at"`
+}
+
+type Review struct {
+	UserID         uuid.UUID    `db:"user_id" json:"user_id"`
+	FlashcardID    uuid.UUID    `db:"flashcard_id" json:"flashcard_id"`
+	EaseFactor     float64      `db:"ease_factor" json:"ease_factor"`
+	IntervalDays   int32        `db:"interval_days" json:"interval_days"`
+	Repetitions    int32        `db:"repetitions" json:"repetitions"`
+	Lapses         int32        `db:"lapses" json:"lapses"`
+	DueAt          time.Time    `db:"due_at" json:"due_at"`
+	LastReviewedAt sql.NullTime `db:"last_reviewed_at" json:"last_reviewed_at"`
+}
+
+type Revision struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	EntityType string    `db:"entity_type" json:"entity_type"`
+	EntityID   uuid.UUID `db:"entity_id" json:"entity_id"`
+	Owner      uuid.UUID `db:"owner" json:"owner"`
+	Version    int64     `db:"version" json:"version"`
+	Action     string    `db:"action" json:"action"`
+	ActorID    uuid.UUID `db:"actor_id" json:"actor_id"`
+	RequestID  string    `db:"request_id" json:"request_id"`
+	Changes    string    `db:"changes" json:"changes"`
+	Snapshot   string    `db:"snapshot" json:"snapshot"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+type Session struct {
+	ID          uuid.UUID    `db:"id" json:"id"`
+	UserID      uuid.UUID    `db:"user_id" json:"user_id"`
+	RefreshHash string       `db:"refresh_hash" json:"refresh_hash"`
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`
+	ExpiresAt   time.Time    `db:"expires_at" json:"expires_at"`
+	RevokedAt   sql.NullTime `db:"revoked_at" json:"revoked_at"`
 }
 
 type User struct {
-	ID       uuid.UUID      `db:"id" json:"id"`
-	Login    sql.NullString `db:"login" json:"login"`
-	Password sql.NullString `db:"password" json:"password"`
+	ID                    uuid.UUID      `db:"id" json:"id"`
+	Login                 sql.NullString `db:"login" json:"login"`
+	Password              sql.NullString `db:"password" json:"password"`
+	Role                  string         `db:"role" json:"role"`
+	DisabledAt            sql.NullTime   `db:"disabled_at" json:"disabled_at"`
+	PasswordResetRequired bool           `db:"password_reset_required" json:"password_reset_required"`
+	PasswordResetHash     string         `db:"password_reset_hash" json:"password_reset_hash"`
 }
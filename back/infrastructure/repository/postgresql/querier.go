@@ -17,25 +17,53 @@ type Querier interface {
 	CreateDeck(ctx context.Context, arg CreateDeckParams) (CreateDeckRow, error)
 	// Flashcards
 	CreateFlashcard(ctx context.Context, arg CreateFlashcardParams) (CreateFlashcardRow, error)
+	// History
+	CreateRevision(ctx context.Context, arg CreateRevisionParams) error
+	// Sessions
+	CreateSession(ctx context.Context, arg CreateSessionParams) error
 	// User
 	CreateUser(ctx context.Context, arg CreateUserParams) error
-	DeleteDeck(ctx context.Context, id uuid.UUID) error
-	DeleteFlashcard(ctx context.Context, id uuid.UUID) error
-	DeleteFromDeck(ctx context.Context, arg DeleteFromDeckParams) error
+	DeleteDeck(ctx context.Context, arg DeleteDeckParams) (int64, error)
+	DeleteFlashcard(ctx context.Context, arg DeleteFlashcardParams) (int64, error)
+	DeleteFromDeck(ctx context.Context, arg DeleteFromDeckParams) (int64, error)
 	DeleteUser(ctx context.Context, id uuid.UUID) error
-	EditDeckProps(ctx context.Context, arg EditDeckPropsParams) error
-	SelectDeck(ctx context.Context, id uuid.UUID) (Deck, error)
-	SelectDecksByName(ctx context.Context, name sql.NullString) ([]Deck, error)
-	SelectFlashcardByID(ctx context.Context, id uuid.UUID) (Flashcard, error)
-	SelectFlashcardByMeaning(ctx context.Context, arg SelectFlashcardByMeaningParams) ([]SelectFlashcardByMeaningRow, error)
-	SelectFlashcardByWord(ctx context.Context, arg SelectFlashcardByWordParams) ([]SelectFlashcardByWordRow, error)
-	SelectOwnerDecks(ctx context.Context, owner uuid.NullUUID) ([]Deck, error)
+	EditDeckProps(ctx context.Context, arg EditDeckPropsParams) (int64, error)
+	PurgeDecks(ctx context.Context, deletedAt sql.NullTime) (int64, error)
+	PurgeFlashcards(ctx context.Context, deletedAt sql.NullTime) (int64, error)
+	RequirePasswordReset(ctx context.Context, arg RequirePasswordResetParams) (int64, error)
+	RestoreDeck(ctx context.Context, arg RestoreDeckParams) (int64, error)
+	RestoreFlashcard(ctx context.Context, arg RestoreFlashcardParams) (int64, error)
+	RevokeSession(ctx context.Context, id uuid.UUID) error
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID) error
+	RotateSession(ctx context.Context, arg RotateSessionParams) (int64, error)
+	SearchFlashcards(ctx context.Context, arg SearchFlashcardsParams) ([]SearchFlashcardsRow, error)
+	SelectDeck(ctx context.Context, arg SelectDeckParams) (Deck, error)
+	SelectDeckFlashcards(ctx context.Context, deckID uuid.UUID) ([]Flashcard, error)
+	SelectDecksByName(ctx context.Context, arg SelectDecksByNameParams) ([]Deck, error)
+	SelectDeletedDeck(ctx context.Context, arg SelectDeletedDeckParams) (Deck, error)
+	SelectDeletedDecks(ctx context.Context, owner uuid.UUID) ([]Deck, error)
+	SelectDeletedFlashcard(ctx context.Context, arg SelectDeletedFlashcardParams) (Flashcard, error)
+	SelectDeletedFlashcards(ctx context.Context, owner uuid.UUID) ([]Flashcard, error)
+	SelectDueReviews(ctx context.Context, arg SelectDueReviewsParams) ([]SelectDueReviewsRow, error)
+	SelectFlashcardByID(ctx context.Context, arg SelectFlashcardByIDParams) (Flashcard, error)
+	SelectFlashcardByMeaning(ctx context.Context, arg SelectFlashcardByMeaningParams) ([]Flashcard, error)
+	SelectFlashcardByWord(ctx context.Context, arg SelectFlashcardByWordParams) ([]Flashcard, error)
+	SelectOwnerDecks(ctx context.Context, owner uuid.UUID) ([]Deck, error)
+	// Reviews
+	SelectReview(ctx context.Context, arg SelectReviewParams) (Review, error)
+	SelectRevision(ctx context.Context, arg SelectRevisionParams) (Revision, error)
+	SelectRevisions(ctx context.Context, arg SelectRevisionsParams) ([]Revision, error)
+	SelectSession(ctx context.Context, id uuid.UUID) (Session, error)
 	SelectUser(ctx context.Context, arg SelectUserParams) (User, error)
 	SelectUserByID(ctx context.Context, id uuid.UUID) (User, error)
 	SelectUserByLogin(ctx context.Context, login sql.NullString) (User, error)
-	UpdateFlashcard(ctx context.Context, arg UpdateFlashcardParams) error
-	UpdateUserLogin(ctx context.Context, arg UpdateUserLoginParams) (UpdateUserLoginRow, error)
-	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
+	SelectUsers(ctx context.Context, arg SelectUsersParams) ([]User, error)
+	UpdateFlashcard(ctx context.Context, arg UpdateFlashcardParams) (int64, error)
+	UpdateUserDisabled(ctx context.Context, arg UpdateUserDisabledParams) (int64, error)
+	UpdateUserLogin(ctx context.Context, arg UpdateUserLoginParams) (int64, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (int64, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (int64, error)
+	UpsertReview(ctx context.Context, arg UpsertReviewParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"fmt"
+	"languago/pkg/models/entities"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// The storages other than PostgreSQL search with matchFlashcards, it scans all
+// of the owner's cards. The weights follow the ones ts_rank gives to the word,
+// the meaning and the usage in PostgreSQL.
+const (
+	wordWeight    = 1.0
+	meaningWeight = 0.4
+	usageWeight   = 0.2
+
+	// a term matching the beginning of a word or a word with a typo counts
+	// less than the word itself
+	prefixScore = 0.7
+	typoScore   = 0.5
+
+	// snippetWords is the length of the snippet, it starts a few words before
+	// the first match
+	snippetWords  = 12
+	snippetBefore = 3
+)
+
+// searchTerms validates arg and returns the folded terms of the query.
+func searchTerms(arg SearchFlashcardsParams) ([]string, error) {
+	if arg.Limit <= 0 {
+		return nil, fmt.Errorf("error search limit must be positive: %w", ErrInvalidData)
+	}
+
+	terms := words(fold(arg.Query))
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("error empty search query: %w", ErrInvalidData)
+	}
+
+	return terms, nil
+}
+
+// matchFlashcards ranks the cards containing every term, best first, and
+// returns up to limit of them.
+func matchFlashcards(terms []string, cards []*entities.Flashcard, limit int) []*entities.FlashcardMatch {
+	resp := make([]*entities.FlashcardMatch, 0)
+	for _, card := range cards {
+		if match := matchFlashcard(terms, card); match != nil {
+			resp = append(resp, match)
+		}
+	}
+
+	slices.SortFunc(resp, func(a, b *entities.FlashcardMatch) int {
+		switch {
+		case a.Rank > b.Rank:
+			return -1
+		case a.Rank < b.Rank:
+			return 1
+		}
+		return strings.Compare(a.Flashcard.ID.String(), b.Flashcard.ID.String())
+	})
+
+	if len(resp) > limit {
+		resp = resp[:limit]
+	}
+
+	return resp
+}
+
+func matchFlashcard(terms []string, card *entities.Flashcard) *entities.FlashcardMatch {
+	fields := []struct {
+		words  []string
+		weight float64
+	}{
+		{words(fold(card.Word)), wordWeight},
+		{words(fold(card.Meaning)), meaningWeight},
+		{words(fold(strings.Join(card.UsageExamples, " "))), usageWeight},
+	}
+
+	var rank float64
+	for _, term := range terms {
+		var best float64
+		for _, field := range fields {
+			for _, word := range field.words {
+				best = max(best, field.weight*termScore(term, word))
+			}
+		}
+
+		if best == 0 {
+			return nil
+		}
+		rank += best
+	}
+
+	text := strings.Join(append([]string{card.Word, card.Meaning}, card.UsageExamples...), " ")
+
+	return &entities.FlashcardMatch{
+		Flashcard: card,
+		Rank:      rank / float64(len(terms)),
+		Snippet:   snippet(text, terms),
+	}
+}
+
+// termScore tells how well the folded word matches the folded term, 0 is no
+// match.
+func termScore(term, word string) float64 {
+	switch {
+	case term == word:
+		return 1
+	case utf8.RuneCountInString(term) >= 3 && strings.HasPrefix(word, term):
+		return prefixScore
+	}
+
+	// longer terms tolerate more typos, short ones would match anything
+	var typos int
+	switch n := utf8.RuneCountInString(term); {
+	case n >= 8:
+		typos = 2
+	case n >= 4:
+		typos = 1
+	default:
+		return 0
+	}
+
+	if distance(term, word) <= typos {
+		return typoScore
+	}
+	return 0
+}
+
+// distance is the Levenshtein distance of a and b in runes.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// htmlEscaper escapes the card text put around the <b> tags of a snippet, the
+// SearchFlashcards query in PostgreSQL replaces the same characters.
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// snippet returns the words of text around the first match with the matching
+// words in <b> tags, like ts_headline does in PostgreSQL. The text is HTML
+// escaped, so only the tags are markup.
+func snippet(text string, terms []string) string {
+	spans := wordSpans(text)
+	if len(spans) == 0 {
+		return ""
+	}
+
+	matched := make([]bool, len(spans))
+	first := -1
+	for i, span := range spans {
+		word := fold(text[span[0]:span[1]])
+		for _, term := range terms {
+			if termScore(term, word) > 0 {
+				matched[i] = true
+				break
+			}
+		}
+
+		if matched[i] && first < 0 {
+			first = i
+		}
+	}
+
+	start := max(first-snippetBefore, 0)
+	end := min(start+snippetWords, len(spans))
+
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		if i > start {
+			htmlEscaper.WriteString(&b, text[spans[i-1][1]:spans[i][0]])
+		}
+
+		word := htmlEscaper.Replace(text[spans[i][0]:spans[i][1]])
+		if matched[i] {
+			word = "<b>" + word + "</b>"
+		}
+		b.WriteString(word)
+	}
+
+	return b.String()
+}
+
+// fold lowercases s and strips the accents, as the flashcards text search
+// configuration does in PostgreSQL.
+func fold(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	folded, _, err := transform.String(t, strings.ToLower(s))
+	if err != nil {
+		return strings.ToLower(s)
+	}
+	return folded
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(s, isSeparator)
+}
+
+// wordSpans returns the byte offsets of the words of s.
+func wordSpans(s string) [][2]int {
+	var (
+		spans [][2]int
+		start = -1
+	)
+
+	for i, r := range s {
+		switch {
+		case isSeparator(r) && start >= 0:
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		case !isSeparator(r) && start < 0:
+			start = i
+		}
+	}
+
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(s)})
+	}
+
+	return spans
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r)
+}
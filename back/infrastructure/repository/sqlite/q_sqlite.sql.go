@@ -0,0 +1,1186 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.21.0
+// source: q_sqlite.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const addToDeck = `-- name: AddToDeck :exec
+INSERT INTO flashcard_decks
+    (deck_id, flashcard_id)
+    VALUES
+    (?, ?)
+    ON CONFLICT DO NOTHING
+`
+
+type AddToDeckParams struct {
+	DeckID      uuid.UUID `db:"deck_id" json:"deck_id"`
+	FlashcardID uuid.UUID `db:"flashcard_id" json:"flashcard_id"`
+}
+
+func (q *Queries) AddToDeck(ctx context.Context, arg AddToDeckParams) error {
+	_, err := q.db.ExecContext(ctx, addToDeck, arg.DeckID, arg.FlashcardID)
+	return err
+}
+
+const createDeck = `-- name: CreateDeck :exec
+INSERT INTO decks 
+    (id, name, owner)
+    VALUES
+    (?, ?, ?)
+`
+
+type CreateDeckParams struct {
+	ID    uuid.UUID      `db:"id" json:"id"`
+	Name  sql.NullString `db:"name" json:"name"`
+	Owner uuid.UUID      `db:"owner" json:"owner"`
+}
+
+// Decks
+func (q *Queries) CreateDeck(ctx context.Context, arg CreateDeckParams) error {
+	_, err := q.db.ExecContext(ctx, createDeck, arg.ID, arg.Name, arg.Owner)
+	return err
+}
+
+const createFlashcard = `-- name: CreateFlashcard :exec
+INSERT INTO flashcards
+    (id, word, meaning, usage, owner, created_at, updated_at)
+    VALUES
+    (?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateFlashcardParams struct {
+	ID        uuid.UUID      `db:"id" json:"id"`
+	Word      sql.NullString `db:"word" json:"word"`
+	Meaning   sql.NullString `db:"meaning" json:"meaning"`
+	Usage     sql.NullString `db:"usage" json:"usage"`
+	Owner     uuid.UUID      `db:"owner" json:"owner"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// Flashcards
+func (q *Queries) CreateFlashcard(ctx context.Context, arg CreateFlashcardParams) error {
+	_, err := q.db.ExecContext(ctx, createFlashcard,
+		arg.ID,
+		arg.Word,
+		arg.Meaning,
+		arg.Usage,
+		arg.Owner,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const createRevision = `-- name: CreateRevision :exec
+INSERT INTO revisions
+    (id, entity_type, entity_id, owner, version, action, actor_id, request_id, changes, snapshot, created_at)
+    VALUES
+    (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateRevisionParams struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	EntityType string    `db:"entity_type" json:"entity_type"`
+	EntityID   uuid.UUID `db:"entity_id" json:"entity_id"`
+	Owner      uuid.UUID `db:"owner" json:"owner"`
+	Version    int64     `db:"version" json:"version"`
+	Action     string    `db:"action" json:"action"`
+	ActorID    uuid.UUID `db:"actor_id" json:"actor_id"`
+	RequestID  string    `db:"request_id" json:"request_id"`
+	Changes    string    `db:"changes" json:"changes"`
+	Snapshot   string    `db:"snapshot" json:"snapshot"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// History
+func (q *Queries) CreateRevision(ctx context.Context, arg CreateRevisionParams) error {
+	_, err := q.db.ExecContext(ctx, createRevision,
+		arg.ID,
+		arg.EntityType,
+		arg.EntityID,
+		arg.Owner,
+		arg.Version,
+		arg.Action,
+		arg.ActorID,
+		arg.RequestID,
+		arg.Changes,
+		arg.Snapshot,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const createSession = `-- name: CreateSession :exec
+INSERT INTO sessions
+    (id, user_id, refresh_hash, expires_at)
+    VALUES
+    (?, ?, ?, ?)
+`
+
+type CreateSessionParams struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	UserID      uuid.UUID `db:"user_id" json:"user_id"`
+	RefreshHash string    `db:"refresh_hash" json:"refresh_hash"`
+	ExpiresAt   time.Time `db:"expires_at" json:"expires_at"`
+}
+
+// Sessions
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) error {
+	_, err := q.db.ExecContext(ctx, createSession,
+		arg.ID,
+		arg.UserID,
+		arg.RefreshHash,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const createUser = `-- name: CreateUser :exec
+INSERT INTO users 
+    (id, login, password) 
+    VALUES 
+    (?, ?, ?)
+`
+
+type CreateUserParams struct {
+	ID       uuid.UUID      `db:"id" json:"id"`
+	Login    sql.NullString `db:"login" json:"login"`
+	Password sql.NullString `db:"password" json:"password"`
+}
+
+// User
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) error {
+	_, err := q.db.ExecContext(ctx, createUser, arg.ID, arg.Login, arg.Password)
+	return err
+}
+
+const deleteDeck = `-- name: DeleteDeck :execrows
+UPDATE decks SET deleted_at = ?
+    WHERE id = ? AND owner = ? AND deleted_at IS NULL
+`
+
+type DeleteDeckParams struct {
+	DeletedAt sql.NullTime `db:"deleted_at" json:"deleted_at"`
+	ID        uuid.UUID    `db:"id" json:"id"`
+	Owner     uuid.UUID    `db:"owner" json:"owner"`
+}
+
+func (q *Queries) DeleteDeck(ctx context.Context, arg DeleteDeckParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteDeck, arg.DeletedAt, arg.ID, arg.Owner)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteFlashcard = `-- name: DeleteFlashcard :execrows
+UPDATE flashcards SET deleted_at = ?
+    WHERE id = ? AND owner = ? AND deleted_at IS NULL
+`
+
+type DeleteFlashcardParams struct {
+	DeletedAt sql.NullTime `db:"deleted_at" json:"deleted_at"`
+	ID        uuid.UUID    `db:"id" json:"id"`
+	Owner     uuid.UUID    `db:"owner" json:"owner"`
+}
+
+func (q *Queries) DeleteFlashcard(ctx context.Context, arg DeleteFlashcardParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFlashcard, arg.DeletedAt, arg.ID, arg.Owner)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteFromDeck = `-- name: DeleteFromDeck :execrows
+DELETE FROM flashcard_decks
+    WHERE flashcard_id = ? AND
+        deck_id = ?
+`
+
+type DeleteFromDeckParams struct {
+	FlashcardID uuid.UUID `db:"flashcard_id" json:"flashcard_id"`
+	DeckID      uuid.UUID `db:"deck_id" json:"deck_id"`
+}
+
+func (q *Queries) DeleteFromDeck(ctx context.Context, arg DeleteFromDeckParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFromDeck, arg.FlashcardID, arg.DeckID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users 
+    WHERE id = ?
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
+	return err
+}
+
+const editDeckProps = `-- name: EditDeckProps :execrows
+UPDATE decks SET
+    name = ?,
+    version = version + 1
+    WHERE id = ? AND owner = ? AND version = ? AND deleted_at IS NULL
+`
+
+type EditDeckPropsParams struct {
+	Name    sql.NullString `db:"name" json:"name"`
+	ID      uuid.UUID      `db:"id" json:"id"`
+	Owner   uuid.UUID      `db:"owner" json:"owner"`
+	Version int64          `db:"version" json:"version"`
+}
+
+func (q *Queries) EditDeckProps(ctx context.Context, arg EditDeckPropsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, editDeckProps,
+		arg.Name,
+		arg.ID,
+		arg.Owner,
+		arg.Version,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const purgeDecks = `-- name: PurgeDecks :execrows
+DELETE FROM decks
+    WHERE deleted_at < ?
+`
+
+func (q *Queries) PurgeDecks(ctx context.Context, deletedAt sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDecks, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const purgeFlashcards = `-- name: PurgeFlashcards :execrows
+DELETE FROM flashcards
+    WHERE deleted_at < ?
+`
+
+func (q *Queries) PurgeFlashcards(ctx context.Context, deletedAt sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeFlashcards, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const requirePasswordReset = `-- name: RequirePasswordReset :execrows
+UPDATE users SET password_reset_required = true, password_reset_hash = ?
+    WHERE id = ?
+`
+
+type RequirePasswordResetParams struct {
+	PasswordResetHash string    `db:"password_reset_hash" json:"password_reset_hash"`
+	ID                uuid.UUID `db:"id" json:"id"`
+}
+
+func (q *Queries) RequirePasswordReset(ctx context.Context, arg RequirePasswordResetParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, requirePasswordReset, arg.PasswordResetHash, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreDeck = `-- name: RestoreDeck :execrows
+UPDATE decks SET deleted_at = NULL
+    WHERE id = ? AND owner = ? AND deleted_at IS NOT NULL
+`
+
+type RestoreDeckParams struct {
+	ID    uuid.UUID `db:"id" json:"id"`
+	Owner uuid.UUID `db:"owner" json:"owner"`
+}
+
+func (q *Queries) RestoreDeck(ctx context.Context, arg RestoreDeckParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restoreDeck, arg.ID, arg.Owner)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreFlashcard = `-- name: RestoreFlashcard :execrows
+UPDATE flashcards SET deleted_at = NULL
+    WHERE id = ? AND owner = ? AND deleted_at IS NOT NULL
+`
+
+type RestoreFlashcardParams struct {
+	ID    uuid.UUID `db:"id" json:"id"`
+	Owner uuid.UUID `db:"owner" json:"owner"`
+}
+
+func (q *Queries) RestoreFlashcard(ctx context.Context, arg RestoreFlashcardParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restoreFlashcard, arg.ID, arg.Owner)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const revokeSession = `-- name: RevokeSession :exec
+UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+    WHERE id = ? AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeSession(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, revokeSession, id)
+	return err
+}
+
+const revokeUserSessions = `-- name: RevokeUserSessions :exec
+UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+    WHERE user_id = ? AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, revokeUserSessions, userID)
+	return err
+}
+
+const rotateSession = `-- name: RotateSession :execrows
+UPDATE sessions SET refresh_hash = ?, expires_at = ?
+    WHERE id = ? AND refresh_hash = ? AND revoked_at IS NULL
+`
+
+type RotateSessionParams struct {
+	RefreshHash   string    `db:"refresh_hash" json:"refresh_hash"`
+	ExpiresAt     time.Time `db:"expires_at" json:"expires_at"`
+	ID            uuid.UUID `db:"id" json:"id"`
+	RefreshHash_2 string    `db:"refresh_hash_2" json:"refresh_hash_2"`
+}
+
+func (q *Queries) RotateSession(ctx context.Context, arg RotateSessionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rotateSession,
+		arg.RefreshHash,
+		arg.ExpiresAt,
+		arg.ID,
+		arg.RefreshHash_2,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const selectDeck = `-- name: SelectDeck :one
+SELECT id, name, owner, version, deleted_at FROM decks 
+    WHERE id = ? AND owner = ? AND deleted_at IS NULL
+`
+
+type SelectDeckParams struct {
+	ID    uuid.UUID `db:"id" json:"id"`
+	Owner uuid.UUID `db:"owner" json:"owner"`
+}
+
+func (q *Queries) SelectDeck(ctx context.Context, arg SelectDeckParams) (Deck, error) {
+	row := q.db.QueryRowContext(ctx, selectDeck, arg.ID, arg.Owner)
+	var i Deck
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Owner,
+		&i.Version,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const selectDeckFlashcards = `-- name: SelectDeckFlashcards :many
+SELECT f.id, f.word, f.meaning, f.usage, f.owner, f.created_at, f.updated_at, f.version, f.deleted_at FROM flashcards AS f
+    INNER JOIN flashcard_decks AS d
+        ON d.flashcard_id = f.id
+    WHERE d.deck_id = ? AND f.deleted_at IS NULL
+`
+
+func (q *Queries) SelectDeckFlashcards(ctx context.Context, deckID uuid.UUID) ([]Flashcard, error) {
+	rows, err := q.db.QueryContext(ctx, selectDeckFlashcards, deckID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Flashcard
+	for rows.Next() {
+		var i Flashcard
+		if err := rows.Scan(
+			&i.ID,
+			&i.Word,
+			&i.Meaning,
+			&i.Usage,
+			&i.Owner,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectDecksByName = `-- name: SelectDecksByName :many
+SELECT id, name, owner, version, deleted_at FROM decks
+    WHERE owner = ? AND name = ? AND deleted_at IS NULL
+`
+
+type SelectDecksByNameParams struct {
+	Owner uuid.UUID      `db:"owner" json:"owner"`
+	Name  sql.NullString `db:"name" json:"name"`
+}
+
+func (q *Queries) SelectDecksByName(ctx context.Context, arg SelectDecksByNameParams) ([]Deck, error) {
+	rows, err := q.db.QueryContext(ctx, selectDecksByName, arg.Owner, arg.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Deck
+	for rows.Next() {
+		var i Deck
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Owner,
+			&i.Version,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectDeletedDeck = `-- name: SelectDeletedDeck :one
+SELECT id, name, owner, version, deleted_at FROM decks
+    WHERE id = ? AND owner = ? AND deleted_at IS NOT NULL
+`
+
+type SelectDeletedDeckParams struct {
+	ID    uuid.UUID `db:"id" json:"id"`
+	Owner uuid.UUID `db:"owner" json:"owner"`
+}
+
+func (q *Queries) SelectDeletedDeck(ctx context.Context, arg SelectDeletedDeckParams) (Deck, error) {
+	row := q.db.QueryRowContext(ctx, selectDeletedDeck, arg.ID, arg.Owner)
+	var i Deck
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Owner,
+		&i.Version,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const selectDeletedDecks = `-- name: SelectDeletedDecks :many
+SELECT id, name, owner, version, deleted_at FROM decks
+    WHERE owner = ? AND deleted_at IS NOT NULL
+    ORDER BY deleted_at DESC, id
+`
+
+func (q *Queries) SelectDeletedDecks(ctx context.Context, owner uuid.UUID) ([]Deck, error) {
+	rows, err := q.db.QueryContext(ctx, selectDeletedDecks, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Deck
+	for rows.Next() {
+		var i Deck
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Owner,
+			&i.Version,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectDeletedFlashcard = `-- name: SelectDeletedFlashcard :one
+SELECT id, word, meaning, usage, owner, created_at, updated_at, version, deleted_at FROM flashcards
+    WHERE id = ? AND owner = ? AND deleted_at IS NOT NULL
+`
+
+type SelectDeletedFlashcardParams struct {
+	ID    uuid.UUID `db:"id" json:"id"`
+	Owner uuid.UUID `db:"owner" json:"owner"`
+}
+
+func (q *Queries) SelectDeletedFlashcard(ctx context.Context, arg SelectDeletedFlashcardParams) (Flashcard, error) {
+	row := q.db.QueryRowContext(ctx, selectDeletedFlashcard, arg.ID, arg.Owner)
+	var i Flashcard
+	err := row.Scan(
+		&i.ID,
+		&i.Word,
+		&i.Meaning,
+		&i.Usage,
+		&i.Owner,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const selectDeletedFlashcards = `-- name: SelectDeletedFlashcards :many
+SELECT id, word, meaning, usage, owner, created_at, updated_at, version, deleted_at FROM flashcards
+    WHERE owner = ? AND deleted_at IS NOT NULL
+    ORDER BY deleted_at DESC, id
+`
+
+func (q *Queries) SelectDeletedFlashcards(ctx context.Context, owner uuid.UUID) ([]Flashcard, error) {
+	rows, err := q.db.QueryContext(ctx, selectDeletedFlashcards, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Flashcard
+	for rows.Next() {
+		var i Flashcard
+		if err := rows.Scan(
+			&i.ID,
+			&i.Word,
+			&i.Meaning,
+			&i.Usage,
+			&i.Owner,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectDueReviews = `-- name: SelectDueReviews :many
+SELECT r.user_id, r.flashcard_id, r.ease_factor, r.interval_days, r.repetitions, r.lapses, r.due_at, r.last_reviewed_at, f.word, f.meaning, f.usage FROM reviews AS r
+    INNER JOIN flashcards AS f
+        ON f.id = r.flashcard_id
+    WHERE r.user_id = ? AND r.due_at <= ? AND f.deleted_at IS NULL
+    ORDER BY r.due_at
+    LIMIT ?
+`
+
+type SelectDueReviewsParams struct {
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	DueAt  time.Time `db:"due_at" json:"due_at"`
+	Limit  int64     `db:"limit" json:"limit"`
+}
+
+type SelectDueReviewsRow struct {
+	UserID         uuid.UUID      `db:"user_id" json:"user_id"`
+	FlashcardID    uuid.UUID      `db:"flashcard_id" json:"flashcard_id"`
+	EaseFactor     float64        `db:"ease_factor" json:"ease_factor"`
+	IntervalDays   int64          `db:"interval_days" json:"interval_days"`
+	Repetitions    int64          `db:"repetitions" json:"repetitions"`
+	Lapses         int64          `db:"lapses" json:"lapses"`
+	DueAt          time.Time      `db:"due_at" json:"due_at"`
+	LastReviewedAt sql.NullTime   `db:"last_reviewed_at" json:"last_reviewed_at"`
+	Word           sql.NullString `db:"word" json:"word"`
+	Meaning        sql.NullString `db:"meaning" json:"meaning"`
+	Usage          sql.NullString `db:"usage" json:"usage"`
+}
+
+func (q *Queries) SelectDueReviews(ctx context.Context, arg SelectDueReviewsParams) ([]SelectDueReviewsRow, error) {
+	rows, err := q.db.QueryContext(ctx, selectDueReviews, arg.UserID, arg.DueAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectDueReviewsRow
+	for rows.Next() {
+		var i SelectDueReviewsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.FlashcardID,
+			&i.EaseFactor,
+			&i.IntervalDays,
+			&i.Repetitions,
+			&i.Lapses,
+			&i.DueAt,
+			&i.LastReviewedAt,
+			&i.Word,
+			&i.Meaning,
+			&i.Usage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectFlashcardByID = `-- name: SelectFlashcardByID :one
+SELECT id, word, meaning, usage, owner, created_at, updated_at, version, deleted_at FROM flashcards 
+    WHERE id = ? AND owner = ? AND deleted_at IS NULL
+`
+
+type SelectFlashcardByIDParams struct {
+	ID    uuid.UUID `db:"id" json:"id"`
+	Owner uuid.UUID `db:"owner" json:"owner"`
+}
+
+func (q *Queries) SelectFlashcardByID(ctx context.Context, arg SelectFlashcardByIDParams) (Flashcard, error) {
+	row := q.db.QueryRowContext(ctx, selectFlashcardByID, arg.ID, arg.Owner)
+	var i Flashcard
+	err := row.Scan(
+		&i.ID,
+		&i.Word,
+		&i.Meaning,
+		&i.Usage,
+		&i.Owner,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const selectFlashcardByMeaning = `-- name: SelectFlashcardByMeaning :many
+SELECT f.id, f.word, f.meaning, f.usage, f.owner, f.created_at, f.updated_at, f.version, f.deleted_at FROM flashcards AS f
+    INNER JOIN flashcard_decks AS d
+        ON d.flashcard_id = f.id
+    WHERE d.deck_id = ? AND f.owner = ? AND f.meaning = ? AND f.deleted_at IS NULL
+`
+
+type SelectFlashcardByMeaningParams struct {
+	DeckID  uuid.UUID      `db:"deck_id" json:"deck_id"`
+	Owner   uuid.UUID      `db:"owner" json:"owner"`
+	Meaning sql.NullString `db:"meaning" json:"meaning"`
+}
+
+func (q *Queries) SelectFlashcardByMeaning(ctx context.Context, arg SelectFlashcardByMeaningParams) ([]Flashcard, error) {
+	rows, err := q.db.QueryContext(ctx, selectFlashcardByMeaning, arg.DeckID, arg.Owner, arg.Meaning)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Flashcard
+	for rows.Next() {
+		var i Flashcard
+		if err := rows.Scan(
+			&i.ID,
+			&i.Word,
+			&i.Meaning,
+			&i.Usage,
+			&i.Owner,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectFlashcardByWord = `-- name: SelectFlashcardByWord :many
+SELECT f.id, f.word, f.meaning, f.usage, f.owner, f.created_at, f.updated_at, f.version, f.deleted_at FROM flashcards AS f
+    INNER JOIN flashcard_decks AS d
+        ON d.flashcard_id = f.id
+    WHERE d.deck_id = ? AND f.owner = ? AND f.word = ? AND f.deleted_at IS NULL
+`
+
+type SelectFlashcardByWordParams struct {
+	DeckID uuid.UUID      `db:"deck_id" json:"deck_id"`
+	Owner  uuid.UUID      `db:"owner" json:"owner"`
+	Word   sql.NullString `db:"word" json:"word"`
+}
+
+func (q *Queries) SelectFlashcardByWord(ctx context.Context, arg SelectFlashcardByWordParams) ([]Flashcard, error) {
+	rows, err := q.db.QueryContext(ctx, selectFlashcardByWord, arg.DeckID, arg.Owner, arg.Word)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Flashcard
+	for rows.Next() {
+		var i Flashcard
+		if err := rows.Scan(
+			&i.ID,
+			&i.Word,
+			&i.Meaning,
+			&i.Usage,
+			&i.Owner,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectOwnerDecks = `-- name: SelectOwnerDecks :many
+SELECT id, name, owner, version, deleted_at FROM decks
+    WHERE owner = ? AND deleted_at IS NULL
+    ORDER BY name
+`
+
+func (q *Queries) SelectOwnerDecks(ctx context.Context, owner uuid.UUID) ([]Deck, error) {
+	rows, err := q.db.QueryContext(ctx, selectOwnerDecks, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Deck
+	for rows.Next() {
+		var i Deck
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Owner,
+			&i.Version,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectReview = `-- name: SelectReview :one
+SELECT user_id, flashcard_id, ease_factor, interval_days, repetitions, lapses, due_at, last_reviewed_at FROM reviews
+    WHERE user_id = ? AND flashcard_id = ?
+`
+
+type SelectReviewParams struct {
+	UserID      uuid.UUID `db:"user_id" json:"user_id"`
+	FlashcardID uuid.UUID `db:"flashcard_id" json:"flashcard_id"`
+}
+
+// Reviews
+func (q *Queries) SelectReview(ctx context.Context, arg SelectReviewParams) (Review, error) {
+	row := q.db.QueryRowContext(ctx, selectReview, arg.UserID, arg.FlashcardID)
+	var i Review
+	err := row.Scan(
+		&i.UserID,
+		&i.FlashcardID,
+		&i.EaseFactor,
+		&i.IntervalDays,
+		&i.Repetitions,
+		&i.Lapses,
+		&i.DueAt,
+		&i.LastReviewedAt,
+	)
+	return i, err
+}
+
+const selectRevision = `-- name: SelectRevision :one
+SELECT id, entity_type, entity_id, owner, version, "action", actor_id, request_id, changes, snapshot, created_at FROM revisions
+    WHERE id = ? AND entity_type = ? AND entity_id = ? AND owner = ?
+`
+
+type SelectRevisionParams struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	EntityType string    `db:"entity_type" json:"entity_type"`
+	EntityID   uuid.UUID `db:"entity_id" json:"entity_id"`
+	Owner      uuid.UUID `db:"owner" json:"owner"`
+}
+
+func (q *Queries) SelectRevision(ctx context.Context, arg SelectRevisionParams) (Revision, error) {
+	row := q.db.QueryRowContext(ctx, selectRevision,
+		arg.ID,
+		arg.EntityType,
+		arg.EntityID,
+		arg.Owner,
+	)
+	var i Revision
+	err := row.Scan(
+		&i.ID,
+		&i.EntityType,
+		&i.EntityID,
+		&i.Owner,
+		&i.Version,
+		&i.Action,
+		&i.ActorID,
+		&i.RequestID,
+		&i.Changes,
+		&i.Snapshot,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const selectRevisions = `-- name: SelectRevisions :many
+SELECT id, entity_type, entity_id, owner, version, "action", actor_id, request_id, changes, snapshot, created_at FROM revisions
+    WHERE entity_type = ? AND entity_id = ? AND owner = ?
+    ORDER BY created_at, version
+`
+
+type SelectRevisionsParams struct {
+	EntityType string    `db:"entity_type" json:"entity_type"`
+	EntityID   uuid.UUID `db:"entity_id" json:"entity_id"`
+	Owner      uuid.UUID `db:"owner" json:"owner"`
+}
+
+func (q *Queries) SelectRevisions(ctx context.Context, arg SelectRevisionsParams) ([]Revision, error) {
+	rows, err := q.db.QueryContext(ctx, selectRevisions, arg.EntityType, arg.EntityID, arg.Owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Revision
+	for rows.Next() {
+		var i Revision
+		if err := rows.Scan(
+			&i.ID,
+			&i.EntityType,
+			&i.EntityID,
+			&i.Owner,
+			&i.Version,
+			&i.Action,
+			&i.ActorID,
+			&i.RequestID,
+			&i.Changes,
+			&i.Snapshot,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectSession = `-- name: SelectSession :one
+SELECT id, user_id, refresh_hash, created_at, expires_at, revoked_at FROM sessions
+    WHERE id = ?
+`
+
+func (q *Queries) SelectSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	row := q.db.QueryRowContext(ctx, selectSession, id)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RefreshHash,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const selectUserByID = `-- name: SelectUserByID :one
+SELECT id, login, password, role, disabled_at, password_reset_required, password_reset_hash FROM users 
+    WHERE id = ?
+`
+
+func (q *Queries) SelectUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, selectUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Login,
+		&i.Password,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
+		&i.PasswordResetHash,
+	)
+	return i, err
+}
+
+const selectUserByLogin = `-- name: SelectUserByLogin :one
+SELECT id, login, password, role, disabled_at, password_reset_required, password_reset_hash FROM users 
+    WHERE login = ?
+`
+
+func (q *Queries) SelectUserByLogin(ctx context.Context, login sql.NullString) (User, error) {
+	row := q.db.QueryRowContext(ctx, selectUserByLogin, login)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Login,
+		&i.Password,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
+		&i.PasswordResetHash,
+	)
+	return i, err
+}
+
+const selectUsers = `-- name: SelectUsers :many
+SELECT id, login, password, role, disabled_at, password_reset_required, password_reset_hash FROM users
+    ORDER BY login
+    LIMIT ? OFFSET ?
+`
+
+type SelectUsersParams struct {
+	Limit  int64 `db:"limit" json:"limit"`
+	Offset int64 `db:"offset" json:"offset"`
+}
+
+func (q *Queries) SelectUsers(ctx context.Context, arg SelectUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, selectUsers, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Login,
+			&i.Password,
+			&i.Role,
+			&i.DisabledAt,
+			&i.PasswordResetRequired,
+			&i.PasswordResetHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateFlashcard = `-- name: UpdateFlashcard :execrows
+UPDATE flashcards SET
+    word = ?,
+    meaning = ?,
+    usage = ?,
+    updated_at = ?,
+    version = version + 1
+    WHERE id = ? AND owner = ? AND version = ? AND deleted_at IS NULL
+`
+
+type UpdateFlashcardParams struct {
+	Word      sql.NullString `db:"word" json:"word"`
+	Meaning   sql.NullString `db:"meaning" json:"meaning"`
+	Usage     sql.NullString `db:"usage" json:"usage"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+	ID        uuid.UUID      `db:"id" json:"id"`
+	Owner     uuid.UUID      `db:"owner" json:"owner"`
+	Version   int64          `db:"version" json:"version"`
+}
+
+func (q *Queries) UpdateFlashcard(ctx context.Context, arg UpdateFlashcardParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateFlashcard,
+		arg.Word,
+		arg.Meaning,
+		arg.Usage,
+		arg.UpdatedAt,
+		arg.ID,
+		arg.Owner,
+		arg.Version,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateUserDisabled = `-- name: UpdateUserDisabled :execrows
+UPDATE users SET disabled_at = ?
+    WHERE id = ?
+`
+
+type UpdateUserDisabledParams struct {
+	DisabledAt sql.NullTime `db:"disabled_at" json:"disabled_at"`
+	ID         uuid.UUID    `db:"id" json:"id"`
+}
+
+func (q *Queries) UpdateUserDisabled(ctx context.Context, arg UpdateUserDisabledParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserDisabled, arg.DisabledAt, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateUserLogin = `-- name: UpdateUserLogin :execrows
+UPDATE users SET login = ?
+    WHERE id = ?
+`
+
+type UpdateUserLoginParams struct {
+	Login sql.NullString `db:"login" json:"login"`
+	ID    uuid.UUID      `db:"id" json:"id"`
+}
+
+func (q *Queries) UpdateUserLogin(ctx context.Context, arg UpdateUserLoginParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserLogin, arg.Login, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :execrows
+UPDATE users SET password = ?, password_reset_required = false, password_reset_hash = ''
+    WHERE id = ?
+`
+
+type UpdateUserPasswordParams struct {
+	Password sql.NullString `db:"password" json:"password"`
+	ID       uuid.UUID      `db:"id" json:"id"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserPassword, arg.Password, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateUserRole = `-- name: UpdateUserRole :execrows
+UPDATE users SET role = ?
+    WHERE id = ?
+`
+
+type UpdateUserRoleParams struct {
+	Role string    `db:"role" json:"role"`
+	ID   uuid.UUID `db:"id" json:"id"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserRole, arg.Role, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertReview = `-- name: UpsertReview :exec
+INSERT INTO reviews
+    (user_id, flashcard_id, ease_factor, interval_days, repetitions, lapses, due_at, last_reviewed_at)
+    VALUES
+    (?, ?, ?, ?, ?, ?, ?, ?)
+    ON CONFLICT (user_id, flashcard_id) DO UPDATE SET
+        ease_factor = excluded.ease_factor,
+        interval_days = excluded.interval_days,
+        repetitions = excluded.repetitions,
+        lapses = excluded.lapses,
+        due_at = excluded.due_at,
+        last_reviewed_at = excluded.last_reviewed_at
+`
+
+type UpsertReviewParams struct {
+	UserID         uuid.UUID    `db:"user_id" json:"user_id"`
+	FlashcardID    uuid.UUID    `db:"flashcard_id" json:"flashcard_id"`
+	EaseFactor     float64      `db:"ease_factor" json:"ease_factor"`
+	IntervalDays   int64        `db:"interval_days" json:"interval_days"`
+	Repetitions    int64        `db:"repetitions" json:"repetitions"`
+	Lapses         int64        `db:"lapses" json:"lapses"`
+	DueAt          time.Time    `db:"due_at" json:"due_at"`
+	LastReviewedAt sql.NullTime `db:"last_reviewed_at" json:"last_reviewed_at"`
+}
+
+func (q *Queries) UpsertReview(ctx context.Context, arg UpsertReviewParams) error {
+	_, err := q.db.ExecContext(ctx, upsertReview,
+		arg.UserID,
+		arg.FlashcardID,
+		arg.EaseFactor,
+		arg.IntervalDays,
+		arg.Repetitions,
+		arg.Lapses,
+		arg.DueAt,
+		arg.LastReviewedAt,
+	)
+	return err
+}
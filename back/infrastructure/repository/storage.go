@@ -3,10 +3,15 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"languago/pkg/changes"
+	"sync"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
 type (
@@ -16,6 +21,7 @@ type (
 		GetUser() string
 		GetSecret() string
 		GetDBName() string
+		GetPath() string
 		SetSSLMode(b bool)
 		GetSSLMode() string
 	}
@@ -23,6 +29,7 @@ type (
 	DBCred struct {
 		DbAddress string
 		DBName    string
+		Path      string
 		SSLMode   string
 		Driver    string
 		User      string
@@ -34,18 +41,38 @@ type (
 		Database() Storage
 		CloseConnection() error
 		DDCredentials() DBCredentials
+		Migrator() Migrator
+		// WithTx runs fn as one unit of work, everything fn does with the given
+		// Storage is committed together or not at all.
+		WithTx(ctx context.Context, fn func(Storage) error) error
+		// Changes is the bus the writes are announced on, it reaches the
+		// other nodes only for PostgreSQL. It is opened on the first call,
+		// so a node without a cache doesn't keep a listener connection.
+		Changes() changes.Bus
 	}
 
 	databaseInteractor struct {
-		DB     Storage
-		DBCred DBCredentials
+		DB       Storage
+		DBCred   DBCredentials
+		migrator Migrator
+
+		// bus is opened with openBus the first time Changes is called,
+		// busMu guards it
+		busMu   sync.Mutex
+		bus     changes.Bus
+		openBus func() changes.Bus
 	}
 )
 
+// changesChannel is the PostgreSQL channel the changes are sent on
+const changesChannel = "languago_changes"
+
 func NewDatabaseInteractor(cfg abstractDatabaseConfig) (DatabaseInteractor, error) {
 	if cfg.IsMock() {
 		mock := &databaseInteractor{
-			DB: _newMockStorage(),
+			DB:       newMemoryStorage(),
+			migrator: nopMigrator{},
+			openBus:  changes.NewLocalBus,
 		}
 		return mock, nil
 	}
@@ -66,10 +93,26 @@ func NewDatabaseInteractor(cfg abstractDatabaseConfig) (DatabaseInteractor, erro
 		interactor.DB = newPGStorage(database)
 	} else if driver == "mysql" {
 		interactor.DB = newMySQLStorage(database)
+	} else if driver == "sqlite" {
+		interactor.DB = newSQLiteStorage(database)
 	} else {
 		return nil, fmt.Errorf("error invalid driver %s", driver)
 	}
 
+	migrator, err := newMigrator(database, driver)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing database interactor: %w", err)
+	}
+
+	// only PostgreSQL can tell the other nodes about the changes
+	interactor.openBus = changes.NewLocalBus
+	if driver == "postgres" {
+		interactor.openBus = func() changes.Bus {
+			return changes.NewPostgresBus(database, postgresDSN(cred), changesChannel)
+		}
+	}
+
+	interactor.migrator = migrator
 	interactor.DBCred = cred
 	return &interactor, nil
 }
@@ -83,21 +126,29 @@ func databaseConnection(c DBCredentials) (*sql.DB, error) {
 	)
 	switch c.GetDriver() {
 	case "postgres":
-		connStr = fmt.Sprintf("postgresql://%s:%s@%s/%s?sslmode=%s",
-			c.GetUser(), c.GetSecret(), c.GetAddress(), c.GetDBName(), c.GetSSLMode())
+		connStr = postgresDSN(c)
 
 		db, err = sql.Open(c.GetDriver(), connStr)
 		if err != nil {
 			return nil, fmt.Errorf("error connecting to database: %w", handleError(err))
 		}
 	case "mysql":
-		connStr = fmt.Sprintf("mysql://%s:%s@%s/%s",
-			c.GetUser(), c.GetSecret(), c.GetAddress(), c.GetDBName())
+		connStr = mysqlDSN(c)
 
 		db, err = sql.Open(c.GetDriver(), connStr)
 		if err != nil {
 			return nil, fmt.Errorf("error connecting to database: %w", handleError(err))
 		}
+	case "sqlite":
+		db, err = sql.Open(c.GetDriver(), sqliteDSN(c))
+		if err != nil {
+			return nil, fmt.Errorf("error connecting to database: %w", handleError(err))
+		}
+
+		// SQLite takes one writer at a time, a single connection queues the
+		// writes instead of failing them with SQLITE_BUSY. It also keeps a
+		// ":memory:" database alive, each connection would open its own.
+		db.SetMaxOpenConns(1)
 	default:
 		return nil, fmt.Errorf("error connecting to database. unknown driver.")
 	}
@@ -105,6 +156,49 @@ func databaseConnection(c DBCredentials) (*sql.DB, error) {
 	return db, nil
 }
 
+// postgresDSN builds the lib/pq connection URL, the change bus opens a
+// connection of its own with it.
+func postgresDSN(c DBCredentials) string {
+	return fmt.Sprintf("postgresql://%s:%s@%s/%s?sslmode=%s",
+		c.GetUser(), c.GetSecret(), c.GetAddress(), c.GetDBName(), c.GetSSLMode())
+}
+
+// mysqlDSN builds the go-sql-driver DSN, user:secret@tcp(address)/dbname?params.
+// Times are kept in UTC on both sides and affected rows count matched rows, as
+// in PostgreSQL, so updates that change nothing don't look like missing rows.
+func mysqlDSN(c DBCredentials) string {
+	cfg := mysql.NewConfig()
+	cfg.User = c.GetUser()
+	cfg.Passwd = c.GetSecret()
+	cfg.Net = "tcp"
+	cfg.Addr = c.GetAddress()
+	cfg.DBName = c.GetDBName()
+	cfg.ParseTime = true
+	cfg.Loc = time.UTC
+	cfg.ClientFoundRows = true
+	cfg.Collation = "utf8mb4_unicode_ci"
+	cfg.Params = map[string]string{
+		"time_zone": "'+00:00'",
+	}
+
+	if c.GetSSLMode() == "enabled" {
+		cfg.TLSConfig = "true"
+	}
+
+	return cfg.FormatDSN()
+}
+
+// sqliteDSN builds the modernc.org/sqlite DSN for the database file. Foreign
+// keys are off in SQLite by default and have to be enabled per connection.
+// Times are written in the format SQLite date functions understand.
+func sqliteDSN(c DBCredentials) string {
+	return "file:" + c.GetPath() +
+		"?_pragma=foreign_keys(1)" +
+		"&_pragma=busy_timeout(5000)" +
+		"&_pragma=journal_mode(WAL)" +
+		"&_time_format=sqlite"
+}
+
 // Relational database impl
 func (c *DBCred) GetAddress() string {
 	return c.DbAddress
@@ -126,6 +220,10 @@ func (c *DBCred) GetDBName() string {
 	return c.DBName
 }
 
+func (c *DBCred) GetPath() string {
+	return c.Path
+}
+
 func (c *DBCred) SetSSLMode(b bool) {
 	if b {
 		c.SSLMode = "enabled"
@@ -146,22 +244,31 @@ func (d *databaseInteractor) DDCredentials() DBCredentials {
 	return d.DBCred
 }
 
-func (d *databaseInteractor) CloseConnection() error {
-	var err error
+func (d *databaseInteractor) Migrator() Migrator {
+	return d.migrator
+}
 
-	ctx, timeoutCloser := context.WithTimeout(context.Background(), 15*time.Second)
-	defer timeoutCloser()
+func (d *databaseInteractor) Changes() changes.Bus {
+	d.busMu.Lock()
+	defer d.busMu.Unlock()
 
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	if d.bus == nil {
+		d.bus = d.openBus()
+	}
+	return d.bus
+}
 
-	go func() {
-		time.Sleep(30 * time.Second)
-		err = fmt.Errorf("error closing connection to database. Aborting closer due to timeout")
-		cancel()
-	}()
+// CloseConnection stops the change bus if it was opened and closes the
+// connections of the pool, queries in flight are waited for.
+func (d *databaseInteractor) CloseConnection() error {
+	d.busMu.Lock()
+	bus := d.bus
+	d.busMu.Unlock()
 
-	err = d.DB.Close()
+	var err error
+	if bus != nil {
+		err = bus.Close()
+	}
 
-	return err
+	return errors.Join(err, d.DB.Close())
 }
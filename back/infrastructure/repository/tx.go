@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"languago/infrastructure/repository/database"
+	"languago/infrastructure/repository/mysql"
+	"languago/infrastructure/repository/postgresql"
+	"languago/infrastructure/repository/sqlite"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// A transaction is shared by the sqlc queries and the squirrel builders, so
+// both of them have to accept it.
+var (
+	_ postgresql.DBTX = (*sql.Tx)(nil)
+	_ mysql.DBTX      = (*sql.Tx)(nil)
+	_ sqlite.DBTX     = (*sql.Tx)(nil)
+	_ database.DBTX   = (*sql.Tx)(nil)
+)
+
+// transactor is implemented by the storages, withTx runs fn on a Storage bound
+// to one transaction.
+type transactor interface {
+	withTx(ctx context.Context, fn func(Storage) error) error
+}
+
+// WithTx runs fn in a transaction, it is committed if fn returns nil and rolled
+// back otherwise, also if fn panics. fn must only use the Storage it is given,
+// the storage of the interactor may be blocked until the transaction ends.
+func (d *databaseInteractor) WithTx(ctx context.Context, fn func(Storage) error) error {
+	t, ok := d.DB.(transactor)
+	if !ok {
+		return fmt.Errorf("error storage %T doesn't support transactions", d.DB)
+	}
+
+	return t.withTx(ctx, fn)
+}
+
+// runTx begins a transaction on conn and ends it depending on the result of fn.
+// The error of fn is returned as it is, so the callers can still match it.
+func runTx(ctx context.Context, conn *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error begin transaction: %w", handleError(err))
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("error rollback transaction: %w", rbErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error commit transaction: %w", handleError(err))
+	}
+
+	return nil
+}
+
+func (s *sqlStorage) withTx(ctx context.Context, fn func(Storage) error) error {
+	return s.atomic(ctx, func(s *sqlStorage) error {
+		return fn(s)
+	})
+}
+
+// atomic runs fn on a storage bound to a transaction, a storage which is
+// already bound runs it in the running transaction. Storage methods making more
+// than one change use it.
+func (s *sqlStorage) atomic(ctx context.Context, fn func(s *sqlStorage) error) error {
+	if s.tx != nil {
+		return fn(s)
+	}
+
+	return runTx(ctx, s.conn, func(tx *sql.Tx) error {
+		return fn(&sqlStorage{
+			conn:    s.conn,
+			tx:      tx,
+			q:       s.q.withTx(tx),
+			dynamic: s.dynamic.WithTx(tx),
+		})
+	})
+}
+
+// withTx runs fn on a copy of the data and swaps it in if fn succeeds. The
+// storage stays locked meanwhile, so transactions are serialized.
+func (s *memoryStorage) withTx(ctx context.Context, fn func(Storage) error) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("error begin transaction: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := s.clone()
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("error commit transaction: %w", err)
+	}
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	s.users = tx.users
+	s.flashcards = tx.flashcards
+	s.decks = tx.decks
+	s.deckCards = tx.deckCards
+	s.reviews = tx.reviews
+	s.sessions = tx.sessions
+	s.revisions = tx.revisions
+
+	return nil
+}
+
+// clone copies the data, the caller holds the lock. Stored entities are never
+// changed in place, so copying the maps is enough.
+func (s *memoryStorage) clone() *memoryStorage {
+	c := newMemoryStorage()
+
+	for k, v := range s.users {
+		c.users[k] = v
+	}
+	for k, v := range s.flashcards {
+		c.flashcards[k] = v
+	}
+	for k, v := range s.decks {
+		c.decks[k] = v
+	}
+	for k, cards := range s.deckCards {
+		c.deckCards[k] = make(map[uuid.UUID]struct{}, len(cards))
+		for id := range cards {
+			c.deckCards[k][id] = struct{}{}
+		}
+	}
+	for k, v := range s.reviews {
+		c.reviews[k] = v
+	}
+	for k, v := range s.sessions {
+		c.sessions[k] = v
+	}
+	c.revisions = slices.Clone(s.revisions)
+
+	return c
+}
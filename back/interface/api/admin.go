@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"languago/pkg/models/requests/rest"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (a *API) listUsersHandler(w http.ResponseWriter, r *http.Request) {
+	limit, ok := a.queryInt(w, r, "limit")
+	if !ok {
+		return
+	}
+
+	offset, ok := a.queryInt(w, r, "offset")
+	if !ok {
+		return
+	}
+
+	req := &rest.ListUsersRequest{
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	response, err := a.adminController.ListUsers(ctx, req)
+	if err != nil {
+		a.writeError(w, "error select users", err)
+		return
+	}
+
+	a.writeJSON(w, http.StatusOK, response)
+}
+
+func (a *API) disableUserHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := a.userID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	if err := a.adminController.DisableUser(ctx, userID); err != nil {
+		a.writeError(w, "error disable user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) enableUserHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := a.userID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	if err := a.adminController.EnableUser(ctx, userID); err != nil {
+		a.writeError(w, "error enable user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) passwordResetHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := a.userID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	response, err := a.adminController.ForcePasswordReset(ctx, userID)
+	if err != nil {
+		a.writeError(w, "error require password reset", err)
+		return
+	}
+
+	a.writeJSON(w, http.StatusOK, response)
+}
+
+func (a *API) setRoleHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := a.userID(w, r)
+	if !ok {
+		return
+	}
+
+	req := new(rest.SetRoleRequest)
+	if !a.bindRequest(w, r, req) {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	if err := a.adminController.SetRole(ctx, userID, req); err != nil {
+		a.writeError(w, "error set role", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) userID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(a.responseError("error parse user id", err, http.StatusBadRequest))
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// queryInt returns the integer query parameter, 0 when it is missing.
+func (a *API) queryInt(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, true
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(a.responseError("error parse "+name, err, http.StatusBadRequest))
+		return 0, false
+	}
+
+	return n, true
+}
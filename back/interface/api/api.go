@@ -2,20 +2,24 @@ package api
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"languago/infrastructure/config"
 	"languago/infrastructure/logger"
 	"languago/infrastructure/repository"
 	"languago/pkg/auth"
+	"languago/pkg/controllers/admin"
+	"languago/pkg/controllers/decks"
 	"languago/pkg/controllers/flashcards"
+	"languago/pkg/controllers/reviews"
+	"languago/pkg/controllers/trash"
 	"languago/pkg/controllers/users"
 	errors2 "languago/pkg/errors"
 	"languago/pkg/http/middleware"
+	"languago/pkg/models"
 	"languago/pkg/models/requests/rest"
-	"os"
 
 	"net/http"
 	"time"
@@ -37,18 +41,40 @@ type (
 		errorsPresenter      errors2.ErrorsPersenter
 		usersController      users.UsersController
 		flashcardsController flashcards.FlashcardsController
+		reviewsController    reviews.ReviewsController
+		decksController      decks.DecksController
+		adminController      admin.AdminController
+		trashController      trash.TrashController
+		authorizer           auth.Authorizer
 	}
 )
 
-func NewAPI(cfg config.AbstractLoggerConfig, interactor repository.DatabaseInteractor) *API {
+func NewAPI(
+	cfg config.AbstractLoggerConfig,
+	authCfg config.AbstractAuthConfig,
+	interactor repository.DatabaseInteractor,
+) (*API, error) {
 	logger := logger.ProvideLogger(cfg)
 	errorsPresenter := errors2.NewErrorPresenter(logger)
 
+	keys, err := auth.NewKeyring(authCfg)
+	if err != nil {
+		return nil, fmt.Errorf("error init signing keys: %w", err)
+	}
+
+	authorizer := auth.NewAuthorizer(
+		logger,
+		interactor.Database(),
+		keys,
+		authCfg,
+	)
+
 	api := API{
 		ID:              uuid.New(),
 		Repo:            interactor,
 		log:             logger,
 		errorsPresenter: errorsPresenter,
+		authorizer:      authorizer,
 		flashcardsController: flashcards.NewFlashcardsController(
 			logger,
 			interactor,
@@ -56,20 +82,34 @@ func NewAPI(cfg config.AbstractLoggerConfig, interactor repository.DatabaseInter
 		usersController: users.NewUsersController(
 			logger,
 			interactor,
+			authorizer,
+			auth.NewPasswordHasher(auth.DefaultPasswordParams),
+		),
+		reviewsController: reviews.NewReviewsController(
+			logger,
+			interactor,
+		),
+		decksController: decks.NewDecksController(
+			logger,
+			interactor,
+		),
+		adminController: admin.NewAdminController(
+			logger,
+			interactor,
+		),
+		trashController: trash.NewTrashController(
+			logger,
+			interactor,
 		),
 	}
 
 	router := chi.NewRouter()
 
-	mw := middleware.NewMiddleware(api.log, auth.NewAuthorizer(
-		logger,
-		interactor.Database(),
-		[]byte(os.Getenv("LANGUAGO_SECRET")),
-	))
+	mw := middleware.NewMiddleware(api.log, authorizer)
 
 	router.Use(cors.Handler(cors.Options{
 		AllowedOrigins: []string{"*"},
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders: []string{
 			"X-PINGOTHER",
 			"Accept",
@@ -79,9 +119,10 @@ func NewAPI(cfg config.AbstractLoggerConfig, interactor repository.DatabaseInter
 			"X-Requested-With",
 			"Cache-Control",
 			"Connection",
+			"If-Match",
 		},
 		OptionsPassthrough: true,
-		ExposedHeaders:     []string{"Link"},
+		ExposedHeaders:     []string{"Link", "ETag"},
 		AllowCredentials:   true,
 		MaxAge:             300, // Maximum value not ignored by any of major browsers
 	}))
@@ -90,20 +131,66 @@ func NewAPI(cfg config.AbstractLoggerConfig, interactor repository.DatabaseInter
 	//router.Use(mw.Options)
 	router.Use(mw.LoggingMiddleware)
 	router.Use(mw.Recovery)
-	router.Use(mw.AuthMiddleware)
 
-	router.Post("/signup", api.signUpHandler)
+	// public routes
+	router.Group(func(r chi.Router) {
+		r.Post("/signup", api.signUpHandler)
+		r.Post("/signin", api.signInHandler)
+		r.Post("/signout", api.signOutHandler)
+		r.Post("/token/refresh", api.refreshTokenHandler)
+		r.Post("/password/change", api.changePasswordHandler)
+
+		r.Get("/randomword", api.randomWordHandler)
+
+		r.Get("/.well-known/jwks.json", api.jwksHandler)
+	})
+
+	router.Group(func(r chi.Router) {
+		r.Use(mw.AuthMiddleware)
+
+		r.Get("/flashcard", api.getFlashcardHandler)
+		r.Post("/flashcard", api.newFlashcardHandler)
+		r.Delete("/flashcard", api.deleteFlashcardHandler)
+		r.Put("/flashcard", api.editFlashcardHandler)
+		r.Get("/flashcards", api.listFlashcardsHandler)
+		r.Patch("/flashcards/{cardID}", api.patchFlashcardHandler)
+		r.Get("/flashcards/{cardID}/history", api.flashcardHistoryHandler)
+		r.Post("/flashcards/{cardID}/history/{revisionID}/revert", api.revertFlashcardHandler)
+		r.Get("/search", api.searchHandler)
+
+		r.Route("/decks", func(r chi.Router) {
+			r.Get("/", api.listDecksHandler)
+			r.Post("/", api.newDeckHandler)
+			r.Get("/{deckID}", api.getDeckHandler)
+			r.Put("/{deckID}", api.editDeckHandler)
+			r.Delete("/{deckID}", api.deleteDeckHandler)
+			r.Post("/{deckID}/flashcards/{cardID}", api.addToDeckHandler)
+			r.Delete("/{deckID}/flashcards/{cardID}", api.deleteFromDeckHandler)
+		})
+
+		r.Route("/trash", func(r chi.Router) {
+			r.Get("/", api.listTrashHandler)
+			r.Post("/flashcards/{cardID}/restore", api.restoreFlashcardHandler)
+			r.Post("/decks/{deckID}/restore", api.restoreDeckHandler)
+		})
 
-	router.Get("/randomword", api.randomWordHandler)
+		r.Get("/review/due", api.dueReviewsHandler)
+		r.Post("/review/{cardID}", api.reviewFlashcardHandler)
 
-	router.Get("/flashcard", api.getFlashcardHandler)
-	router.Post("/flashcard", api.newFlashcardHandler)
-	router.Delete("/flashcard", api.deleteFlashcardHandler)
-	router.Put("/flashcard", api.editFlashcardHandler)
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(mw.RequireRole(models.RoleAdmin))
+
+			r.Get("/users", api.listUsersHandler)
+			r.Post("/users/{userID}/disable", api.disableUserHandler)
+			r.Post("/users/{userID}/enable", api.enableUserHandler)
+			r.Post("/users/{userID}/password-reset", api.passwordResetHandler)
+			r.Put("/users/{userID}/role", api.setRoleHandler)
+		})
+	})
 
 	api.Mux = router
 
-	return &api
+	return &api, nil
 }
 
 const (
@@ -118,37 +205,6 @@ const (
 	// Any       = 3
 )
 
-func (a *API) signUpHandler(w http.ResponseWriter, r *http.Request) {
-	req := new(rest.SignUpRequest)
-
-	rawBody, err := io.ReadAll(r.Body)
-	defer r.Body.Close()
-
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(a.responseError("error read request body", err, http.StatusInternalServerError))
-		return
-	}
-
-	err = json.Unmarshal(rawBody, &req)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(a.responseError("error bind request body to request model", err, http.StatusBadRequest))
-		return
-	}
-
-	ctx, close := context.WithTimeout(r.Context(), 5*time.Second)
-	defer close()
-	err = a.usersController.CreateUser(ctx, req)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(a.responseError("error create user", err, http.StatusInternalServerError))
-		return
-	}
-
-	w.WriteHeader(http.StatusOK)
-}
-
 func (a *API) randomWordHandler(w http.ResponseWriter, r *http.Request) {
 	resp, err := http.Get(randomwordapi)
 	if err != nil {
@@ -185,13 +241,12 @@ func (a *API) newFlashcardHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, c := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
 	defer c()
 
 	err = a.flashcardsController.CreateFlashcard(ctx, req)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write(a.responseError("error create flashcard", err, http.StatusInternalServerError))
+		a.writeError(w, "error create flashcard", err)
 		return
 	}
 
@@ -204,7 +259,7 @@ func (a *API) getFlashcardHandler(w http.ResponseWriter, r *http.Request) {
 	word := r.URL.Query().Get("word")
 	meaning := r.URL.Query().Get("meaning")
 
-	ctx, c := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
 	defer c()
 
 	w.Header().Add("Content-Type", "application/json")
@@ -222,17 +277,11 @@ func (a *API) getFlashcardHandler(w http.ResponseWriter, r *http.Request) {
 			ID: id,
 		})
 		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write(a.responseError("not found", errors2.ErrNotFound, http.StatusNotFound))
-				return
-			}
-
-			w.WriteHeader(http.StatusInternalServerError)
-			w.Write(a.responseError("error select flashcard", err, http.StatusInternalServerError))
+			a.writeError(w, "error select flashcard", err)
 			return
 		}
 
+		w.Header().Set("ETag", etag(cards[0].Version))
 		response.Flashcards = cards
 		resp, err := json.Marshal(response)
 		if err != nil {
@@ -256,14 +305,7 @@ func (a *API) getFlashcardHandler(w http.ResponseWriter, r *http.Request) {
 				Word:   word,
 			})
 			if err != nil {
-				if errors.Is(err, sql.ErrNoRows) {
-					w.WriteHeader(http.StatusInternalServerError)
-					w.Write(a.responseError("not found", errors2.ErrNotFound, http.StatusNotFound))
-					return
-				}
-
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write(a.responseError("error select flashcard", err, http.StatusInternalServerError))
+				a.writeError(w, "error select flashcard", err)
 				return
 			}
 
@@ -283,14 +325,7 @@ func (a *API) getFlashcardHandler(w http.ResponseWriter, r *http.Request) {
 				Meaning: meaning,
 			})
 			if err != nil {
-				if errors.Is(err, sql.ErrNoRows) {
-					w.WriteHeader(http.StatusInternalServerError)
-					w.Write(a.responseError("not found", errors2.ErrNotFound, http.StatusNotFound))
-					return
-				}
-
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write(a.responseError("error select flashcard", err, http.StatusInternalServerError))
+				a.writeError(w, "error select flashcard", err)
 				return
 			}
 
@@ -326,19 +361,23 @@ func (a *API) deleteFlashcardHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, c := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
 	defer c()
 
 	err = a.Repo.Database().DeleteFlashcard(ctx, uuid)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write(a.responseError("error delete flashcard", err, http.StatusInternalServerError))
+		a.writeError(w, "error delete flashcard", err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
 func (a *API) editFlashcardHandler(w http.ResponseWriter, r *http.Request) {
+	match, ok := a.ifMatch(w, r)
+	if !ok {
+		return
+	}
+
 	var request *rest.EditFlashcardRequest
 	body, err := io.ReadAll(r.Body)
 	defer r.Body.Close()
@@ -355,7 +394,7 @@ func (a *API) editFlashcardHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, c := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
 	defer c()
 
 	id, err := uuid.Parse(request.Id)
@@ -364,27 +403,46 @@ func (a *API) editFlashcardHandler(w http.ResponseWriter, r *http.Request) {
 		w.Write(a.responseError("error parse flashcard uuid", err, http.StatusBadRequest))
 		return
 	}
+	version, err := match.version(a.flashcardVersion(ctx, id))
+	if err != nil {
+		a.writeError(w, "error select flashcard", err)
+		return
+	}
+
 	params := repository.UpdateFlashcardParams{
-		ID: id,
+		ID:      id,
+		Version: version,
 	}
 
-	switch {
-	case request.WordInNative != "":
-		params.Meaning = request.WordInNative
-	case request.WordInTarget != "":
-		params.Word = request.WordInTarget
-	case request.UsageExamples != nil:
-		params.Usage = request.UsageExamples
-	default:
+	params.Tags, err = flashcards.NormalizeTags(request.Tags)
+	if err != nil {
+		a.writeError(w, "error invalid tags", err)
+		return
+	}
+
+	// every field which is given is changed, see PATCH /flashcards/{id} to
+	// clear them
+	if request.WordInNative != "" {
+		params.Meaning = &request.WordInNative
+	}
+	if request.WordInTarget != "" {
+		params.Word = &request.WordInTarget
+	}
+	params.Usage = request.UsageExamples
+
+	if params.Meaning == nil && params.Word == nil && params.Usage == nil && params.Tags == nil {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write(a.responseError("error missing required fields", err, http.StatusBadRequest))
 		return
 	}
 
 	err = a.Repo.Database().UpdateFlashcard(ctx, params)
+	if errors.Is(err, errors2.ErrPreconditionFailed) {
+		a.writeFlashcardConflict(ctx, w, id, match.expected())
+		return
+	}
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write(a.responseError("error update flashcard", err, http.StatusInternalServerError))
+		a.writeError(w, "error update flashcard", err)
 		return
 	}
 
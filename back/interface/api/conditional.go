@@ -0,0 +1,126 @@
+package api
+
+import (
+	"fmt"
+	errors2 "languago/pkg/errors"
+	"languago/pkg/models/requests/rest"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// etag is the entity tag of a version of a card or a deck.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// noVersion is never the version of an entity, a write made on it always
+// conflicts.
+const noVersion int64 = -1
+
+// precondition is what the If-Match header of a write asks for: any version
+// or one of the versions listed.
+type precondition struct {
+	any      bool
+	versions []int64
+}
+
+// version returns the version the write is made on, 0 if any version matches.
+// The current version is only looked up when several versions are listed. If
+// none of them can match, the write is made on noVersion to be rejected.
+func (p precondition) version(current func() (int64, error)) (int64, error) {
+	switch {
+	case p.any:
+		return 0, nil
+	case len(p.versions) == 0:
+		return noVersion, nil
+	case len(p.versions) == 1:
+		return p.versions[0], nil
+	}
+
+	version, err := current()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, v := range p.versions {
+		if v == version {
+			return version, nil
+		}
+	}
+
+	return p.versions[0], nil
+}
+
+// expected is the version reported in a conflict, the first one listed.
+func (p precondition) expected() int64 {
+	if len(p.versions) == 0 {
+		return 0
+	}
+
+	return p.versions[0]
+}
+
+// ifMatch parses the If-Match header. No header or "*" matches any version,
+// otherwise it is a list of entity tags. Weak tags never match as If-Match
+// compares strongly, so do tags etag could not have made. Only a malformed
+// header is rejected here, a tag which doesn't match fails the write with 412.
+func (a *API) ifMatch(w http.ResponseWriter, r *http.Request) (precondition, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return precondition{any: true}, true
+	}
+
+	var p precondition
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		weak := strings.HasPrefix(tag, "W/")
+		opaque, ok := opaqueTag(strings.TrimPrefix(tag, "W/"))
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(a.responseError("error parse If-Match header", fmt.Errorf("error invalid entity tag %s", tag), http.StatusBadRequest))
+			return precondition{}, false
+		}
+
+		version, err := strconv.ParseInt(opaque, 10, 64)
+		if weak || err != nil || version <= 0 {
+			continue
+		}
+
+		p.versions = append(p.versions, version)
+	}
+
+	return p, true
+}
+
+// opaqueTag returns the tag without its quotes if it is a quoted entity tag.
+func opaqueTag(tag string) (string, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return "", false
+	}
+
+	opaque := tag[1 : len(tag)-1]
+	if strings.Contains(opaque, `"`) {
+		return "", false
+	}
+
+	return opaque, true
+}
+
+// writeConflict rejects a write made on an outdated version, the body holds
+// the current state so the client can merge and retry with its ETag.
+func (a *API) writeConflict(w http.ResponseWriter, expected, current int64, entity any) {
+	w.Header().Set("ETag", etag(current))
+
+	a.writeJSON(w, http.StatusPreconditionFailed, rest.ConflictResponse{
+		Code:            int(errors2.CodePreconditionFailed),
+		Message:         "entity was changed since the version given in If-Match",
+		ExpectedVersion: expected,
+		CurrentVersion:  current,
+		Current:         entity,
+	})
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"languago/infrastructure/repository"
+	errors2 "languago/pkg/errors"
+	"languago/pkg/models/requests/rest"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (a *API) listDecksHandler(w http.ResponseWriter, r *http.Request) {
+	req := &rest.ListDecksRequest{
+		Name: r.URL.Query().Get("name"),
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	response, err := a.decksController.ListDecks(ctx, req)
+	if err != nil {
+		a.writeError(w, "error select decks", err)
+		return
+	}
+
+	a.writeJSON(w, http.StatusOK, response)
+}
+
+func (a *API) newDeckHandler(w http.ResponseWriter, r *http.Request) {
+	req := new(rest.CreateDeckRequest)
+	if !a.bindRequest(w, r, req) {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	response, err := a.decksController.CreateDeck(ctx, req)
+	if err != nil {
+		a.writeError(w, "error create deck", err)
+		return
+	}
+
+	a.writeJSON(w, http.StatusCreated, response)
+}
+
+func (a *API) getDeckHandler(w http.ResponseWriter, r *http.Request) {
+	deckID, ok := a.deckID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	response, err := a.decksController.GetDeck(ctx, deckID)
+	if err != nil {
+		a.writeError(w, "error select deck", err)
+		return
+	}
+
+	a.writeJSON(w, http.StatusOK, response)
+}
+
+func (a *API) editDeckHandler(w http.ResponseWriter, r *http.Request) {
+	deckID, ok := a.deckID(w, r)
+	if !ok {
+		return
+	}
+
+	match, ok := a.ifMatch(w, r)
+	if !ok {
+		return
+	}
+
+	req := new(rest.EditDeckRequest)
+	if !a.bindRequest(w, r, req) {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	var err error
+	req.Version, err = match.version(func() (int64, error) {
+		deck, err := a.Repo.Database().SelectDeck(ctx, repository.SelectDeckParams{ID: deckID})
+		if err != nil {
+			return 0, err
+		}
+
+		return deck.Version, nil
+	})
+	if err != nil {
+		a.writeError(w, "error select deck", err)
+		return
+	}
+
+	err = a.decksController.EditDeck(ctx, deckID, req)
+	if errors.Is(err, errors2.ErrPreconditionFailed) {
+		current, err := a.decksController.GetDeck(ctx, deckID)
+		if err != nil {
+			a.writeError(w, "error select deck", err)
+			return
+		}
+
+		a.writeConflict(w, match.expected(), current.Deck.Version, current.Deck)
+		return
+	}
+	if err != nil {
+		a.writeError(w, "error update deck", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (a *API) deleteDeckHandler(w http.ResponseWriter, r *http.Request) {
+	deckID, ok := a.deckID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	if err := a.decksController.DeleteDeck(ctx, deckID); err != nil {
+		a.writeError(w, "error delete deck", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (a *API) addToDeckHandler(w http.ResponseWriter, r *http.Request) {
+	deckID, ok := a.deckID(w, r)
+	if !ok {
+		return
+	}
+
+	cardID, err := uuid.Parse(chi.URLParam(r, "cardID"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(a.responseError("error parse card id", err, http.StatusBadRequest))
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	if err := a.decksController.AddFlashcard(ctx, deckID, cardID); err != nil {
+		a.writeError(w, "error add flashcard to deck", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (a *API) deleteFromDeckHandler(w http.ResponseWriter, r *http.Request) {
+	deckID, ok := a.deckID(w, r)
+	if !ok {
+		return
+	}
+
+	cardID, err := uuid.Parse(chi.URLParam(r, "cardID"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(a.responseError("error parse card id", err, http.StatusBadRequest))
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	if err := a.decksController.RemoveFlashcard(ctx, deckID, cardID); err != nil {
+		a.writeError(w, "error remove flashcard from deck", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (a *API) deckID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	deckID, err := uuid.Parse(chi.URLParam(r, "deckID"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(a.responseError("error parse deck id", err, http.StatusBadRequest))
+		return uuid.Nil, false
+	}
+
+	return deckID, true
+}
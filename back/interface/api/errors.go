@@ -2,14 +2,18 @@ package api
 
 import (
 	"encoding/json"
-	"languago/pkg/errors"
+	"errors"
+	errors2 "languago/pkg/errors"
+	"net/http"
 )
 
 func (a *API) responseError(msg string, e error, code int) []byte {
 	err := a.errorsPresenter.ServiceError(
 		e,
-		errors.ErrorServiceID(a.ID),
-		errors.ErrorServiceErr(errors.New(errors.Code(code), msg)),
+		errors2.ErrorServiceID(a.ID),
+		errors2.ErrorCode(errors2.Code(code)),
+		errors2.ErrorMessage(msg),
+		errors2.ErrorServiceErr(e),
 	)
 
 	body, err := json.Marshal(a.errorsPresenter.ResponseError(err))
@@ -19,3 +23,25 @@ func (a *API) responseError(msg string, e error, code int) []byte {
 
 	return body
 }
+
+// errorStatus maps an error returned by controllers or storage to the HTTP status code.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, errors2.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errors2.ErrUnauthorized),
+		errors.Is(err, errors2.ErrInvalidToken):
+		return http.StatusUnauthorized
+	case errors.Is(err, errors2.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, errors2.ErrValidation),
+		errors.Is(err, errors2.ErrBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, errors2.ErrAlreadyExists):
+		return http.StatusConflict
+	case errors.Is(err, errors2.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"languago/infrastructure/repository"
+	errors2 "languago/pkg/errors"
+	"languago/pkg/models/requests/rest"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// mergePatchType is the media type of JSON Merge Patch, RFC 7396.
+const mergePatchType = "application/merge-patch+json"
+
+func (a *API) listFlashcardsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit, ok := a.queryInt(w, r, "limit")
+	if !ok {
+		return
+	}
+
+	req := &rest.ListFlashcardsRequest{
+		Limit:  limit,
+		Cursor: query.Get("cursor"),
+		Sort:   query.Get("sort"),
+		Order:  query.Get("order"),
+		Tag:    query.Get("tag"),
+		Prefix: query.Get("prefix"),
+	}
+
+	if deckID := query.Get("deck_id"); deckID != "" {
+		id, err := uuid.Parse(deckID)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(a.responseError("error parse deck id", err, http.StatusBadRequest))
+			return
+		}
+		req.DeckID = id
+	}
+
+	if due := query.Get("due"); due != "" {
+		d, err := strconv.ParseBool(due)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(a.responseError("error parse due", err, http.StatusBadRequest))
+			return
+		}
+		req.Due = d
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	response, err := a.flashcardsController.ListFlashcards(ctx, req)
+	if err != nil {
+		a.writeError(w, "error list flashcards", err)
+		return
+	}
+
+	a.writeJSON(w, http.StatusOK, response)
+}
+
+func (a *API) searchHandler(w http.ResponseWriter, r *http.Request) {
+	limit, ok := a.queryInt(w, r, "limit")
+	if !ok {
+		return
+	}
+
+	req := &rest.SearchFlashcardsRequest{
+		Query: r.URL.Query().Get("q"),
+		Limit: limit,
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	response, err := a.flashcardsController.SearchFlashcards(ctx, req)
+	if err != nil {
+		a.writeError(w, "error search flashcards", err)
+		return
+	}
+
+	a.writeJSON(w, http.StatusOK, response)
+}
+
+func (a *API) patchFlashcardHandler(w http.ResponseWriter, r *http.Request) {
+	cardID, err := uuid.Parse(chi.URLParam(r, "cardID"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(a.responseError("error parse card id", err, http.StatusBadRequest))
+		return
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != mergePatchType {
+		w.Header().Set("Accept-Patch", mergePatchType)
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		w.Write(a.responseError("error patch must be "+mergePatchType, nil, http.StatusUnsupportedMediaType))
+		return
+	}
+
+	match, ok := a.ifMatch(w, r)
+	if !ok {
+		return
+	}
+
+	req := new(rest.PatchFlashcardRequest)
+	if !a.bindPatch(w, r, req) {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	req.Version, err = match.version(a.flashcardVersion(ctx, cardID))
+	if err != nil {
+		a.writeError(w, "error select flashcard", err)
+		return
+	}
+
+	card, err := a.flashcardsController.PatchFlashcard(ctx, cardID, req)
+	if errors.Is(err, errors2.ErrPreconditionFailed) {
+		a.writeFlashcardConflict(ctx, w, cardID, match.expected())
+		return
+	}
+	if err != nil {
+		a.writeError(w, "error patch flashcard", err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(card.Version))
+	a.writeJSON(w, http.StatusOK, card)
+}
+
+func (a *API) flashcardHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	cardID, err := uuid.Parse(chi.URLParam(r, "cardID"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(a.responseError("error parse card id", err, http.StatusBadRequest))
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	response, err := a.flashcardsController.FlashcardHistory(ctx, cardID)
+	if err != nil {
+		a.writeError(w, "error select flashcard history", err)
+		return
+	}
+
+	a.writeJSON(w, http.StatusOK, response)
+}
+
+func (a *API) revertFlashcardHandler(w http.ResponseWriter, r *http.Request) {
+	cardID, err := uuid.Parse(chi.URLParam(r, "cardID"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(a.responseError("error parse card id", err, http.StatusBadRequest))
+		return
+	}
+
+	revisionID, err := uuid.Parse(chi.URLParam(r, "revisionID"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(a.responseError("error parse revision id", err, http.StatusBadRequest))
+		return
+	}
+
+	match, ok := a.ifMatch(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	version, err := match.version(a.flashcardVersion(ctx, cardID))
+	if err != nil {
+		a.writeError(w, "error select flashcard", err)
+		return
+	}
+
+	card, err := a.flashcardsController.RevertFlashcard(ctx, cardID, &rest.RevertFlashcardRequest{
+		RevisionID: revisionID,
+		Version:    version,
+	})
+	if errors.Is(err, errors2.ErrPreconditionFailed) {
+		a.writeFlashcardConflict(ctx, w, cardID, match.expected())
+		return
+	}
+	if err != nil {
+		a.writeError(w, "error revert flashcard", err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(card.Version))
+	a.writeJSON(w, http.StatusOK, card)
+}
+
+// writeFlashcardConflict rejects a write made on an outdated version of the
+// card with the card as it is now.
+func (a *API) writeFlashcardConflict(ctx context.Context, w http.ResponseWriter, cardID uuid.UUID, version int64) {
+	cards, err := a.Repo.Database().SelectFlashcard(ctx, repository.SelectFlashcardParams{ID: cardID})
+	if err != nil {
+		a.writeError(w, "error select flashcard", err)
+		return
+	}
+
+	a.writeConflict(w, version, cards[0].Version, cards[0])
+}
+
+// flashcardVersion looks up the version of the card a list of entity tags is
+// matched against.
+func (a *API) flashcardVersion(ctx context.Context, cardID uuid.UUID) func() (int64, error) {
+	return func() (int64, error) {
+		cards, err := a.Repo.Database().SelectFlashcard(ctx, repository.SelectFlashcardParams{ID: cardID})
+		if err != nil {
+			return 0, err
+		}
+
+		return cards[0].Version, nil
+	}
+}
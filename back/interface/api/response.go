@@ -0,0 +1,83 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// bindRequest reads the request body and unmarshals it into v.
+func (a *API) bindRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(a.responseError("error read request body", err, http.StatusBadRequest))
+		return false
+	}
+
+	if err = json.Unmarshal(body, v); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(a.responseError("error bind request body to a request model", err, http.StatusBadRequest))
+		return false
+	}
+
+	return true
+}
+
+// bindPatch reads a JSON Merge Patch into v. The patch must be an object and
+// may only have the members of v, so the fields which are read only can't be
+// patched.
+func (a *API) bindPatch(w http.ResponseWriter, r *http.Request, v any) bool {
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(a.responseError("error read request body", err, http.StatusBadRequest))
+		return false
+	}
+
+	if trimmed := bytes.TrimSpace(body); len(trimmed) == 0 || trimmed[0] != '{' {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(a.responseError("error patch must be a JSON object", nil, http.StatusBadRequest))
+		return false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+
+	if err = decoder.Decode(v); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(a.responseError("error bind patch to a request model", err, http.StatusBadRequest))
+		return false
+	}
+
+	return true
+}
+
+// writeJSON marshals v and writes it with the given status code.
+func (a *API) writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	resp, err := json.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(a.responseError("error marshal response body", err, http.StatusInternalServerError))
+		return
+	}
+
+	w.WriteHeader(code)
+	w.Write(resp)
+}
+
+// writeError writes the error response with the status code matching err.
+func (a *API) writeError(w http.ResponseWriter, msg string, err error) {
+	code := errorStatus(err)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(a.responseError(msg, err, code))
+}
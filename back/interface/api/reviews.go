@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"languago/pkg/models/requests/rest"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (a *API) dueReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	req := new(rest.DueReviewsRequest)
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(a.responseError("error parse limit", err, http.StatusBadRequest))
+			return
+		}
+		req.Limit = l
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	response, err := a.reviewsController.DueReviews(ctx, req)
+	if err != nil {
+		a.writeError(w, "error select due reviews", err)
+		return
+	}
+
+	a.writeJSON(w, http.StatusOK, response)
+}
+
+func (a *API) reviewFlashcardHandler(w http.ResponseWriter, r *http.Request) {
+	cardID, err := uuid.Parse(chi.URLParam(r, "cardID"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(a.responseError("error parse card id", err, http.StatusBadRequest))
+		return
+	}
+
+	req := new(rest.ReviewFlashcardRequest)
+	if !a.bindRequest(w, r, req) {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	response, err := a.reviewsController.ReviewFlashcard(ctx, cardID, req)
+	if err != nil {
+		a.writeError(w, "error review flashcard", err)
+		return
+	}
+
+	a.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (a *API) listTrashHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	response, err := a.trashController.ListTrash(ctx)
+	if err != nil {
+		a.writeError(w, "error select trash", err)
+		return
+	}
+
+	a.writeJSON(w, http.StatusOK, response)
+}
+
+func (a *API) restoreFlashcardHandler(w http.ResponseWriter, r *http.Request) {
+	cardID, err := uuid.Parse(chi.URLParam(r, "cardID"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(a.responseError("error parse card id", err, http.StatusBadRequest))
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	if err := a.trashController.RestoreFlashcard(ctx, cardID); err != nil {
+		a.writeError(w, "error restore flashcard", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (a *API) restoreDeckHandler(w http.ResponseWriter, r *http.Request) {
+	deckID, ok := a.deckID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	if err := a.trashController.RestoreDeck(ctx, deckID); err != nil {
+		a.writeError(w, "error restore deck", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
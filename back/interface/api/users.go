@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"languago/pkg/models/requests/rest"
+	"net/http"
+	"time"
+)
+
+func (a *API) signUpHandler(w http.ResponseWriter, r *http.Request) {
+	req := new(rest.SignUpRequest)
+	if !a.bindRequest(w, r, req) {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	response, err := a.usersController.CreateUser(ctx, req)
+	if err != nil {
+		a.writeError(w, "error create user", err)
+		return
+	}
+
+	a.writeJSON(w, http.StatusCreated, response)
+}
+
+func (a *API) signInHandler(w http.ResponseWriter, r *http.Request) {
+	req := new(rest.SignInRequest)
+	if !a.bindRequest(w, r, req) {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	response, err := a.usersController.SignIn(ctx, req)
+	if err != nil {
+		a.writeError(w, "error sign in", err)
+		return
+	}
+
+	a.writeJSON(w, http.StatusOK, response)
+}
+
+func (a *API) refreshTokenHandler(w http.ResponseWriter, r *http.Request) {
+	req := new(rest.RefreshTokenRequest)
+	if !a.bindRequest(w, r, req) {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	response, err := a.usersController.RefreshToken(ctx, req)
+	if err != nil {
+		a.writeError(w, "error refresh token", err)
+		return
+	}
+
+	a.writeJSON(w, http.StatusOK, response)
+}
+
+func (a *API) signOutHandler(w http.ResponseWriter, r *http.Request) {
+	req := new(rest.SignOutRequest)
+	if !a.bindRequest(w, r, req) {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	err := a.usersController.SignOut(ctx, req)
+	if err != nil {
+		a.writeError(w, "error sign out", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) changePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	req := new(rest.ChangePasswordRequest)
+	if !a.bindRequest(w, r, req) {
+		return
+	}
+
+	ctx, c := context.WithTimeout(r.Context(), 5*time.Second)
+	defer c()
+
+	response, err := a.usersController.ChangePassword(ctx, req)
+	if err != nil {
+		a.writeError(w, "error change password", err)
+		return
+	}
+
+	a.writeJSON(w, http.StatusOK, response)
+}
+
+func (a *API) jwksHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	a.writeJSON(w, http.StatusOK, a.authorizer.JWKS())
+}
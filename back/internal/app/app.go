@@ -2,10 +2,12 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"languago/infrastructure/config"
 	"languago/infrastructure/logger"
 	"languago/internal/server"
+	"languago/pkg/closer"
 	errors2 "languago/pkg/errors"
 	"os/signal"
 	"syscall"
@@ -43,6 +45,7 @@ func (a *languagoApp) main(ctx context.Context) error {
 		Log:             logger.ProvideLogger(a.config.LoggerCfg),
 		Logger:          a.config.GetLoggerConfig().GetLogger(),
 		Config:          a.config,
+		Closer:          closer.NewCloser(),
 		ErrorsPresenter: errors2.NewErrorPresenter(log),
 	})
 
@@ -50,18 +53,34 @@ func (a *languagoApp) main(ctx context.Context) error {
 		node.Run()
 	}()
 
-	<-ctx.Done()
-	func() {
-		closeTimer := time.AfterFunc(shutdownTimeout, func() {
-			node.Log().Warn().Msg("error node shutdown timeout. stopping node with force")
-			node.Stop(ctx) // todo force
-		})
-		defer closeTimer.Stop()
+	// a service the supervisor gave up on stops the node like a signal
+	var failure error
+	select {
+	case <-ctx.Done():
+	case failure = <-node.Failed():
+		node.Log().Error().Err(failure).Msg("error service failed")
+	}
+	node.Log().Info().Msgf("node shutting down | node_id: %v time: %v", node.ID().String(), time.Now())
 
-		node.Stop(ctx)
-	}()
+	// the requests in flight get shutdownTimeout to finish, the connections
+	// are closed after them
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
 
-	node.Log().Info().Msgf("node shutting down | node_id: %v time: %v", node.ID().String(), time.Now())
+	err := node.Stop(shutdownCtx)
+
+	// the last state of each service tells what the node went through
+	for _, status := range node.Status() {
+		node.Log().Info().Msgf("service %s %s | restarts: %d last_error: %q",
+			status.Name, status.State, status.Restarts, status.LastError)
+	}
+
+	if err != nil {
+		node.Log().Warn().Err(err).Msg("error node shutdown. stopping node with force")
+		return errors.Join(failure, err)
+	}
+
+	node.Log().Info().Msgf("node stopped | node_id: %v time: %v", node.ID().String(), time.Now())
 
-	return nil
+	return failure
 }
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"languago/infrastructure/config"
+	"languago/infrastructure/repository"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateTimeout time.Duration = 10 * time.Minute
+
+// Migrate runs the migrate command against the configured database:
+//
+//	migrate up          applies all pending migrations
+//	migrate down [n]    reverts the last n migrations, 1 by default
+//	migrate status      lists the migrations and when they were applied
+func Migrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("error migrate: command required, one of up, down [n], status")
+	}
+
+	cfg := config.InitialConfiguration()
+
+	interactor, err := repository.NewDatabaseInteractor(cfg.GetDatabaseConfig())
+	if err != nil {
+		return fmt.Errorf("error migrate: %w", err)
+	}
+	defer interactor.CloseConnection()
+
+	ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+	defer cancel()
+
+	migrator := interactor.Migrator()
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			n, err = strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("error migrate down: invalid number of migrations %q", args[1])
+			}
+		}
+
+		return migrator.Down(ctx, n)
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range status {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+
+		return w.Flush()
+	default:
+		return fmt.Errorf("error migrate: unknown command %q", args[0])
+	}
+}
@@ -2,8 +2,10 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"languago/infrastructure/config"
 	"languago/infrastructure/logger"
+	"languago/pkg/closer"
 	errors2 "languago/pkg/errors"
 
 	"github.com/google/uuid"
@@ -14,21 +16,33 @@ type (
 	Node interface {
 		ID() uuid.UUID
 		Run()
-		Stop(ctx context.Context)
+		// Stop drains the services and closes what they opened, it gives up
+		// once ctx is done.
+		Stop(ctx context.Context) error
+		// Status reports the state of each service.
+		Status() []ServiceStatus
+		// Failed receives the error of a service which couldn't be kept
+		// running, the node should be stopped then.
+		Failed() <-chan error
 		SetConfig(cfg config.AbstractNodeConfig)
 		ErrorsPresenter() errors2.ErrorsPersenter
 		Log() *zerolog.Logger
 	}
 
+	// Service is run by the supervisor of the node. Start mustn't block, the
+	// errors which stop the service are sent to e and Start is called again
+	// to restart it.
 	Service interface {
 		Start(e chan error)
+		Stop(ctx context.Context) error
 	}
 
 	node struct {
-		id       uuid.UUID
-		config   config.AbstractNodeConfig
-		services Services
-		log      *zerolog.Logger
+		id         uuid.UUID
+		config     config.AbstractNodeConfig
+		supervisor Supervisor
+		log        *zerolog.Logger
+		closer     closer.Closer
 
 		errorsPersenter errors2.ErrorsPersenter
 		errorCh         chan error
@@ -46,7 +60,9 @@ type (
 		Log    zerolog.Logger
 		Logger logger.Logger
 		Config config.AbstractConfig
-		//Closer          closer.Closer
+		// Closer closes the services and what they opened when the node
+		// stops, a new one is used if it is nil
+		Closer          closer.Closer
 		ErrorsPresenter errors2.ErrorsPersenter
 	}
 )
@@ -56,10 +72,23 @@ func NewNode(args *NewNodeParams) Node {
 		panic("error NewNodeParams are required.")
 	}
 
-	var services Services = make(Services, 0)
-	for _, serviceCfg := range args.Config.GetNodeConfig().GetServicesCfg() {
-		service := NewService(args.Config, serviceCfg.GetHTTPAddress())
-		services = append(services, service)
+	c := args.Closer
+	if c == nil {
+		c = closer.NewCloser()
+	}
+
+	nodeCfg := args.Config.GetNodeConfig()
+	errorCh := make(chan error)
+	supervisor := NewSupervisor(args.Log, RestartPolicy{
+		MaxRestarts: nodeCfg.GetMaxRestarts(),
+		MinBackoff:  nodeCfg.GetMinBackoff(),
+		MaxBackoff:  nodeCfg.GetMaxBackoff(),
+		ResetAfter:  nodeCfg.GetRestartReset(),
+	}, errorCh)
+
+	for _, serviceCfg := range nodeCfg.GetServicesCfg() {
+		service := NewService(args.Config, serviceCfg.GetHTTPAddress(), c)
+		supervisor.Add(serviceCfg.ServiceName(), service)
 	}
 
 	nodeId := uuid.New()
@@ -68,14 +97,13 @@ func NewNode(args *NewNodeParams) Node {
 		id:              nodeId,
 		logger:          args.Logger, //todo remove
 		errorsPersenter: args.ErrorsPresenter,
-		services:        services,
-		//closer:          args.Closer,
-		errorsObserver: errors2.NewErrorObserver(args.Log),
+		supervisor:      supervisor,
+		log:             &args.Log,
+		closer:          c,
+		errorCh:         errorCh,
+		errorsObserver:  errors2.NewErrorObserver(args.Log),
 	}
 
-	errObserver := errors2.NewErrorObserver(args.Log)
-	errObserver.WatchErrors(node)
-
 	node.LogErrors()
 
 	return node
@@ -84,15 +112,25 @@ func NewNode(args *NewNodeParams) Node {
 func (n *node) Run() {
 	n.logger.Info("starting the node: ", "node_id: ", n.ID())
 
-	for _, s := range n.services {
-		s.Start(n.errorCh)
-	}
+	n.supervisor.Run()
 }
 
-func (n *node) Stop(ctx context.Context) {
-	// todo
+func (n *node) Stop(ctx context.Context) error {
+	n.log.Info().Msgf("stopping the node | node_id: %v", n.ID())
+
+	// no service is restarted while they are stopped
+	n.supervisor.Stop()
+	if err := n.closer.Close(ctx); err != nil {
+		return fmt.Errorf("error stop node %v: %w", n.ID(), err)
+	}
+
+	return nil
 }
 
+func (n *node) Status() []ServiceStatus { return n.supervisor.Status() }
+
+func (n *node) Failed() <-chan error { return n.supervisor.Failed() }
+
 func (n *node) ID() uuid.UUID { return n.id }
 
 func (n *node) SetConfig(cfg config.AbstractNodeConfig) { n.config = cfg }
@@ -1,14 +1,20 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"languago/infrastructure/config"
 	"languago/infrastructure/logger"
 	"languago/infrastructure/repository"
 	"languago/interface/api"
+	"languago/pkg/cache"
+	"languago/pkg/closer"
+	"languago/pkg/controllers/trash"
 
 	errors2 "languago/pkg/errors"
 	"net/http"
+	"sync"
 
 	_ "github.com/lib/pq"
 	"github.com/rs/zerolog"
@@ -17,32 +23,131 @@ import (
 type (
 	flashcardService struct {
 		API             *api.API
+		server          *http.Server
+		purger          *trash.Purger
 		address         string
 		log             zerolog.Logger
 		errorsPresenter errors2.ErrorsPersenter
+
+		// mu guards the server of the last Start and the purger, stopPurger
+		// stops it and purged is closed once it returned
+		mu         sync.Mutex
+		stopPurger context.CancelFunc
+		purged     chan struct{}
 	}
 )
 
-func NewService(cfg config.AbstractConfig, address string) Service {
+// NewService builds the service with its database connection and cache, they
+// are registered with c to be closed after the service is stopped.
+func NewService(cfg config.AbstractConfig, address string, c closer.Closer) Service {
 	dbInteractor, err := repository.NewDatabaseInteractor(cfg.GetDatabaseConfig())
 	if err != nil {
 		panic("can't get database interactor! " + err.Error())
 	}
-	return &flashcardService{
-		API:     api.NewAPI(cfg.GetLoggerConfig(), dbInteractor),
+	c.Add(dbInteractor.CloseConnection)
+
+	if cfg.GetDatabaseConfig().MigrateOnStart() {
+		if err := dbInteractor.Migrator().Up(context.Background()); err != nil {
+			panic("can't migrate database! " + err.Error())
+		}
+	}
+
+	if cacheCfg := cfg.GetCacheConfig(); cacheCfg.Enabled() {
+		store := cache.NewInMemory(cacheCfg.GetMaxEntries(), cacheCfg.GetMaxBytes())
+		if cacheCfg.GetBackend() == config.CacheBackendRESP {
+			store = cache.NewRESP(cacheCfg.GetRESPOptions())
+		}
+		c.Add(store.Close)
+
+		dbInteractor = repository.NewCachedInteractor(
+			dbInteractor,
+			store,
+			repository.CacheTTL{
+				User:      cacheCfg.GetUserTTL(),
+				Deck:      cacheCfg.GetDeckTTL(),
+				Flashcard: cacheCfg.GetFlashcardTTL(),
+			},
+		)
+	}
+
+	flashcardsAPI, err := api.NewAPI(cfg.GetLoggerConfig(), cfg.GetAuthConfig(), dbInteractor)
+	if err != nil {
+		panic("can't init api! " + err.Error())
+	}
+
+	log := logger.ProvideLogger(cfg.GetLoggerConfig())
+
+	service := &flashcardService{
+		API: flashcardsAPI,
+		purger: trash.NewPurger(
+			log,
+			dbInteractor,
+			cfg.GetTrashConfig().GetRetention(),
+			cfg.GetTrashConfig().GetPurgeInterval(),
+		),
 		address: address,
-		log:     logger.ProvideLogger(cfg.GetLoggerConfig()),
+		log:     log,
 	}
+	c.AddShutdown(service.Stop)
+
+	return service
 }
 
+// Start serves on a new server each time, so a service which crashed can be
+// started again. The purger is started once and keeps running.
 func (s *flashcardService) Start(e chan error) {
 	s.log.Info().Msgf("Starting server at %v", s.address)
-	go s.listen(e)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.server = &http.Server{
+		Addr:    s.address,
+		Handler: s.API,
+	}
+	go s.listen(s.server, e)
+
+	if s.stopPurger == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopPurger, s.purged = cancel, make(chan struct{})
+		go func(purged chan struct{}) {
+			defer close(purged)
+			s.purger.Run(ctx)
+		}(s.purged)
+	}
 }
 
-func (s *flashcardService) listen(e chan error) {
-	err := http.ListenAndServe(s.address, s.API)
-	if err != nil {
+// Stop lets the requests in flight finish and stops the purger, it gives up
+// once ctx is done.
+func (s *flashcardService) Stop(ctx context.Context) error {
+	s.log.Info().Msgf("Stopping server at %v", s.address)
+
+	s.mu.Lock()
+	server, stopPurger, purged := s.server, s.stopPurger, s.purged
+	s.mu.Unlock()
+
+	var errs []error
+	if server != nil {
+		if err := server.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("error shutdown server at %v: %w", s.address, err))
+		}
+	}
+
+	if stopPurger != nil {
+		stopPurger()
+		select {
+		case <-purged:
+		case <-ctx.Done():
+			errs = append(errs, fmt.Errorf("error stop trash purger: %w", ctx.Err()))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *flashcardService) listen(server *http.Server, e chan error) {
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		e <- fmt.Errorf("error service runtime error: %w", err)
 	}
 }
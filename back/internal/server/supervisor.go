@@ -0,0 +1,254 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	ServiceStarting   ServiceState = "starting"
+	ServiceRunning    ServiceState = "running"
+	ServiceRestarting ServiceState = "restarting"
+	ServiceFailed     ServiceState = "failed"
+	ServiceStopped    ServiceState = "stopped"
+)
+
+type (
+	// Supervisor starts the services and restarts the ones which report an
+	// error. A service which crashes more often than the policy allows is
+	// given up on and the error is sent on Failed, the node should stop then.
+	Supervisor interface {
+		Add(name string, s Service)
+		Run()
+		// Stop stops restarting the services, stopping them is up to the
+		// closer of the node.
+		Stop()
+		Status() []ServiceStatus
+		Failed() <-chan error
+	}
+
+	// RestartPolicy tells how a crashed service is restarted. The delay
+	// before a restart doubles from MinBackoff up to MaxBackoff with every
+	// crash in a row, after MaxRestarts of them the service has failed. A
+	// service which ran for ResetAfter starts the count over.
+	RestartPolicy struct {
+		MaxRestarts int
+		MinBackoff  time.Duration
+		MaxBackoff  time.Duration
+		ResetAfter  time.Duration
+	}
+
+	ServiceState string
+
+	ServiceStatus struct {
+		Name  string       `json:"name"`
+		State ServiceState `json:"state"`
+		// Restarts counts every restart since the node started
+		Restarts  int       `json:"restarts"`
+		LastError string    `json:"last_error,omitempty"`
+		Since     time.Time `json:"since"`
+	}
+
+	supervisor struct {
+		log     zerolog.Logger
+		policy  RestartPolicy
+		errorCh chan error
+		failed  chan error
+
+		ctx    context.Context
+		cancel context.CancelFunc
+		wg     sync.WaitGroup
+
+		mu       sync.Mutex
+		services []*supervised
+	}
+
+	supervised struct {
+		service Service
+		// errs is where the service reports its errors, each service has
+		// its own to know which one crashed
+		errs   chan error
+		status ServiceStatus
+		// crashes counts the crashes in a row
+		crashes int
+	}
+)
+
+// NewSupervisor returns a supervisor restarting the services by the policy,
+// every error of a service is also sent to errorCh unless it is nil.
+func NewSupervisor(log zerolog.Logger, policy RestartPolicy, errorCh chan error) Supervisor {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &supervisor{
+		log:     log,
+		policy:  policy,
+		errorCh: errorCh,
+		failed:  make(chan error, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+func (s *supervisor) Add(name string, service Service) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.services = append(s.services, &supervised{
+		service: service,
+		errs:    make(chan error, 1),
+		status: ServiceStatus{
+			Name:  name,
+			State: ServiceStarting,
+			Since: time.Now(),
+		},
+	})
+}
+
+// Run starts the services and watches them until Stop is called.
+func (s *supervisor) Run() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx.Err() != nil {
+		return
+	}
+
+	for _, sv := range s.services {
+		s.start(sv)
+
+		s.wg.Add(1)
+		go s.watch(sv)
+	}
+}
+
+func (s *supervisor) Stop() {
+	s.cancel()
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sv := range s.services {
+		if sv.status.State != ServiceFailed {
+			s.setState(sv, ServiceStopped)
+		}
+	}
+}
+
+func (s *supervisor) Status() []ServiceStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]ServiceStatus, 0, len(s.services))
+	for _, sv := range s.services {
+		statuses = append(statuses, sv.status)
+	}
+	return statuses
+}
+
+func (s *supervisor) Failed() <-chan error { return s.failed }
+
+// start starts the service, s.mu is held.
+func (s *supervisor) start(sv *supervised) {
+	s.setState(sv, ServiceRunning)
+	sv.service.Start(sv.errs)
+}
+
+// watch restarts the service whenever it reports an error, until the policy
+// is exhausted or the supervisor is stopped.
+func (s *supervisor) watch(sv *supervised) {
+	defer s.wg.Done()
+
+	for {
+		var err error
+		select {
+		case <-s.ctx.Done():
+			return
+		case err = <-sv.errs:
+		}
+
+		name := sv.status.Name
+		err = fmt.Errorf("error service %s: %w", name, err)
+		s.report(err)
+
+		crashes, delay := s.crashed(sv, err)
+		if crashes > s.policy.MaxRestarts {
+			s.log.Error().Msgf("service %s crashed %d times in a row, giving up", name, crashes)
+			select {
+			case s.failed <- fmt.Errorf("error service %s failed after %d restarts: %w", name, crashes-1, err):
+			default:
+			}
+			return
+		}
+
+		s.log.Warn().Msgf("service %s crashed, restarting in %v", name, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.mu.Lock()
+		if s.ctx.Err() == nil {
+			sv.status.Restarts++
+			s.start(sv)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// crashed records the crash and returns how many crashes in a row the
+// service had and how long to wait before it is restarted.
+func (s *supervisor) crashed(sv *supervised, err error) (int, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(sv.status.Since) >= s.policy.ResetAfter {
+		sv.crashes = 0
+	}
+	sv.crashes++
+	sv.status.LastError = err.Error()
+
+	if sv.crashes > s.policy.MaxRestarts {
+		s.setState(sv, ServiceFailed)
+		return sv.crashes, 0
+	}
+
+	s.setState(sv, ServiceRestarting)
+	return sv.crashes, s.policy.backoff(sv.crashes)
+}
+
+func (s *supervisor) report(err error) {
+	if s.errorCh == nil {
+		return
+	}
+
+	select {
+	case s.errorCh <- err:
+	case <-s.ctx.Done():
+	}
+}
+
+// setState records and logs the new state of the service, s.mu is held.
+func (s *supervisor) setState(sv *supervised, state ServiceState) {
+	sv.status.State = state
+	sv.status.Since = time.Now()
+
+	s.log.Info().Msgf("service %s is %s | restarts: %d", sv.status.Name, state, sv.status.Restarts)
+}
+
+// backoff is the delay before the restart after the given crash in a row.
+func (p RestartPolicy) backoff(crashes int) time.Duration {
+	delay := p.MinBackoff
+	for i := 1; i < crashes && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, p.MaxBackoff)
+}
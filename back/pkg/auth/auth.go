@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"languago/infrastructure/repository"
 	"languago/pkg/models"
@@ -14,36 +15,85 @@ import (
 	"github.com/rs/zerolog"
 )
 
+var (
+	ErrUserDisabled = errors2.New(errors2.CodeAccountDisabled, "account disabled", errors2.ErrForbidden)
+	ErrRoleChanged  = errors2.New(errors2.CodeRoleChanged, "role changed, refresh the token", errors2.ErrInvalidToken)
+)
+
 type Authorizer interface {
 	Authorize(token *jwt.Token) (*models.User, error)
 	CreateToken(c ClaimJWTParams) (string, error)
-	Secret() []byte
+	// CreateSession starts a new session for the user and issues its tokens.
+	CreateSession(ctx context.Context, userID uuid.UUID) (*TokenPair, error)
+	// Refresh exchanges a refresh token for a new pair. The used refresh token
+	// stops working, presenting it again revokes the whole session.
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+	// Revoke ends the session the refresh token belongs to. Access tokens issued
+	// for the session are rejected from then on.
+	Revoke(ctx context.Context, refreshToken string) error
+	// ParseToken verifies the token signature against the keyring.
+	ParseToken(tokenStr string) (*jwt.Token, error)
+	// JWKS returns the public keys tokens can be verified with.
+	JWKS() JWKSet
 }
 
 type authorizer struct {
-	log         zerolog.Logger
-	secret      []byte
-	userStorage repository.UserRepository
+	log     zerolog.Logger
+	keys    *Keyring
+	cfg     abstractClaimsConfig
+	storage authStorage
 }
 
-func NewAuthorizer(log zerolog.Logger, userStorage repository.UserRepository, secret []byte) Authorizer {
+type authStorage interface {
+	repository.UserRepository
+	repository.SessionRepository
+}
+
+func NewAuthorizer(
+	log zerolog.Logger,
+	storage repository.Storage,
+	keys *Keyring,
+	cfg abstractClaimsConfig,
+) Authorizer {
 	return &authorizer{
-		log:         log,
-		secret:      secret,
-		userStorage: userStorage,
+		log:     log,
+		keys:    keys,
+		cfg:     cfg,
+		storage: storage,
 	}
 }
 
-func (a *authorizer) Authorize(token *jwt.Token) (*models.User, error) {
-	if err := token.Claims.Valid(); err != nil {
-		a.log.Warn().Msg(fmt.Sprintf("invalid token claims: %s", err.Error()))
-		return nil, errors2.ErrInvalidToken
+func (a *authorizer) ParseToken(tokenStr string) (*jwt.Token, error) {
+	// claims are validated by Authorize, which applies the configured leeway
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+
+	token, err := parser.ParseWithClaims(tokenStr, make(jwt.MapClaims), a.keys.Keyfunc)
+	if err != nil {
+		var verr *jwt.ValidationError
+		if errors.As(err, &verr) && errors.Is(verr.Inner, ErrUnknownKey) {
+			return nil, ErrUnknownKey
+		}
+
+		return nil, fmt.Errorf("error parse token: %w", errors2.ErrInvalidToken)
 	}
 
+	return token, nil
+}
+
+func (a *authorizer) JWKS() JWKSet {
+	return a.keys.JWKS()
+}
+
+func (a *authorizer) Authorize(token *jwt.Token) (*models.User, error) {
 	payload, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
 		a.log.Warn().Msg("invalid token claims")
-		return nil, errors2.ErrInvalidToken
+		return nil, ErrInvalidClaims
+	}
+
+	if err := a.validateClaims(payload, time.Now()); err != nil {
+		a.log.Warn().Msg(fmt.Sprintf("invalid token claims: %s", err.Error()))
+		return nil, err
 	}
 
 	userIDstr, ok := payload["sub"].(string)
@@ -61,7 +111,29 @@ func (a *authorizer) Authorize(token *jwt.Token) (*models.User, error) {
 		return nil, errors2.ErrInvalidToken
 	}
 
-	user, err := a.userStorage.SelectUser(ctx, repository.SelectUserParams{
+	sessionIDstr, ok := payload["sid"].(string)
+	if !ok || sessionIDstr == "" {
+		a.log.Warn().Msg("invalid sid claim")
+		return nil, errors2.ErrInvalidToken
+	}
+
+	sessionID, err := uuid.Parse(sessionIDstr)
+	if err != nil {
+		a.log.Warn().Msg("auth: error parse session_id")
+		return nil, errors2.ErrInvalidToken
+	}
+
+	session, err := a.storage.SelectSession(ctx, sessionID)
+	if err != nil {
+		a.log.Warn().Msg("error select session: " + err.Error())
+		return nil, ErrSessionRevoked
+	}
+
+	if session.UserID != userID || !session.Active(time.Now()) {
+		return nil, ErrSessionRevoked
+	}
+
+	user, err := a.storage.SelectUser(ctx, repository.SelectUserParams{
 		ID: userID,
 	})
 	if err != nil {
@@ -69,22 +141,50 @@ func (a *authorizer) Authorize(token *jwt.Token) (*models.User, error) {
 		return nil, errors2.ErrUnauthorized
 	}
 
+	if user.Disabled() {
+		return nil, ErrUserDisabled
+	}
+
+	// the role is checked on every request, so a demoted user can't keep
+	// using the privileges of an access token issued before
+	if role, _ := payload["role"].(string); models.Role(role) != user.Role {
+		return nil, ErrRoleChanged
+	}
+
 	a.log.Info().Msg(fmt.Sprintf("[ AUTHORIZE ] user authorized. user: %s time: %v"+user.Id.String(), time.Now()))
 	return user.ToModel(), nil
 }
 
 type ClaimJWTParams struct {
-	UserId string
+	UserId    string
+	SessionId string
+	Role      models.Role
+}
+
+type claims struct {
+	jwt.StandardClaims
+	SessionID string      `json:"sid,omitempty"`
+	Role      models.Role `json:"role,omitempty"`
 }
 
 func (a *authorizer) CreateToken(c ClaimJWTParams) (string, error) {
-	claims := jwt.StandardClaims{
-		ExpiresAt: time.Now().Add(24 * time.Hour).Unix(),
-		Subject:   c.UserId,
+	now := time.Now()
+
+	claims := claims{
+		StandardClaims: jwt.StandardClaims{
+			Audience:  a.cfg.GetAudience(),
+			ExpiresAt: now.Add(a.cfg.GetAccessTokenTTL()).Unix(),
+			Id:        uuid.NewString(),
+			IssuedAt:  now.Unix(),
+			Issuer:    a.cfg.GetIssuer(),
+			NotBefore: now.Unix(),
+			Subject:   c.UserId,
+		},
+		SessionID: c.SessionId,
+		Role:      c.Role,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := token.SignedString(a.secret)
+	signed, err := a.keys.Sign(claims)
 	if err != nil {
 		a.log.Error().Msg("error sign token")
 		return "", fmt.Errorf("error sign token: %w", err)
@@ -92,7 +192,3 @@ func (a *authorizer) CreateToken(c ClaimJWTParams) (string, error) {
 
 	return signed, nil
 }
-
-func (a *authorizer) Secret() []byte {
-	return a.secret
-}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"encoding/json"
+	"time"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/golang-jwt/jwt"
+)
+
+var (
+	ErrInvalidClaims    = errors2.New(errors2.CodeInvalidClaims, "invalid token claims", errors2.ErrInvalidToken)
+	ErrTokenExpired     = errors2.New(errors2.CodeTokenExpired, "token expired", errors2.ErrInvalidToken)
+	ErrTokenNotValidYet = errors2.New(errors2.CodeTokenNotValidYet, "token not valid yet", errors2.ErrInvalidToken)
+	ErrInvalidIssuer    = errors2.New(errors2.CodeInvalidIssuer, "invalid token issuer", errors2.ErrInvalidToken)
+	ErrInvalidAudience  = errors2.New(errors2.CodeInvalidAudience, "invalid token audience", errors2.ErrInvalidToken)
+)
+
+type abstractClaimsConfig interface {
+	GetIssuer() string
+	GetAudience() string
+	GetAccessTokenTTL() time.Duration
+	GetRefreshTokenTTL() time.Duration
+	// GetLeeway is the clock skew tolerated on exp, nbf and iat.
+	GetLeeway() time.Duration
+}
+
+// validateClaims checks the registered claims of an access token. exp, iat
+// and jti are required, nbf is checked when present.
+func (a *authorizer) validateClaims(payload jwt.MapClaims, now time.Time) error {
+	leeway := a.cfg.GetLeeway()
+
+	exp, ok := timeClaim(payload, "exp")
+	if !ok {
+		return ErrInvalidClaims
+	}
+
+	if now.After(exp.Add(leeway)) {
+		return ErrTokenExpired
+	}
+
+	iat, ok := timeClaim(payload, "iat")
+	if !ok {
+		return ErrInvalidClaims
+	}
+
+	if now.Add(leeway).Before(iat) {
+		return ErrTokenNotValidYet
+	}
+
+	if _, present := payload["nbf"]; present {
+		nbf, ok := timeClaim(payload, "nbf")
+		if !ok {
+			return ErrInvalidClaims
+		}
+
+		if now.Add(leeway).Before(nbf) {
+			return ErrTokenNotValidYet
+		}
+	}
+
+	if jti, _ := payload["jti"].(string); jti == "" {
+		return ErrInvalidClaims
+	}
+
+	if iss, _ := payload["iss"].(string); iss != a.cfg.GetIssuer() {
+		return ErrInvalidIssuer
+	}
+
+	if !hasAudience(payload["aud"], a.cfg.GetAudience()) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
+
+func timeClaim(payload jwt.MapClaims, name string) (time.Time, bool) {
+	switch v := payload[name].(type) {
+	case float64:
+		return time.Unix(int64(v), 0), true
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(n, 0), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// hasAudience accepts both forms of the aud claim, a single string or an
+// array of strings.
+func hasAudience(aud interface{}, audience string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == audience
+	case []interface{}:
+		for _, a := range v {
+			if s, ok := a.(string); ok && s == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
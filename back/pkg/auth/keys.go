@@ -0,0 +1,261 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+var ErrUnknownKey = errors2.New(errors2.CodeUnknownSigningKey, "unknown signing key", errors2.ErrInvalidToken)
+
+// KeyConfig describes a single signing key. HS256 secrets are taken from
+// Secret, the SecretEnv environment variable or File, in this order. ES256
+// and EdDSA keys are read from the PEM encoded File, which may hold a public
+// key only for retired keys that are kept to verify tokens already issued.
+type KeyConfig struct {
+	ID        string `mapstructure:"id"`
+	Algorithm string `mapstructure:"alg"`
+	Secret    string `mapstructure:"secret"`
+	SecretEnv string `mapstructure:"secret_env"`
+	File      string `mapstructure:"file"`
+}
+
+type abstractKeysConfig interface {
+	GetCurrentKeyID() string
+	GetSigningKeys() []KeyConfig
+}
+
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// Keyring holds the current signing key and the retired ones still accepted
+// for verification. Tokens carry the key id in the "kid" header.
+type Keyring struct {
+	current *signingKey
+	keys    map[string]*signingKey
+}
+
+func NewKeyring(cfg abstractKeysConfig) (*Keyring, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("error keys config required")
+	}
+
+	ring := &Keyring{
+		keys: make(map[string]*signingKey),
+	}
+
+	for _, kc := range cfg.GetSigningKeys() {
+		if kc.ID == "" {
+			return nil, fmt.Errorf("error signing key id required")
+		}
+
+		if _, ok := ring.keys[kc.ID]; ok {
+			return nil, fmt.Errorf("error duplicate signing key id %s", kc.ID)
+		}
+
+		key, err := loadKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("error load signing key %s: %w", kc.ID, err)
+		}
+
+		ring.keys[kc.ID] = key
+	}
+
+	current, ok := ring.keys[cfg.GetCurrentKeyID()]
+	if !ok {
+		return nil, fmt.Errorf("error current signing key %q not found", cfg.GetCurrentKeyID())
+	}
+
+	if current.signKey == nil {
+		return nil, fmt.Errorf("error current signing key %s has no private part", current.id)
+	}
+
+	ring.current = current
+
+	return ring, nil
+}
+
+// Sign signs the claims with the current key.
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.current.method, claims)
+	token.Header["kid"] = k.current.id
+
+	return token.SignedString(k.current.signKey)
+}
+
+// Keyfunc resolves the verification key by the token "kid" header. The token
+// algorithm must match the key, so a public key can't be used as HMAC secret.
+func (k *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors2.ErrInvalidToken
+	}
+
+	return key.verifyKey, nil
+}
+
+type (
+	// JWK is the public part of a signing key, RFC 7517.
+	JWK struct {
+		Kty string `json:"kty"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y,omitempty"`
+		Kid string `json:"kid"`
+		Alg string `json:"alg"`
+		Use string `json:"use"`
+	}
+
+	JWKSet struct {
+		Keys []JWK `json:"keys"`
+	}
+)
+
+// JWKS returns the public keys of the keyring. HMAC keys are symmetric and
+// never published.
+func (k *Keyring) JWKS() JWKSet {
+	set := JWKSet{
+		Keys: make([]JWK, 0, len(k.keys)),
+	}
+
+	for _, key := range k.keys {
+		switch pub := key.verifyKey.(type) {
+		case *ecdsa.PublicKey:
+			size := (pub.Curve.Params().BitSize + 7) / 8
+			set.Keys = append(set.Keys, JWK{
+				Kty: "EC",
+				Crv: pub.Curve.Params().Name,
+				X:   base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size))),
+				Y:   base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size))),
+				Kid: key.id,
+				Alg: key.method.Alg(),
+				Use: "sig",
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+				Kid: key.id,
+				Alg: key.method.Alg(),
+				Use: "sig",
+			})
+		}
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+
+	return set
+}
+
+func loadKey(kc KeyConfig) (*signingKey, error) {
+	key := &signingKey{
+		id: kc.ID,
+	}
+
+	switch kc.Algorithm {
+	case AlgHS256:
+		secret, err := loadSecret(kc)
+		if err != nil {
+			return nil, err
+		}
+
+		key.method = jwt.SigningMethodHS256
+		key.signKey = secret
+		key.verifyKey = secret
+	case AlgES256:
+		pem, err := os.ReadFile(kc.File)
+		if err != nil {
+			return nil, fmt.Errorf("error read key file: %w", err)
+		}
+
+		key.method = jwt.SigningMethodES256
+		if private, err := jwt.ParseECPrivateKeyFromPEM(pem); err == nil {
+			key.signKey = private
+			key.verifyKey = &private.PublicKey
+		} else if public, err := jwt.ParseECPublicKeyFromPEM(pem); err == nil {
+			key.verifyKey = public
+		} else {
+			return nil, fmt.Errorf("error parse ES256 key: %w", err)
+		}
+
+		// ES256 is ECDSA on P-256 only, a key on another curve signs tokens
+		// no verifier accepts
+		if curve := key.verifyKey.(*ecdsa.PublicKey).Curve; curve != elliptic.P256() {
+			return nil, fmt.Errorf("error ES256 key must be on curve P-256, got %s", curve.Params().Name)
+		}
+	case AlgEdDSA:
+		pem, err := os.ReadFile(kc.File)
+		if err != nil {
+			return nil, fmt.Errorf("error read key file: %w", err)
+		}
+
+		key.method = jwt.SigningMethodEdDSA
+		if private, err := jwt.ParseEdPrivateKeyFromPEM(pem); err == nil {
+			edKey, ok := private.(ed25519.PrivateKey)
+			if !ok {
+				return nil, fmt.Errorf("error key is not an ed25519 key")
+			}
+			key.signKey = edKey
+			key.verifyKey = edKey.Public()
+		} else if public, err := jwt.ParseEdPublicKeyFromPEM(pem); err == nil {
+			key.verifyKey = public
+		} else {
+			return nil, fmt.Errorf("error parse EdDSA key: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("error unsupported algorithm %q", kc.Algorithm)
+	}
+
+	return key, nil
+}
+
+func loadSecret(kc KeyConfig) ([]byte, error) {
+	var secret string
+
+	switch {
+	case kc.Secret != "":
+		secret = kc.Secret
+	case kc.SecretEnv != "":
+		secret = os.Getenv(kc.SecretEnv)
+	case kc.File != "":
+		raw, err := os.ReadFile(kc.File)
+		if err != nil {
+			return nil, fmt.Errorf("error read secret file: %w", err)
+		}
+		secret = strings.TrimSpace(string(raw))
+	}
+
+	if secret == "" {
+		return nil, fmt.Errorf("error empty HS256 secret")
+	}
+
+	return []byte(secret), nil
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	errors2 "languago/pkg/errors"
+
+	"golang.org/x/crypto/argon2"
+)
+
+var ErrInvalidHash = errors2.New(errors2.CodeInternalServerError, "invalid password hash", errors2.ErrInternalServerError)
+
+// hashPrefix starts every encoded hash, anything else is a legacy password
+// stored as it was signed up with.
+const hashPrefix = "$argon2id$"
+
+// PasswordParams are the argon2id cost parameters. They are encoded into every
+// hash, so raising them only affects new hashes and stored ones are upgraded on
+// the next successful sign in.
+type PasswordParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultPasswordParams are the minimum argon2id configuration of the OWASP
+// Password Storage Cheat Sheet, m=19MiB, t=2, p=1.
+var DefaultPasswordParams = PasswordParams{
+	Memory:      19 * 1024,
+	Iterations:  2,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+type PasswordHasher interface {
+	// Hash returns the PHC encoded argon2id hash of the password.
+	Hash(password string) (string, error)
+	// Verify reports whether the password matches the encoded hash and whether
+	// the hash was made with outdated parameters and should be replaced. A
+	// password stored before the passwords were hashed is compared as it is
+	// and always has to be replaced.
+	Verify(password, encoded string) (match bool, rehash bool, err error)
+}
+
+type passwordHasher struct {
+	params PasswordParams
+}
+
+func NewPasswordHasher(params PasswordParams) PasswordHasher {
+	return &passwordHasher{
+		params: params,
+	}
+}
+
+func (h *passwordHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error generate salt: %w", err)
+	}
+
+	key := argon2.IDKey(
+		[]byte(password),
+		salt,
+		h.params.Iterations,
+		h.params.Memory,
+		h.params.Parallelism,
+		h.params.KeyLength,
+	)
+
+	return encodeHash(h.params, salt, key), nil
+}
+
+func (h *passwordHasher) Verify(password, encoded string) (bool, bool, error) {
+	if !strings.HasPrefix(encoded, hashPrefix) {
+		match := subtle.ConstantTimeCompare([]byte(password), []byte(encoded)) == 1
+		return match, match, nil
+	}
+
+	params, salt, key, err := decodeHash(encoded)
+	if err != nil {
+		return false, false, err
+	}
+
+	other := argon2.IDKey(
+		[]byte(password),
+		salt,
+		params.Iterations,
+		params.Memory,
+		params.Parallelism,
+		params.KeyLength,
+	)
+
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return false, false, nil
+	}
+
+	return true, params != h.params, nil
+}
+
+func encodeHash(p PasswordParams, salt, key []byte) string {
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		p.Memory,
+		p.Iterations,
+		p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+func decodeHash(encoded string) (PasswordParams, []byte, []byte, error) {
+	var p PasswordParams
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism)
+	if err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+
+	return p, salt, key, nil
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+)
+
+const resetTokenLength = 32
+
+// NewResetToken returns a one-time password reset token to hand to the user
+// and the hash of it kept in the storage.
+func NewResetToken() (string, string, error) {
+	secret := make([]byte, resetTokenLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("error generate reset token: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	return token, hashRefreshSecret(token), nil
+}
+
+// ResetTokenMatches reports whether the token is the one the stored hash was
+// made of.
+func ResetTokenMatches(token, hash string) bool {
+	if token == "" || hash == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(hashRefreshSecret(token)), []byte(hash)) == 1
+}
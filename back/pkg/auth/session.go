@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"languago/infrastructure/repository"
+	"languago/pkg/models/entities"
+	"strings"
+	"time"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRefreshToken = errors2.New(errors2.CodeUnauthorized, "invalid refresh token", errors2.ErrInvalidToken)
+	ErrSessionRevoked      = errors2.New(errors2.CodeTokenRevoked, "session revoked", errors2.ErrInvalidToken)
+)
+
+const refreshSecretLength = 32
+
+// TokenPair is a short-lived access token and the refresh token to renew it.
+type TokenPair struct {
+	SessionID    uuid.UUID
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+func (a *authorizer) CreateSession(ctx context.Context, userID uuid.UUID) (*TokenPair, error) {
+	user, err := a.activeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID := uuid.New()
+
+	refreshToken, hash, err := newRefreshToken(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.storage.CreateSession(ctx, repository.CreateSessionParams{
+		ID:          sessionID,
+		UserID:      userID,
+		RefreshHash: hash,
+		ExpiresAt:   time.Now().Add(a.cfg.GetRefreshTokenTTL()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error create session: %w", err)
+	}
+
+	return a.tokenPair(user, sessionID, refreshToken)
+}
+
+func (a *authorizer) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	sessionID, hash, err := parseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := a.storage.SelectSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, errors2.ErrNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+
+		return nil, fmt.Errorf("error select session: %w", err)
+	}
+
+	if !session.Active(time.Now()) {
+		return nil, ErrSessionRevoked
+	}
+
+	if subtle.ConstantTimeCompare([]byte(session.RefreshHash), []byte(hash)) != 1 {
+		// an already rotated token is presented again, so either the client or
+		// an attacker holds a stolen copy. Ending the session locks out both.
+		a.revokeReused(ctx, session.ID)
+		return nil, ErrSessionRevoked
+	}
+
+	user, err := a.activeUser(ctx, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	next, nextHash, err := newRefreshToken(session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.storage.RotateSession(ctx, repository.RotateSessionParams{
+		ID:          session.ID,
+		OldHash:     hash,
+		RefreshHash: nextHash,
+		ExpiresAt:   time.Now().Add(a.cfg.GetRefreshTokenTTL()),
+	})
+	if err != nil {
+		if errors.Is(err, errors2.ErrNotFound) {
+			// lost the race against a concurrent refresh with the same token
+			a.revokeReused(ctx, session.ID)
+			return nil, ErrSessionRevoked
+		}
+
+		return nil, fmt.Errorf("error rotate session: %w", err)
+	}
+
+	return a.tokenPair(user, session.ID, next)
+}
+
+func (a *authorizer) Revoke(ctx context.Context, refreshToken string) error {
+	sessionID, hash, err := parseRefreshToken(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	session, err := a.storage.SelectSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, errors2.ErrNotFound) {
+			return ErrInvalidRefreshToken
+		}
+
+		return fmt.Errorf("error select session: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(session.RefreshHash), []byte(hash)) != 1 {
+		return ErrInvalidRefreshToken
+	}
+
+	if err := a.storage.RevokeSession(ctx, session.ID); err != nil {
+		return fmt.Errorf("error revoke session: %w", err)
+	}
+
+	return nil
+}
+
+// activeUser loads the user tokens are issued for. Tokens carry the current
+// role, disabled accounts get none.
+func (a *authorizer) activeUser(ctx context.Context, userID uuid.UUID) (*entities.User, error) {
+	user, err := a.storage.SelectUser(ctx, repository.SelectUserParams{
+		ID: userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error select user: %w", err)
+	}
+
+	if user.Disabled() {
+		return nil, ErrUserDisabled
+	}
+
+	return user, nil
+}
+
+func (a *authorizer) tokenPair(user *entities.User, sessionID uuid.UUID, refreshToken string) (*TokenPair, error) {
+	expiresAt := time.Now().Add(a.cfg.GetAccessTokenTTL())
+
+	accessToken, err := a.CreateToken(ClaimJWTParams{
+		UserId:    user.Id.String(),
+		SessionId: sessionID.String(),
+		Role:      user.Role,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		SessionID:    sessionID,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+func (a *authorizer) revokeReused(ctx context.Context, sessionID uuid.UUID) {
+	a.log.Warn().Msgf("refresh token reuse detected, revoking session %s", sessionID)
+
+	if err := a.storage.RevokeSession(ctx, sessionID); err != nil {
+		a.log.Error().Err(err).Msg("error revoke session")
+	}
+}
+
+// newRefreshToken returns the "<session id>.<secret>" token handed to the
+// client and the hash of the secret kept in the storage.
+func newRefreshToken(sessionID uuid.UUID) (string, string, error) {
+	secret := make([]byte, refreshSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("error generate refresh token: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(secret)
+
+	return sessionID.String() + "." + encoded, hashRefreshSecret(encoded), nil
+}
+
+func parseRefreshToken(token string) (uuid.UUID, string, error) {
+	id, secret, ok := strings.Cut(token, ".")
+	if !ok || secret == "" {
+		return uuid.Nil, "", ErrInvalidRefreshToken
+	}
+
+	sessionID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, "", ErrInvalidRefreshToken
+	}
+
+	return sessionID, hashRefreshSecret(secret), nil
+}
+
+// hashRefreshSecret hashes the refresh and the password reset secrets, they
+// are random, so a plain hash is enough.
+func hashRefreshSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
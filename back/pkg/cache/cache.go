@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTooLarge is returned by Set for an entry which doesn't fit into the
+// cache even when it is empty.
+var ErrTooLarge = errors.New("error cache entry is larger than the cache")
+
+// Cache keeps values by key for a while. Values are copied in and out, so
+// callers may change them afterwards.
+type Cache interface {
+	// Get returns the value of the key, ok is false if it is missing or
+	// expired.
+	Get(ctx context.Context, key string) (value []byte, ok bool)
+	// Set stores the value for ttl, a ttl of 0 keeps it until it is evicted.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+	Flush(ctx context.Context) error
+	Stats() Stats
+	// Close releases the connections of the cache, if it has any.
+	Close() error
+}
+
+// Stats counts the lookups of a cache since it was created.
+type Stats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	// Entries and Bytes are what the cache holds now, Bytes is an estimate
+	Entries int   `json:"entries"`
+	Bytes   int64 `json:"bytes"`
+}
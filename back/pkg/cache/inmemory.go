@@ -1,49 +1,174 @@
 package cache
 
 import (
-	"unsafe"
+	"container/list"
+	"context"
+	"sync"
+	"time"
 )
 
-type Cache interface {
-	Add(key string, value any) error
-	Delete(key string) error
-	Flush() error
-	MemoryLimit(limit int)
+// entryOverhead is the estimated memory of an entry besides its key and
+// value: the list element, the map bucket slot and the entry itself.
+const entryOverhead = 128
+
+type (
+	inmemory struct {
+		mu sync.Mutex
+		// 0 is no limit
+		maxEntries int
+		maxBytes   int64
+
+		// lru holds *entry, the most recently used first
+		lru     *list.List
+		entries map[string]*list.Element
+		bytes   int64
+
+		hits, misses, evictions uint64
+	}
+
+	entry struct {
+		key   string
+		value []byte
+		// zero never expires
+		expiresAt time.Time
+	}
+)
+
+// NewInMemory returns a cache evicting the least recently used entries once
+// it holds more than maxEntries entries or maxBytes bytes, 0 is no limit.
+func NewInMemory(maxEntries int, maxBytes int64) Cache {
+	return &inmemory{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+func (c *inmemory) Get(ctx context.Context, key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	e := el.Value.(*entry)
+	if e.expired(time.Now()) {
+		c.remove(el)
+		c.misses++
+		return nil, false
+	}
+
+	c.lru.MoveToFront(el)
+	c.hits++
+	return append([]byte(nil), e.value...), true
 }
 
-type inmemory struct {
-	// negative limit == "unlimited" storage size
-	memoryLimit int
-	storage     map[string]any
+func (c *inmemory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	e := &entry{key: key, value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		e.expiresAt = time.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+
+	if c.maxBytes > 0 && e.size() > c.maxBytes {
+		return ErrTooLarge
+	}
+
+	c.entries[key] = c.lru.PushFront(e)
+	c.bytes += e.size()
+
+	c.evict()
+	return nil
 }
 
-func (c *inmemory) Add(key string, value any) error {
-	if unsafe.Sizeof(c.storage) >= uintptr(c.memoryLimit) &&
-		c.memoryLimit > 0 {
-		go func() {
-			for k := range c.storage {
-				delete(c.storage, k)
-			}
-		}()
+func (c *inmemory) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
 	}
 
-	c.storage[key] = value
 	return nil
 }
 
-func (c *inmemory) Delete(key string) error {
-	delete(c.storage, key)
+func (c *inmemory) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lru.Init()
+	clear(c.entries)
+	c.bytes = 0
+
 	return nil
 }
 
-func (c *inmemory) Flush() error {
-	clear(c.storage)
+func (c *inmemory) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return Stats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Entries:   c.lru.Len(),
+		Bytes:     c.bytes,
+	}
+}
+
+func (c *inmemory) Close() error {
 	return nil
 }
 
-func (c *inmemory) MemoryLimit(limit int) {
-	c.memoryLimit = limit
+// evict removes the expired entries first and then the least recently used
+// ones until the cache is within its limits. The caller holds the lock.
+func (c *inmemory) evict() {
+	if !c.full() {
+		return
+	}
+
+	now := time.Now()
+	for el := c.lru.Back(); el != nil; {
+		prev := el.Prev()
+		if el.Value.(*entry).expired(now) {
+			c.remove(el)
+		}
+		el = prev
+	}
+
+	for c.full() {
+		c.remove(c.lru.Back())
+		c.evictions++
+	}
+}
+
+func (c *inmemory) full() bool {
+	return (c.maxEntries > 0 && c.lru.Len() > c.maxEntries) ||
+		(c.maxBytes > 0 && c.bytes > c.maxBytes)
 }
 
-// todo
-type redis struct{}
+// remove drops the entry, the caller holds the lock.
+func (c *inmemory) remove(el *list.Element) {
+	e := c.lru.Remove(el).(*entry)
+	delete(c.entries, e.key)
+	c.bytes -= e.size()
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// size is the estimated memory held by the entry.
+func (e *entry) size() int64 {
+	return int64(len(e.key) + cap(e.value) + entryOverhead)
+}
@@ -0,0 +1,424 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultPoolSize    = 10
+	defaultRESPTimeout = 200 * time.Millisecond
+	// scanCount is how many keys Flush asks for at a time
+	scanCount = 100
+)
+
+// ErrUnavailable is returned while the server can't be reached, reads are
+// misses meanwhile. Deletes are still sent, so no stale entry is left behind
+// once the server is back.
+var ErrUnavailable = errors.New("error cache server is unavailable")
+
+type (
+	// RESPOptions configures a cache kept on a server speaking the Redis
+	// protocol.
+	RESPOptions struct {
+		Address  string
+		Password string
+		// DB is selected on every connection unless it is 0
+		DB int
+		// Namespace prefixes every key, so environments can share a server
+		Namespace string
+		// PoolSize is the most connections open at a time
+		PoolSize int
+		// Timeout bounds a dial and each command, it is also how long the
+		// server is left alone after it couldn't be reached
+		Timeout time.Duration
+	}
+
+	respCache struct {
+		opts RESPOptions
+		pool *respPool
+		// downUntil is the unix nano time until which the server is
+		// considered unreachable
+		downUntil atomic.Int64
+
+		hits, misses atomic.Uint64
+	}
+
+	respPool struct {
+		dial func() (*respConn, error)
+		// slots holds a token for each connection which may be opened
+		slots chan struct{}
+
+		// mu guards idle against close, a connection given back after
+		// close is closed instead of kept
+		mu     sync.Mutex
+		idle   chan *respConn
+		closed bool
+	}
+
+	respConn struct {
+		conn net.Conn
+		r    *bufio.Reader
+		w    *bufio.Writer
+	}
+
+	// respError is an error reply of the server, the connection is still
+	// usable after it.
+	respError string
+)
+
+func (e respError) Error() string {
+	return "error cache server: " + string(e)
+}
+
+// NewRESP returns a cache kept on a Redis compatible server. Connections are
+// opened when they are needed and kept for reuse. The cache degrades to
+// misses while the server can't be reached.
+func NewRESP(opts RESPOptions) Cache {
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = defaultPoolSize
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultRESPTimeout
+	}
+
+	c := &respCache{opts: opts}
+	c.pool = &respPool{
+		dial:  c.dial,
+		slots: make(chan struct{}, opts.PoolSize),
+		idle:  make(chan *respConn, opts.PoolSize),
+	}
+	for i := 0; i < opts.PoolSize; i++ {
+		c.pool.slots <- struct{}{}
+	}
+
+	return c
+}
+
+func (c *respCache) Get(ctx context.Context, key string) ([]byte, bool) {
+	reply, err := c.do(ctx, "GET", c.key(key))
+	value, ok := reply.([]byte)
+	if err != nil || !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	return value, true
+}
+
+func (c *respCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []any{"SET", c.key(key), value}
+	if ttl > 0 {
+		args = append(args, "PX", max(ttl.Milliseconds(), 1))
+	}
+
+	if _, err := c.do(ctx, args...); err != nil {
+		return fmt.Errorf("error set cache entry: %w", err)
+	}
+
+	return nil
+}
+
+func (c *respCache) Delete(ctx context.Context, key string) error {
+	if _, err := c.retry(ctx, "DEL", c.key(key)); err != nil {
+		return fmt.Errorf("error delete cache entry: %w", err)
+	}
+
+	return nil
+}
+
+// Flush deletes the keys of the namespace, the other keys of the server are
+// kept.
+func (c *respCache) Flush(ctx context.Context) error {
+	pattern := globEscape(c.key("")) + "*"
+
+	cursor := "0"
+	for {
+		reply, err := c.retry(ctx, "SCAN", cursor, "MATCH", pattern, "COUNT", scanCount)
+		if err != nil {
+			return fmt.Errorf("error scan cache entries: %w", err)
+		}
+
+		page, ok := reply.([]any)
+		if !ok || len(page) != 2 {
+			return fmt.Errorf("error scan cache entries: unexpected reply %v", reply)
+		}
+
+		next, _ := page[0].([]byte)
+		keys, _ := page[1].([]any)
+		if len(keys) > 0 {
+			if _, err := c.retry(ctx, append([]any{"DEL"}, keys...)...); err != nil {
+				return fmt.Errorf("error delete cache entries: %w", err)
+			}
+		}
+
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return nil
+		}
+	}
+}
+
+// Stats counts the lookups of this node, what the server holds isn't known.
+func (c *respCache) Stats() Stats {
+	return Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+}
+
+func (c *respCache) Close() error {
+	return c.pool.close()
+}
+
+func (c *respCache) key(key string) string {
+	if c.opts.Namespace == "" {
+		return key
+	}
+
+	return c.opts.Namespace + ":" + key
+}
+
+// do sends the command on a pooled connection and returns the reply. After a
+// connection failed the server is left alone for a while, so a missing cache
+// doesn't slow down every request.
+func (c *respCache) do(ctx context.Context, args ...any) (any, error) {
+	if time.Now().UnixNano() < c.downUntil.Load() {
+		return nil, ErrUnavailable
+	}
+
+	return c.send(ctx, args...)
+}
+
+// retry sends the command even while the server is left alone and sends it
+// once more after a connection failure. Deletes go this way, a dropped read
+// or set is only a miss but a dropped delete leaves a stale entry.
+func (c *respCache) retry(ctx context.Context, args ...any) (any, error) {
+	reply, err := c.send(ctx, args...)
+	if errors.Is(err, ErrUnavailable) && ctx.Err() == nil {
+		reply, err = c.send(ctx, args...)
+	}
+
+	return reply, err
+}
+
+// send sends the command on a pooled connection. A connection which failed
+// is closed together with the idle ones, they are likely broken as well.
+func (c *respCache) send(ctx context.Context, args ...any) (any, error) {
+	conn, err := c.pool.get(ctx)
+	if err != nil {
+		if !errors.Is(err, ctx.Err()) {
+			c.downUntil.Store(time.Now().Add(c.opts.Timeout).UnixNano())
+		}
+		return nil, err
+	}
+
+	deadline := time.Now().Add(c.opts.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.conn.SetDeadline(deadline)
+
+	reply, err := conn.do(args...)
+
+	var replyErr respError
+	if err != nil && !errors.As(err, &replyErr) {
+		c.pool.put(conn, false)
+		c.pool.closeIdle()
+		c.downUntil.Store(time.Now().Add(c.opts.Timeout).UnixNano())
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	c.pool.put(conn, true)
+	return reply, err
+}
+
+func (c *respCache) dial() (*respConn, error) {
+	conn, err := net.DialTimeout("tcp", c.opts.Address, c.opts.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	rc := &respConn{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+	conn.SetDeadline(time.Now().Add(c.opts.Timeout))
+
+	if c.opts.Password != "" {
+		if _, err := rc.do("AUTH", c.opts.Password); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("error authenticate to cache server: %w", err)
+		}
+	}
+
+	if c.opts.DB != 0 {
+		if _, err := rc.do("SELECT", c.opts.DB); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("error select cache database: %w", err)
+		}
+	}
+
+	return rc, nil
+}
+
+// get returns an idle connection or dials a new one, it waits for a free
+// slot if the pool is full.
+func (p *respPool) get(ctx context.Context) (*respConn, error) {
+	select {
+	case <-p.slots:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case conn := <-p.idle:
+		return conn, nil
+	default:
+	}
+
+	conn, err := p.dial()
+	if err != nil {
+		p.slots <- struct{}{}
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// put gives the connection back, one that isn't healthy or is given back
+// after close is closed.
+func (p *respPool) put(conn *respConn, healthy bool) {
+	p.mu.Lock()
+	if healthy && !p.closed {
+		p.idle <- conn
+	} else {
+		conn.conn.Close()
+	}
+	p.mu.Unlock()
+
+	p.slots <- struct{}{}
+}
+
+// close closes the idle connections, the ones in use are closed when they
+// are given back.
+func (p *respPool) close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	return p.closeIdle()
+}
+
+// closeIdle closes the connections waiting in the pool.
+func (p *respPool) closeIdle() error {
+	var errs []error
+	for {
+		select {
+		case conn := <-p.idle:
+			errs = append(errs, conn.conn.Close())
+		default:
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// do writes the command as an array of bulk strings and reads the reply.
+func (c *respConn) do(args ...any) (any, error) {
+	fmt.Fprintf(c.w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		var b []byte
+		switch v := arg.(type) {
+		case []byte:
+			b = v
+		case string:
+			b = []byte(v)
+		case int:
+			b = strconv.AppendInt(nil, int64(v), 10)
+		case int64:
+			b = strconv.AppendInt(nil, v, 10)
+		default:
+			return nil, fmt.Errorf("error unsupported cache command argument %T", arg)
+		}
+
+		fmt.Fprintf(c.w, "$%d\r\n", len(b))
+		c.w.Write(b)
+		c.w.WriteString("\r\n")
+	}
+
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return c.read()
+}
+
+// read returns a reply: a string for a simple string, an int64 for an
+// integer, []byte or nil for a bulk string and []any for an array.
+func (c *respConn) read() (any, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("error malformed cache reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, respError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+
+		b := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, b); err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+
+		items := make([]any, 0, n)
+		for i := 0; i < n; i++ {
+			item, err := c.read()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("error unknown cache reply %q", line)
+	}
+}
+
+// globEscape escapes the characters SCAN MATCH treats as a pattern.
+func globEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package changes
+
+import (
+	"context"
+	"languago/pkg/models/entities"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type (
+	// Change tells that an entity was written. A change without an entity
+	// tells that any entity may have been, it is sent when changes may have
+	// been missed.
+	Change struct {
+		Entity entities.EntityType `json:"entity,omitempty"`
+		ID     uuid.UUID           `json:"id,omitempty"`
+		Owner  uuid.UUID           `json:"owner,omitempty"`
+	}
+
+	// Bus delivers the changes published by any node to the subscribers of
+	// every node. Delivery is best effort, subscribers should only use
+	// changes to drop what they keep.
+	Bus interface {
+		Publish(ctx context.Context, change Change) error
+		// Subscribe calls fn for every change until cancel is called, fn
+		// must not block.
+		Subscribe(fn func(Change)) (cancel func())
+		Close() error
+	}
+
+	localBus struct {
+		mu          sync.Mutex
+		next        int
+		subscribers map[int]func(Change)
+	}
+)
+
+// All is the change sent when changes may have been missed.
+var All = Change{}
+
+// NewLocalBus returns a bus for a single process, changes are delivered
+// before Publish returns.
+func NewLocalBus() Bus {
+	return &localBus{subscribers: make(map[int]func(Change))}
+}
+
+func (b *localBus) Publish(_ context.Context, change Change) error {
+	b.deliver(change)
+	return nil
+}
+
+func (b *localBus) Subscribe(fn func(Change)) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.next
+	b.next++
+	b.subscribers[id] = fn
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers, id)
+	}
+}
+
+// deliver calls the subscribers with the change, it is how a bus over the
+// network hands over what it received.
+func (b *localBus) deliver(change Change) {
+	b.mu.Lock()
+	subscribers := make([]func(Change), 0, len(b.subscribers))
+	for _, fn := range b.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	b.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(change)
+	}
+}
+
+func (b *localBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	clear(b.subscribers)
+	return nil
+}
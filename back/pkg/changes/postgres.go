@@ -0,0 +1,103 @@
+package changes
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	minReconnect = 100 * time.Millisecond
+	maxReconnect = 10 * time.Second
+	// pingInterval is how often an idle listener checks its connection, a
+	// broken one is only noticed when it is used
+	pingInterval = 90 * time.Second
+)
+
+type postgresBus struct {
+	*localBus
+	db       *sql.DB
+	channel  string
+	listener *pq.Listener
+	done     chan struct{}
+}
+
+// NewPostgresBus returns a bus over PostgreSQL LISTEN/NOTIFY on the channel.
+// Changes are published through db and received on a connection of their
+// own to dsn, which is opened again when it breaks. The subscribers get All
+// once it is, the changes sent meanwhile are lost. The bus doesn't wait for
+// the connection, changes published before it is open aren't received.
+func NewPostgresBus(db *sql.DB, dsn, channel string) Bus {
+	b := &postgresBus{
+		localBus: &localBus{subscribers: make(map[int]func(Change))},
+		db:       db,
+		channel:  channel,
+		done:     make(chan struct{}),
+	}
+
+	b.listener = pq.NewListener(dsn, minReconnect, maxReconnect, nil)
+	go b.receive()
+
+	return b
+}
+
+// Publish notifies the listeners of every node, this one included.
+func (b *postgresBus) Publish(ctx context.Context, change Change) error {
+	payload, err := json.Marshal(change)
+	if err != nil {
+		return fmt.Errorf("error encode change: %w", err)
+	}
+
+	if _, err := b.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", b.channel, string(payload)); err != nil {
+		return fmt.Errorf("error publish change: %w", err)
+	}
+
+	return nil
+}
+
+func (b *postgresBus) receive() {
+	defer close(b.done)
+
+	// Listen waits for the connection, it only fails once the bus is closed
+	if err := b.listener.Listen(b.channel); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case n, ok := <-b.listener.Notify:
+			if !ok {
+				return
+			}
+
+			// the listener sends nil once it reconnected
+			if n == nil {
+				b.deliver(All)
+				continue
+			}
+
+			var change Change
+			if err := json.Unmarshal([]byte(n.Extra), &change); err != nil {
+				continue
+			}
+			b.deliver(change)
+		case <-ticker.C:
+			go b.listener.Ping()
+		}
+	}
+}
+
+func (b *postgresBus) Close() error {
+	err := b.listener.Close()
+	<-b.done
+
+	b.localBus.Close()
+	return err
+}
@@ -8,21 +8,30 @@ import (
 )
 
 type Closer interface {
+	// Close calls the registered funcs in the reverse order they were
+	// added, so what was opened last is closed first. It gives up once ctx
+	// is done, the ShutdownFuncs get ctx to stop in time.
 	Close(ctx context.Context) error
 	Add(f CloseFunc)
+	AddShutdown(f ShutdownFunc)
 }
 
 type closer struct {
 	m          sync.Mutex
-	closeFuncs []CloseFunc
+	closeFuncs []ShutdownFunc
 }
 
-type CloseFunc func() error
+type (
+	CloseFunc func() error
+	// ShutdownFunc stops something which has to be drained, like a server
+	// finishing its requests.
+	ShutdownFunc func(ctx context.Context) error
+)
 
 func NewCloser() Closer {
 	return &closer{
 		m:          sync.Mutex{},
-		closeFuncs: make([]CloseFunc, 0),
+		closeFuncs: make([]ShutdownFunc, 0),
 	}
 }
 
@@ -31,21 +40,24 @@ func (c *closer) Close(ctx context.Context) error {
 	defer c.m.Unlock()
 
 	var (
-		msgs     = make([]string, 0, len(c.closeFuncs))
-		complete = make(chan struct{}, 1)
+		closeFuncs = c.closeFuncs
+		complete   = make(chan []string, 1)
 	)
+	c.closeFuncs = nil
 
 	go func() {
-		for _, f := range c.closeFuncs {
-			if err := f(); err != nil {
+		msgs := make([]string, 0, len(closeFuncs))
+		for i := len(closeFuncs) - 1; i >= 0; i-- {
+			if err := closeFuncs[i](ctx); err != nil {
 				msgs = append(msgs, err.Error())
 			}
 		}
-		complete <- struct{}{}
+		complete <- msgs
 	}()
 
+	var msgs []string
 	select {
-	case <-complete:
+	case msgs = <-complete:
 		break
 	case <-ctx.Done():
 		return fmt.Errorf("error shutdown canceled: %w", ctx.Err())
@@ -60,6 +72,10 @@ func (c *closer) Close(ctx context.Context) error {
 }
 
 func (c *closer) Add(f CloseFunc) {
+	c.AddShutdown(func(context.Context) error { return f() })
+}
+
+func (c *closer) AddShutdown(f ShutdownFunc) {
 	c.m.Lock()
 	defer c.m.Unlock()
 	c.closeFuncs = append(c.closeFuncs, f)
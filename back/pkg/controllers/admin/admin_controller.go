@@ -0,0 +1,185 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"languago/infrastructure/repository"
+	"languago/pkg/auth"
+	"languago/pkg/ctxtools"
+	"languago/pkg/models"
+	"languago/pkg/models/requests/rest"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultUsersLimit = 50
+	maxUsersLimit     = 500
+)
+
+var (
+	ErrInvalidRole = errors2.New(errors2.CodeBadRequest, "role must be one of user, teacher, admin", errors2.ErrValidation)
+	ErrInvalidPage = errors2.New(errors2.CodeBadRequest, fmt.Sprintf("limit must be 0-%d, offset must not be negative", maxUsersLimit), errors2.ErrValidation)
+	ErrSelfLockout = errors2.New(errors2.CodeBadRequest, "administrators can't disable or demote themselves", errors2.ErrBadRequest)
+)
+
+type AdminController interface {
+	ListUsers(ctx context.Context, req *rest.ListUsersRequest) (*rest.ListUsersResponse, error)
+	// DisableUser locks the user out, the sessions of the user are revoked.
+	DisableUser(ctx context.Context, userID uuid.UUID) error
+	EnableUser(ctx context.Context, userID uuid.UUID) error
+	// ForcePasswordReset revokes the sessions of the user, who has to choose
+	// a new password with the returned one-time token before signing in again.
+	ForcePasswordReset(ctx context.Context, userID uuid.UUID) (*rest.PasswordResetResponse, error)
+	SetRole(ctx context.Context, userID uuid.UUID, req *rest.SetRoleRequest) error
+}
+
+type adminController struct {
+	log     zerolog.Logger
+	storage repository.DatabaseInteractor
+}
+
+func NewAdminController(
+	log zerolog.Logger,
+	storage repository.DatabaseInteractor,
+) AdminController {
+	return &adminController{
+		log:     log,
+		storage: storage,
+	}
+}
+
+func (c *adminController) ListUsers(ctx context.Context, req *rest.ListUsersRequest) (*rest.ListUsersResponse, error) {
+	if req.Limit < 0 || req.Limit > maxUsersLimit || req.Offset < 0 {
+		return nil, ErrInvalidPage
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultUsersLimit
+	}
+
+	users, err := c.storage.Database().SelectUsers(ctx, repository.SelectUsersParams{
+		Limit:  limit,
+		Offset: req.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error select users: %w", err)
+	}
+
+	return &rest.ListUsersResponse{
+		Users: users,
+	}, nil
+}
+
+func (c *adminController) DisableUser(ctx context.Context, userID uuid.UUID) error {
+	if err := c.notSelf(ctx, userID); err != nil {
+		return err
+	}
+
+	disabled := true
+
+	// the user is disabled only together with revoking the sessions, a user
+	// left with live sessions would stay signed in
+	return c.storage.WithTx(ctx, func(storage repository.Storage) error {
+		err := storage.UpdateUser(ctx, repository.UpdateUserParams{
+			ID:       userID,
+			Disabled: &disabled,
+		})
+		if err != nil {
+			return fmt.Errorf("error disable user: %w", err)
+		}
+
+		return revokeSessions(ctx, storage, userID)
+	})
+}
+
+func (c *adminController) EnableUser(ctx context.Context, userID uuid.UUID) error {
+	enabled := false
+
+	err := c.storage.Database().UpdateUser(ctx, repository.UpdateUserParams{
+		ID:       userID,
+		Disabled: &enabled,
+	})
+	if err != nil {
+		return fmt.Errorf("error enable user: %w", err)
+	}
+
+	return nil
+}
+
+func (c *adminController) ForcePasswordReset(ctx context.Context, userID uuid.UUID) (*rest.PasswordResetResponse, error) {
+	token, hash, err := auth.NewResetToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.storage.WithTx(ctx, func(storage repository.Storage) error {
+		err := storage.UpdateUser(ctx, repository.UpdateUserParams{
+			ID:                userID,
+			PasswordResetHash: hash,
+		})
+		if err != nil {
+			return fmt.Errorf("error require password reset: %w", err)
+		}
+
+		return revokeSessions(ctx, storage, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &rest.PasswordResetResponse{
+		ResetToken: token,
+	}, nil
+}
+
+func (c *adminController) SetRole(ctx context.Context, userID uuid.UUID, req *rest.SetRoleRequest) error {
+	if !req.Role.Valid() {
+		return ErrInvalidRole
+	}
+
+	if req.Role != models.RoleAdmin {
+		if err := c.notSelf(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	err := c.storage.Database().UpdateUser(ctx, repository.UpdateUserParams{
+		ID:   userID,
+		Role: req.Role,
+	})
+	if err != nil {
+		return fmt.Errorf("error set role: %w", err)
+	}
+
+	// access tokens with the old role are rejected from now on, refreshing
+	// the session issues one with the new role
+	return nil
+}
+
+// notSelf keeps administrators from locking themselves out, leaving no one
+// able to undo it.
+func (c *adminController) notSelf(ctx context.Context, userID uuid.UUID) error {
+	caller := ctxtools.User(ctx)
+	if caller == nil {
+		return errors2.ErrUnauthorized
+	}
+
+	if caller.Id == userID {
+		return ErrSelfLockout
+	}
+
+	return nil
+}
+
+func revokeSessions(ctx context.Context, storage repository.Storage, userID uuid.UUID) error {
+	if err := storage.RevokeUserSessions(ctx, userID); err != nil {
+		return fmt.Errorf("error revoke sessions: %w", err)
+	}
+
+	return nil
+}
@@ -1 +1,177 @@
 package decks
+
+import (
+	"context"
+	"fmt"
+	"languago/infrastructure/repository"
+	"languago/pkg/ctxtools"
+	"languago/pkg/models/entities"
+	"languago/pkg/models/requests/rest"
+	"strings"
+	"unicode/utf8"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const maxDeckNameLength = 200
+
+var ErrInvalidDeckName = errors2.New(
+	errors2.CodeBadRequest,
+	fmt.Sprintf("deck name must be 1-%d characters long", maxDeckNameLength),
+	errors2.ErrValidation,
+)
+
+type DecksController interface {
+	CreateDeck(ctx context.Context, req *rest.CreateDeckRequest) (*rest.CreateDeckResponse, error)
+	GetDeck(ctx context.Context, deckID uuid.UUID) (*rest.GetDeckResponse, error)
+	ListDecks(ctx context.Context, req *rest.ListDecksRequest) (*rest.ListDecksResponse, error)
+	EditDeck(ctx context.Context, deckID uuid.UUID, req *rest.EditDeckRequest) error
+	DeleteDeck(ctx context.Context, deckID uuid.UUID) error
+	AddFlashcard(ctx context.Context, deckID uuid.UUID, cardID uuid.UUID) error
+	RemoveFlashcard(ctx context.Context, deckID uuid.UUID, cardID uuid.UUID) error
+}
+
+type decksController struct {
+	log     zerolog.Logger
+	storage repository.DatabaseInteractor
+}
+
+func NewDecksController(
+	log zerolog.Logger,
+	storage repository.DatabaseInteractor,
+) DecksController {
+	return &decksController{
+		log:     log,
+		storage: storage,
+	}
+}
+
+func (c *decksController) CreateDeck(ctx context.Context, req *rest.CreateDeckRequest) (*rest.CreateDeckResponse, error) {
+	owner, err := ownerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := validateName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	deck := &entities.Deck{
+		Id:    uuid.New(),
+		Name:  name,
+		Owner: owner,
+	}
+
+	err = c.storage.Database().CreateDeck(ctx, repository.CreateDeckParams{
+		ID:   deck.Id,
+		Name: deck.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error create deck: %w", err)
+	}
+
+	return &rest.CreateDeckResponse{
+		Deck: deck,
+	}, nil
+}
+
+func (c *decksController) GetDeck(ctx context.Context, deckID uuid.UUID) (*rest.GetDeckResponse, error) {
+	deck, err := c.storage.Database().SelectDeck(ctx, repository.SelectDeckParams{ID: deckID})
+	if err != nil {
+		return nil, fmt.Errorf("error select deck: %w", err)
+	}
+
+	cards, err := c.storage.Database().SelectFromDeck(ctx, repository.SelectFromDeckParams{DeckID: deckID})
+	if err != nil {
+		return nil, fmt.Errorf("error select deck flashcards: %w", err)
+	}
+
+	return &rest.GetDeckResponse{
+		Deck:       deck,
+		Flashcards: cards,
+	}, nil
+}
+
+func (c *decksController) ListDecks(ctx context.Context, req *rest.ListDecksRequest) (*rest.ListDecksResponse, error) {
+	decks, err := c.storage.Database().SelectDecks(ctx, repository.SelectDeckParams{Name: req.Name})
+	if err != nil {
+		return nil, fmt.Errorf("error select decks: %w", err)
+	}
+
+	return &rest.ListDecksResponse{
+		Decks: decks,
+	}, nil
+}
+
+func (c *decksController) EditDeck(ctx context.Context, deckID uuid.UUID, req *rest.EditDeckRequest) error {
+	name, err := validateName(req.Name)
+	if err != nil {
+		return err
+	}
+
+	err = c.storage.Database().UpdateDeck(ctx, repository.UpdateDeckParams{
+		ID:      deckID,
+		Name:    name,
+		Version: req.Version,
+	})
+	if err != nil {
+		return fmt.Errorf("error update deck: %w", err)
+	}
+
+	return nil
+}
+
+func (c *decksController) DeleteDeck(ctx context.Context, deckID uuid.UUID) error {
+	err := c.storage.Database().DeleteDeck(ctx, repository.DeleteDeckParams{ID: deckID})
+	if err != nil {
+		return fmt.Errorf("error delete deck: %w", err)
+	}
+
+	return nil
+}
+
+func (c *decksController) AddFlashcard(ctx context.Context, deckID uuid.UUID, cardID uuid.UUID) error {
+	err := c.storage.Database().AddToDeck(ctx, repository.AddToDeckParams{
+		DeckID:      deckID,
+		FlashcardID: cardID,
+	})
+	if err != nil {
+		return fmt.Errorf("error add flashcard to deck: %w", err)
+	}
+
+	return nil
+}
+
+func (c *decksController) RemoveFlashcard(ctx context.Context, deckID uuid.UUID, cardID uuid.UUID) error {
+	err := c.storage.Database().DeleteFromDeck(ctx, repository.DeleteFromDeckParams{
+		DeckID:      deckID,
+		FlashcardID: cardID,
+	})
+	if err != nil {
+		return fmt.Errorf("error remove flashcard from deck: %w", err)
+	}
+
+	return nil
+}
+
+func ownerID(ctx context.Context) (uuid.UUID, error) {
+	user := ctxtools.User(ctx)
+	if user == nil || user.Id == uuid.Nil {
+		return uuid.Nil, fmt.Errorf("error fetch user from context: %w", errors2.ErrUnauthorized)
+	}
+
+	return user.Id, nil
+}
+
+func validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxDeckNameLength {
+		return "", ErrInvalidDeckName
+	}
+
+	return name, nil
+}
@@ -2,16 +2,71 @@ package flashcards
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"languago/infrastructure/repository"
+	"languago/pkg/models/entities"
 	"languago/pkg/models/requests/rest"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	errors2 "languago/pkg/errors"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 )
 
+const (
+	defaultFlashcardsLimit = 50
+	maxFlashcardsLimit     = 200
+
+	maxTags      = 20
+	maxTagLength = 50
+
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxQueryLength     = 200
+)
+
+var (
+	ErrInvalidPage = errors2.New(
+		errors2.CodeBadRequest,
+		fmt.Sprintf("limit must be 0-%d, sort one of created, updated, word and order asc or desc", maxFlashcardsLimit),
+		errors2.ErrValidation,
+	)
+	ErrInvalidCursor = errors2.New(errors2.CodeBadRequest, "cursor is invalid or doesn't match the sort order", errors2.ErrValidation)
+	ErrInvalidSearch = errors2.New(
+		errors2.CodeBadRequest,
+		fmt.Sprintf("query must have 1-%d characters and limit be 0-%d", maxQueryLength, maxSearchLimit),
+		errors2.ErrValidation,
+	)
+	ErrInvalidTags = errors2.New(
+		errors2.CodeBadRequest,
+		fmt.Sprintf("a card can have up to %d tags of 1-%d characters", maxTags, maxTagLength),
+		errors2.ErrValidation,
+	)
+)
+
 type FlashcardsController interface {
 	CreateFlashcard(ctx context.Context, req *rest.NewFlashcardRequest) error
+	// ListFlashcards returns a page of the caller's cards, the next page
+	// starts after the last card of this one.
+	ListFlashcards(ctx context.Context, req *rest.ListFlashcardsRequest) (*rest.ListFlashcardsResponse, error)
+	// PatchFlashcard applies a JSON Merge Patch to the card and returns the
+	// patched card. All of the patch is applied or none of it.
+	PatchFlashcard(ctx context.Context, cardID uuid.UUID, req *rest.PatchFlashcardRequest) (*entities.Flashcard, error)
+	// SearchFlashcards returns the caller's cards matching the query, best
+	// first, with the matching text highlighted.
+	SearchFlashcards(ctx context.Context, req *rest.SearchFlashcardsRequest) (*rest.SearchFlashcardsResponse, error)
+	// FlashcardHistory returns the revisions of the caller's card, deleted
+	// cards included.
+	FlashcardHistory(ctx context.Context, cardID uuid.UUID) (*rest.FlashcardHistoryResponse, error)
+	// RevertFlashcard changes the card back to a revision of its history and
+	// returns the reverted card. The revert is a revision too.
+	RevertFlashcard(ctx context.Context, cardID uuid.UUID, req *rest.RevertFlashcardRequest) (*entities.Flashcard, error)
 	GetFlashcard(ctx context.Context, args GetFlashcardParams) (*rest.GetFlashcardResponse, error)
 	DeleteFlashcard(ctx context.Context, args DeleteFlashcardRequest) error
 	EditFlashcard(ctx context.Context, args *rest.EditFlashcardRequest) error
@@ -33,17 +88,234 @@ func NewFlashcardsController(
 }
 
 func (c *flashcardController) CreateFlashcard(ctx context.Context, req *rest.NewFlashcardRequest) error {
-	err := c.storage.Database().CreateFlashcard(ctx, repository.CreateFlashcardParams{
-		ID:      uuid.New(),
-		Word:    req.Content.WordInTarget,
-		Meaning: req.Content.WordInNative,
-		Usage:   req.Content.UsageExamples,
+	tags, err := NormalizeTags(req.Tags)
+	if err != nil {
+		return err
+	}
+
+	return c.storage.WithTx(ctx, func(storage repository.Storage) error {
+		cardID := uuid.New()
+
+		err := storage.CreateFlashcard(ctx, repository.CreateFlashcardParams{
+			ID:      cardID,
+			Word:    req.Content.WordInTarget,
+			Meaning: req.Content.WordInNative,
+			Usage:   req.Content.UsageExamples,
+			Tags:    tags,
+		})
+		if err != nil {
+			return fmt.Errorf("error create flashcard: %w", err)
+		}
+
+		if req.DeckID == uuid.Nil {
+			return nil
+		}
+
+		err = storage.AddToDeck(ctx, repository.AddToDeckParams{
+			DeckID:      req.DeckID,
+			FlashcardID: cardID,
+		})
+		if err != nil {
+			return fmt.Errorf("error add flashcard to deck: %w", err)
+		}
+
+		return nil
 	})
+}
+
+func (c *flashcardController) ListFlashcards(ctx context.Context, req *rest.ListFlashcardsRequest) (*rest.ListFlashcardsResponse, error) {
+	sort := repository.FlashcardSort(req.Sort)
+	if sort == "" {
+		sort = repository.SortByCreated
+	}
+
+	if req.Limit < 0 || req.Limit > maxFlashcardsLimit || !sort.Valid() {
+		return nil, ErrInvalidPage
+	}
+
+	var desc bool
+	switch req.Order {
+	case "", "asc":
+	case "desc":
+		desc = true
+	default:
+		return nil, ErrInvalidPage
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultFlashcardsLimit
+	}
+
+	params := repository.SelectFlashcardParams{
+		DeckID: req.DeckID,
+		Tag:    strings.ToLower(strings.TrimSpace(req.Tag)),
+		Prefix: req.Prefix,
+		Sort:   sort,
+		Desc:   desc,
+		// one more card tells whether there is a next page
+		Limit: limit + 1,
+	}
+
+	if req.Due {
+		params.DueAt = time.Now()
+	}
+
+	if req.Cursor != "" {
+		after, err := decodeCursor(req.Cursor, sort, desc)
+		if err != nil {
+			return nil, err
+		}
+		params.After = after
+	}
+
+	cards, err := c.storage.Database().SelectFlashcard(ctx, params)
 	if err != nil {
-		return fmt.Errorf("error create flashcard: %w", err)
+		return nil, fmt.Errorf("error list flashcards: %w", err)
 	}
 
-	return nil
+	resp := &rest.ListFlashcardsResponse{
+		Flashcards: cards,
+	}
+
+	if len(cards) > limit {
+		resp.Flashcards = cards[:limit]
+		resp.NextCursor = encodeCursor(repository.NewFlashcardCursor(cards[limit-1], sort), sort, desc)
+	}
+
+	return resp, nil
+}
+
+func (c *flashcardController) PatchFlashcard(ctx context.Context, cardID uuid.UUID, req *rest.PatchFlashcardRequest) (*entities.Flashcard, error) {
+	params := repository.UpdateFlashcardParams{
+		ID:      cardID,
+		Version: req.Version,
+	}
+
+	// a null member is decoded as the zero value, which clears the field
+	if req.WordInNative.Set {
+		params.Meaning = &req.WordInNative.Value
+	}
+	if req.WordInTarget.Set {
+		params.Word = &req.WordInTarget.Value
+	}
+	if req.UsageExamples.Set {
+		params.Usage = make([]string, 0, len(req.UsageExamples.Value))
+		params.Usage = append(params.Usage, req.UsageExamples.Value...)
+	}
+	if req.Tags.Set {
+		tags, err := NormalizeTags(req.Tags.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		params.Tags = make([]string, 0, len(tags))
+		params.Tags = append(params.Tags, tags...)
+	}
+
+	var card *entities.Flashcard
+	err := c.storage.WithTx(ctx, func(storage repository.Storage) error {
+		if err := storage.UpdateFlashcard(ctx, params); err != nil {
+			return fmt.Errorf("error update flashcard: %w", err)
+		}
+
+		cards, err := storage.SelectFlashcard(ctx, repository.SelectFlashcardParams{ID: cardID})
+		if err != nil {
+			return fmt.Errorf("error select flashcard: %w", err)
+		}
+
+		card = cards[0]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return card, nil
+}
+
+func (c *flashcardController) SearchFlashcards(ctx context.Context, req *rest.SearchFlashcardsRequest) (*rest.SearchFlashcardsResponse, error) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" || utf8.RuneCountInString(query) > maxQueryLength || req.Limit < 0 || req.Limit > maxSearchLimit {
+		return nil, ErrInvalidSearch
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+
+	matches, err := c.storage.Database().SearchFlashcards(ctx, repository.SearchFlashcardsParams{
+		Query: query,
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error search flashcards: %w", err)
+	}
+
+	return &rest.SearchFlashcardsResponse{Results: matches}, nil
+}
+
+func (c *flashcardController) FlashcardHistory(ctx context.Context, cardID uuid.UUID) (*rest.FlashcardHistoryResponse, error) {
+	revisions, err := c.storage.Database().SelectRevisions(ctx, repository.SelectRevisionsParams{
+		EntityType: entities.EntityFlashcard,
+		EntityID:   cardID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error select revisions: %w", err)
+	}
+
+	if len(revisions) == 0 {
+		return nil, errors2.ErrNotFound
+	}
+
+	return &rest.FlashcardHistoryResponse{Revisions: revisions}, nil
+}
+
+func (c *flashcardController) RevertFlashcard(ctx context.Context, cardID uuid.UUID, req *rest.RevertFlashcardRequest) (*entities.Flashcard, error) {
+	var card *entities.Flashcard
+	err := c.storage.WithTx(ctx, func(storage repository.Storage) error {
+		revision, err := storage.SelectRevision(ctx, repository.SelectRevisionsParams{
+			ID:         req.RevisionID,
+			EntityType: entities.EntityFlashcard,
+			EntityID:   cardID,
+		})
+		if err != nil {
+			return fmt.Errorf("error select revision: %w", err)
+		}
+
+		var snapshot repository.FlashcardSnapshot
+		if err := json.Unmarshal(revision.Snapshot, &snapshot); err != nil {
+			return fmt.Errorf("error decode revision snapshot: %w", err)
+		}
+
+		// a deleted card has to be restored before it can be reverted, the
+		// trash state of the revision is left as it is
+		err = storage.UpdateFlashcard(ctx, repository.UpdateFlashcardParams{
+			ID:      cardID,
+			Word:    &snapshot.Word,
+			Meaning: &snapshot.Meaning,
+			Usage:   append(make([]string, 0, len(snapshot.Usage)), snapshot.Usage...),
+			Tags:    append(make([]string, 0, len(snapshot.Tags)), snapshot.Tags...),
+			Version: req.Version,
+		})
+		if err != nil {
+			return fmt.Errorf("error update flashcard: %w", err)
+		}
+
+		cards, err := storage.SelectFlashcard(ctx, repository.SelectFlashcardParams{ID: cardID})
+		if err != nil {
+			return fmt.Errorf("error select flashcard: %w", err)
+		}
+
+		card = cards[0]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return card, nil
 }
 
 type GetFlashcardParams struct {
@@ -66,3 +338,55 @@ func (c *flashcardController) DeleteFlashcard(ctx context.Context, args DeleteFl
 func (c *flashcardController) EditFlashcard(ctx context.Context, args *rest.EditFlashcardRequest) error {
 	return nil
 }
+
+// cursor is the opaque page cursor, it holds the order it was made for so it
+// can't be used with another one.
+type cursor struct {
+	Sort  repository.FlashcardSort    `json:"s"`
+	Desc  bool                        `json:"d,omitempty"`
+	After *repository.FlashcardCursor `json:"a"`
+}
+
+func encodeCursor(after *repository.FlashcardCursor, sort repository.FlashcardSort, desc bool) string {
+	raw, _ := json.Marshal(cursor{Sort: sort, Desc: desc, After: after})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string, sort repository.FlashcardSort, desc bool) (*repository.FlashcardCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.After == nil || c.Sort != sort || c.Desc != desc {
+		return nil, ErrInvalidCursor
+	}
+
+	return c.After, nil
+}
+
+// NormalizeTags trims and lowercases the tags, duplicates are dropped.
+func NormalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+
+	resp := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, ErrInvalidTags
+		}
+
+		if !slices.Contains(resp, tag) {
+			resp = append(resp, tag)
+		}
+	}
+
+	if len(resp) > maxTags {
+		return nil, ErrInvalidTags
+	}
+
+	return resp, nil
+}
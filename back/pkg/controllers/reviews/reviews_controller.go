@@ -0,0 +1,145 @@
+package reviews
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"languago/infrastructure/repository"
+	"languago/pkg/ctxtools"
+	"languago/pkg/models/entities"
+	"languago/pkg/models/requests/rest"
+	"languago/pkg/srs"
+	"time"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultDueLimit = 20
+	maxDueLimit     = 100
+)
+
+type ReviewsController interface {
+	DueReviews(ctx context.Context, req *rest.DueReviewsRequest) (*rest.DueReviewsResponse, error)
+	ReviewFlashcard(ctx context.Context, cardID uuid.UUID, req *rest.ReviewFlashcardRequest) (*rest.ReviewFlashcardResponse, error)
+}
+
+type reviewsController struct {
+	log     zerolog.Logger
+	storage repository.DatabaseInteractor
+}
+
+func NewReviewsController(
+	log zerolog.Logger,
+	storage repository.DatabaseInteractor,
+) ReviewsController {
+	return &reviewsController{
+		log:     log,
+		storage: storage,
+	}
+}
+
+func (c *reviewsController) DueReviews(ctx context.Context, req *rest.DueReviewsRequest) (*rest.DueReviewsResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultDueLimit
+	} else if limit > maxDueLimit {
+		limit = maxDueLimit
+	}
+
+	reviews, err := c.storage.Database().SelectDueReviews(ctx, repository.SelectDueReviewsParams{
+		DueAt: time.Now(),
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error select due reviews: %w", err)
+	}
+
+	return &rest.DueReviewsResponse{
+		Reviews: reviews,
+	}, nil
+}
+
+func (c *reviewsController) ReviewFlashcard(
+	ctx context.Context,
+	cardID uuid.UUID,
+	req *rest.ReviewFlashcardRequest,
+) (*rest.ReviewFlashcardResponse, error) {
+	user := ctxtools.User(ctx)
+	if user == nil {
+		return nil, fmt.Errorf("error fetch user from context: %w", errors2.ErrUnauthorized)
+	}
+
+	var review *entities.Review
+
+	err := c.storage.WithTx(ctx, func(storage repository.Storage) error {
+		// the card lookup is owner-scoped, so grading a foreign card is a not found
+		_, err := storage.SelectFlashcard(ctx, repository.SelectFlashcardParams{
+			ID: cardID,
+		})
+		if err != nil {
+			return fmt.Errorf("error select flashcard: %w", err)
+		}
+
+		now := time.Now()
+		state := srs.NewState(now)
+
+		current, err := storage.SelectReview(ctx, repository.SelectReviewParams{
+			FlashcardID: cardID,
+		})
+		if err != nil && !errors.Is(err, errors2.ErrNotFound) {
+			return fmt.Errorf("error select review: %w", err)
+		}
+
+		if current != nil {
+			state = srs.State{
+				EaseFactor:  current.EaseFactor,
+				Interval:    current.Interval,
+				Repetitions: current.Repetitions,
+				Lapses:      current.Lapses,
+				DueAt:       current.DueAt,
+			}
+		}
+
+		next, err := srs.Review(state, srs.Grade(req.Grade), now)
+		if err != nil {
+			return err
+		}
+
+		review = &entities.Review{
+			UserID:         user.Id,
+			FlashcardID:    cardID,
+			EaseFactor:     next.EaseFactor,
+			Interval:       next.Interval,
+			Repetitions:    next.Repetitions,
+			Lapses:         next.Lapses,
+			DueAt:          next.DueAt,
+			LastReviewedAt: &now,
+		}
+
+		err = storage.UpsertReview(ctx, repository.UpsertReviewParams{
+			FlashcardID:    review.FlashcardID,
+			EaseFactor:     review.EaseFactor,
+			Interval:       review.Interval,
+			Repetitions:    review.Repetitions,
+			Lapses:         review.Lapses,
+			DueAt:          review.DueAt,
+			LastReviewedAt: review.LastReviewedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("error save review: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &rest.ReviewFlashcardResponse{
+		Review: review,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package trash
+
+import (
+	"context"
+	"fmt"
+	"languago/infrastructure/repository"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Purger removes the cards and decks which have been in the trash for longer
+// than the retention window, with their reviews, tags and deck entries.
+type Purger struct {
+	log       zerolog.Logger
+	storage   repository.DatabaseInteractor
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewPurger(
+	log zerolog.Logger,
+	storage repository.DatabaseInteractor,
+	retention time.Duration,
+	interval time.Duration,
+) *Purger {
+	return &Purger{
+		log:       log,
+		storage:   storage,
+		retention: retention,
+		interval:  interval,
+	}
+}
+
+// Run purges the trash right away and then every interval until ctx is done.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.Purge(ctx); err != nil {
+			p.log.Error().Err(err).Msg("error purge trash")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge removes what was deleted before the retention window and returns how
+// many cards and decks were removed.
+func (p *Purger) Purge(ctx context.Context) (int64, error) {
+	purged, err := p.storage.Database().PurgeDeleted(ctx, time.Now().Add(-p.retention))
+	if err != nil {
+		return 0, fmt.Errorf("error purge deleted: %w", err)
+	}
+
+	if purged > 0 {
+		p.log.Info().Msgf("purged %d cards and decks deleted more than %v ago", purged, p.retention)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,67 @@
+package trash
+
+import (
+	"context"
+	"fmt"
+	"languago/infrastructure/repository"
+	"languago/pkg/models/requests/rest"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// TrashController lists and restores the deleted cards and decks of the
+// user. They are purged for good after the retention window, see Purger.
+type TrashController interface {
+	ListTrash(ctx context.Context) (*rest.TrashResponse, error)
+	RestoreFlashcard(ctx context.Context, cardID uuid.UUID) error
+	RestoreDeck(ctx context.Context, deckID uuid.UUID) error
+}
+
+type trashController struct {
+	log     zerolog.Logger
+	storage repository.DatabaseInteractor
+}
+
+func NewTrashController(
+	log zerolog.Logger,
+	storage repository.DatabaseInteractor,
+) TrashController {
+	return &trashController{
+		log:     log,
+		storage: storage,
+	}
+}
+
+func (c *trashController) ListTrash(ctx context.Context) (*rest.TrashResponse, error) {
+	cards, err := c.storage.Database().SelectDeletedFlashcards(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error select deleted flashcards: %w", err)
+	}
+
+	decks, err := c.storage.Database().SelectDeletedDecks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error select deleted decks: %w", err)
+	}
+
+	return &rest.TrashResponse{
+		Flashcards: cards,
+		Decks:      decks,
+	}, nil
+}
+
+func (c *trashController) RestoreFlashcard(ctx context.Context, cardID uuid.UUID) error {
+	if err := c.storage.Database().RestoreFlashcard(ctx, cardID); err != nil {
+		return fmt.Errorf("error restore flashcard: %w", err)
+	}
+
+	return nil
+}
+
+func (c *trashController) RestoreDeck(ctx context.Context, deckID uuid.UUID) error {
+	if err := c.storage.Database().RestoreDeck(ctx, repository.RestoreDeckParams{ID: deckID}); err != nil {
+		return fmt.Errorf("error restore deck: %w", err)
+	}
+
+	return nil
+}
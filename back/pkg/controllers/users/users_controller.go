@@ -2,55 +2,311 @@ package users
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"languago/infrastructure/repository"
-	"languago/pkg/ctxtools"
+	"languago/pkg/auth"
+	"languago/pkg/models/entities"
 	"languago/pkg/models/requests/rest"
+	"unicode/utf8"
+
+	errors2 "languago/pkg/errors"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 )
 
+const (
+	minLoginLength    = 4
+	maxLoginLength    = 100
+	minPasswordLength = 8
+	maxPasswordLength = 128
+)
+
+var (
+	ErrInvalidLogin       = errors2.New(errors2.CodeBadRequest, "login must be 4-100 characters long", errors2.ErrValidation)
+	ErrInvalidPassword    = errors2.New(errors2.CodeBadRequest, "password must be 8-128 characters long", errors2.ErrValidation)
+	ErrInvalidCredentials = errors2.New(errors2.CodeUnauthorized, "invalid login or password", errors2.ErrUnauthorized)
+	ErrPasswordReset      = errors2.New(errors2.CodePasswordResetRequired, "password reset required", errors2.ErrForbidden)
+	ErrInvalidResetToken  = errors2.New(errors2.CodeUnauthorized, "invalid login or reset token", errors2.ErrUnauthorized)
+)
+
 type UsersController interface {
-	CreateUser(ctx context.Context, req *rest.SignUpRequest) error
+	CreateUser(ctx context.Context, req *rest.SignUpRequest) (*rest.SignUpResponse, error)
+	SignIn(ctx context.Context, req *rest.SignInRequest) (*rest.SignInResponse, error)
+	RefreshToken(ctx context.Context, req *rest.RefreshTokenRequest) (*rest.RefreshTokenResponse, error)
+	SignOut(ctx context.Context, req *rest.SignOutRequest) error
+	// ChangePassword replaces the password of the user, ends their other
+	// sessions and signs them in. Users an administrator requested a reset
+	// from give the reset token instead of the password, it is the only way
+	// in for them.
+	ChangePassword(ctx context.Context, req *rest.ChangePasswordRequest) (*rest.SignInResponse, error)
 	GetUser(ctx context.Context, req *rest.GetUserRequest) (*rest.GetUserResponse, error)
 	DeleteUser(ctx context.Context, req *rest.DeleteUserRequest) error
 	EditUser(ctx context.Context, req *rest.EditUserRequest) (*rest.EditUserResponse, error)
 }
 
 type usersController struct {
-	log     zerolog.Logger
-	storage repository.DatabaseInteractor
+	log        zerolog.Logger
+	storage    repository.DatabaseInteractor
+	authorizer auth.Authorizer
+	hasher     auth.PasswordHasher
 }
 
 func NewUsersController(
 	log zerolog.Logger,
 	storage repository.DatabaseInteractor,
+	authorizer auth.Authorizer,
+	hasher auth.PasswordHasher,
 ) UsersController {
 	return &usersController{
-		log:     log,
-		storage: storage,
+		log:        log,
+		storage:    storage,
+		authorizer: authorizer,
+		hasher:     hasher,
 	}
 }
 
-func (c *usersController) CreateUser(ctx context.Context, req *rest.SignUpRequest) error {
-	userID := ctxtools.UserID(ctx)
-	if userID == uuid.Nil {
-		return fmt.Errorf("error fetch user id from context")
+func (c *usersController) CreateUser(ctx context.Context, req *rest.SignUpRequest) (*rest.SignUpResponse, error) {
+	if l := utf8.RuneCountInString(req.Login); l < minLoginLength || l > maxLoginLength {
+		return nil, ErrInvalidLogin
+	}
+
+	if l := utf8.RuneCountInString(req.Password); l < minPasswordLength || l > maxPasswordLength {
+		return nil, ErrInvalidPassword
 	}
 
-	err := c.storage.Database().CreateUser(ctx, repository.CreateUserParams{
+	hash, err := c.hasher.Hash(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("error hash password: %w", err)
+	}
+
+	userID := uuid.New()
+
+	err = c.storage.Database().CreateUser(ctx, repository.CreateUserParams{
 		ID:       userID,
 		Login:    req.Login,
-		Password: req.Password,
+		Password: hash,
 	})
 	if err != nil {
-		return fmt.Errorf("error create new user: %w", err)
+		return nil, fmt.Errorf("error create new user: %w", err)
+	}
+
+	tokens, err := c.authorizer.CreateSession(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error create session: %w", err)
+	}
+
+	return &rest.SignUpResponse{
+		ID:           userID,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+	}, nil
+}
+
+func (c *usersController) SignIn(ctx context.Context, req *rest.SignInRequest) (*rest.SignInResponse, error) {
+	user, rehash, err := c.checkCredentials(ctx, req.Login, req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.PasswordResetRequired {
+		return nil, ErrPasswordReset
+	}
+
+	if rehash {
+		c.upgradePassword(ctx, user.Id, req.Password)
+	}
+
+	return c.signIn(ctx, user.Id)
+}
+
+func (c *usersController) ChangePassword(ctx context.Context, req *rest.ChangePasswordRequest) (*rest.SignInResponse, error) {
+	if l := utf8.RuneCountInString(req.NewPassword); l < minPasswordLength || l > maxPasswordLength {
+		return nil, ErrInvalidPassword
+	}
+
+	var (
+		user *entities.User
+		err  error
+	)
+	if req.ResetToken != "" {
+		user, err = c.checkResetToken(ctx, req.Login, req.ResetToken)
+	} else {
+		user, _, err = c.checkCredentials(ctx, req.Login, req.Password)
+		// the password may be known to whoever the reset locks out
+		if err == nil && user.PasswordResetRequired {
+			err = ErrPasswordReset
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := c.hasher.Hash(req.NewPassword)
+	if err != nil {
+		return nil, fmt.Errorf("error hash password: %w", err)
+	}
+
+	// the password is changed only together with revoking the sessions,
+	// sessions signed in with the old one would stay alive
+	err = c.storage.WithTx(ctx, func(storage repository.Storage) error {
+		// setting the password also clears a pending reset request
+		err := storage.UpdateUser(ctx, repository.UpdateUserParams{
+			ID:       user.Id,
+			Password: hash,
+		})
+		if err != nil {
+			return fmt.Errorf("error update password: %w", err)
+		}
+
+		if err := storage.RevokeUserSessions(ctx, user.Id); err != nil {
+			return fmt.Errorf("error revoke sessions: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.signIn(ctx, user.Id)
+}
+
+// checkResetToken returns the user the login belongs to if the token is the
+// one of the pending password reset.
+func (c *usersController) checkResetToken(ctx context.Context, login, token string) (*entities.User, error) {
+	if login == "" {
+		return nil, ErrInvalidResetToken
+	}
+
+	user, err := c.storage.Database().SelectUser(ctx, repository.SelectUserParams{
+		Login: login,
+	})
+	if err != nil {
+		if errors.Is(err, errors2.ErrNotFound) {
+			return nil, ErrInvalidResetToken
+		}
+
+		return nil, fmt.Errorf("error select user: %w", err)
+	}
+
+	if !user.PasswordResetRequired || !auth.ResetTokenMatches(token, user.PasswordResetHash) {
+		return nil, ErrInvalidResetToken
+	}
+
+	if user.Disabled() {
+		return nil, auth.ErrUserDisabled
+	}
+
+	return user, nil
+}
+
+// checkCredentials returns the user the login and password belong to and
+// whether the stored hash is outdated. The account state is checked after the
+// password, so it is not revealed to anyone guessing passwords.
+func (c *usersController) checkCredentials(ctx context.Context, login, password string) (*entities.User, bool, error) {
+	if login == "" || password == "" {
+		return nil, false, ErrInvalidCredentials
+	}
+
+	user, err := c.storage.Database().SelectUser(ctx, repository.SelectUserParams{
+		Login: login,
+	})
+	if err != nil {
+		if errors.Is(err, errors2.ErrNotFound) {
+			// burn the same time as a real check so logins can't be probed
+			c.hasher.Hash(password)
+			return nil, false, ErrInvalidCredentials
+		}
+
+		return nil, false, fmt.Errorf("error select user: %w", err)
+	}
+
+	match, rehash, err := c.hasher.Verify(password, user.Password)
+	if errors.Is(err, auth.ErrInvalidHash) {
+		// a corrupt hash can't be signed in with, the user needs a reset
+		c.log.Warn().Err(err).Msg("error verify password hash")
+		return nil, false, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error verify password: %w", err)
+	}
+
+	if !match {
+		return nil, false, ErrInvalidCredentials
+	}
+
+	if user.Disabled() {
+		return nil, false, auth.ErrUserDisabled
+	}
+
+	return user, rehash, nil
+}
+
+func (c *usersController) signIn(ctx context.Context, userID uuid.UUID) (*rest.SignInResponse, error) {
+	tokens, err := c.authorizer.CreateSession(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error create session: %w", err)
+	}
+
+	return &rest.SignInResponse{
+		ID:           userID,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+	}, nil
+}
+
+func (c *usersController) RefreshToken(ctx context.Context, req *rest.RefreshTokenRequest) (*rest.RefreshTokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, auth.ErrInvalidRefreshToken
+	}
+
+	tokens, err := c.authorizer.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("error refresh token: %w", err)
+	}
+
+	return &rest.RefreshTokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+	}, nil
+}
+
+func (c *usersController) SignOut(ctx context.Context, req *rest.SignOutRequest) error {
+	if req.RefreshToken == "" {
+		return auth.ErrInvalidRefreshToken
+	}
+
+	if err := c.authorizer.Revoke(ctx, req.RefreshToken); err != nil {
+		return fmt.Errorf("error revoke session: %w", err)
 	}
 
 	return nil
 }
 
+// upgradePassword replaces a hash made with outdated parameters or a legacy
+// password. Failing to do so does not fail the sign in, the hash will be
+// upgraded next time.
+func (c *usersController) upgradePassword(ctx context.Context, userID uuid.UUID, password string) {
+	hash, err := c.hasher.Hash(password)
+	if err != nil {
+		c.log.Warn().Err(err).Msg("error rehash password")
+		return
+	}
+
+	err = c.storage.Database().UpdateUser(ctx, repository.UpdateUserParams{
+		ID:       userID,
+		Password: hash,
+	})
+	if err != nil {
+		c.log.Warn().Err(err).Msg("error update password hash")
+	}
+}
+
 // todo
 func (c *usersController) GetUser(ctx context.Context, req *rest.GetUserRequest) (*rest.GetUserResponse, error) {
 	return nil, nil
@@ -14,6 +14,23 @@ const (
 	CodeBadRequest          Code = 400
 	CodeNotFound            Code = 404
 	CodeUnauthorized        Code = 401
+	CodeForbidden           Code = 403
+	CodeConflict            Code = 409
+	CodePreconditionFailed  Code = 412
+
+	// Token validation failures, all reported as 401 Unauthorized
+	CodeInvalidClaims     Code = 4010
+	CodeTokenExpired      Code = 4011
+	CodeTokenNotValidYet  Code = 4012
+	CodeInvalidIssuer     Code = 4013
+	CodeInvalidAudience   Code = 4014
+	CodeTokenRevoked      Code = 4015
+	CodeUnknownSigningKey Code = 4016
+	CodeRoleChanged       Code = 4017
+
+	// Account state failures, all reported as 403 Forbidden
+	CodeAccountDisabled       Code = 4030
+	CodePasswordResetRequired Code = 4031
 )
 
 var (
@@ -23,6 +40,9 @@ var (
 	ErrBadRequest          = New(CodeBadRequest, "BadRequest")
 	ErrInvalidToken        = New(CodeUnauthorized, "Invalid Token")
 	ErrUnauthorized        = New(CodeUnauthorized, "Unauthorized")
+	ErrForbidden           = New(CodeForbidden, "Forbidden")
+	ErrAlreadyExists       = New(CodeConflict, "Already Exists")
+	ErrPreconditionFailed  = New(CodePreconditionFailed, "Precondition Failed")
 )
 
 type Code int
@@ -36,15 +56,35 @@ type serviceError struct {
 }
 
 func (e serviceError) Error() string {
+	var parent string
+	if e.Err != nil {
+		parent = e.Err.Error()
+	}
+
 	return fmt.Sprintf(
 		"ServiceID: [%v] ServiceName: [%s] Message: %s Error: %s",
 		e.serviceID,
 		e.serviceName,
 		e.Message,
-		e.Err.Error(),
+		parent,
 	)
 }
 
+func (e serviceError) Unwrap() error {
+	return e.Err
+}
+
+// Describe returns the code and message of the first service error in the
+// chain of err.
+func Describe(err error) (Code, string, bool) {
+	var serr serviceError
+	if !errors.As(err, &serr) {
+		return 0, "", false
+	}
+
+	return serr.Code, serr.Message, true
+}
+
 // Returns bare service error. Can be modified using FOP
 func New(code Code, msg string, parent ...error) error {
 	if parent == nil {
@@ -61,6 +101,29 @@ func New(code Code, msg string, parent ...error) error {
 	}
 }
 
+// detailedError returns the first service error in the chain of err carrying
+// a detailed code, such as CodePasswordResetRequired, instead of a bare HTTP one.
+func detailedError(err error) (serviceError, bool) {
+	if serr, ok := err.(serviceError); ok && serr.Code >= 1000 {
+		return serr, true
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			if serr, ok := detailedError(inner); ok {
+				return serr, true
+			}
+		}
+	case interface{ Unwrap() error }:
+		if inner := e.Unwrap(); inner != nil {
+			return detailedError(inner)
+		}
+	}
+
+	return serviceError{}, false
+}
+
 type ErrorsPersenter interface {
 	ServiceError(be error, opts ...ServiceErrorOption) error
 	ResponseError(err error) error
@@ -103,11 +166,27 @@ func (e *errorPresenter) mapError(err error) error {
 		return ErrUnauthorized
 	case errors.Is(err, ErrUnauthorized):
 		return ErrUnauthorized
+	case errors.Is(err, ErrForbidden):
+		// the client needs to know why, e.g. to ask for a new password
+		if serr, ok := detailedError(err); ok {
+			return New(serr.Code, serr.Message)
+		}
+		return ErrForbidden
 	case errors.Is(err, ErrValidation):
 		return ErrBadRequest
-	default:
-		return ErrInternalServerError
+	case errors.Is(err, ErrNotFound):
+		return ErrNotFound
+	case errors.Is(err, ErrAlreadyExists):
+		return ErrAlreadyExists
+	}
+
+	// errors without a public counterpart keep their client-side code and message
+	if serr, ok := err.(serviceError); ok &&
+		serr.Code >= CodeBadRequest && serr.Code < CodeInternalServerError {
+		return New(serr.Code, serr.Message)
 	}
+
+	return ErrInternalServerError
 }
 
 func ErrorServiceID(serviceID uuid.UUID) ServiceErrorOption {
@@ -122,6 +201,12 @@ func ErrorServiceName(name string) ServiceErrorOption {
 	}
 }
 
+func ErrorCode(code Code) ServiceErrorOption {
+	return func(e *serviceError) {
+		e.Code = code
+	}
+}
+
 func ErrorMessage(msg string) ServiceErrorOption {
 	return func(e *serviceError) {
 		e.Message = msg
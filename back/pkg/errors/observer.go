@@ -22,8 +22,8 @@ func NewErrorObserver(log zerolog.Logger) ErrorsObserver {
 
 func (o *errorObserver) WatchErrors(target ErrorObservable) {
 	go func(target chan error) {
-		err := <-target
-		o.log.Error().Msg(err.Error())
-
+		for err := range target {
+			o.log.Error().Msg(err.Error())
+		}
 	}(target.ErrorChannel())
 }
@@ -3,23 +3,24 @@ package middleware
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"languago/infrastructure/logger"
 	"languago/pkg/auth"
 	"languago/pkg/ctxtools"
+	"languago/pkg/models"
 	"net/http"
+	"strings"
 	"time"
 
 	errors2 "languago/pkg/errors"
 
-	"github.com/golang-jwt/jwt"
-	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 )
 
 const (
-	H_SIGN_IN       = "L-Sign-In"
-	H_SIGN_UP       = "L-Sign-Up"
 	H_Authorization = "Authorization"
+
+	bearerPrefix = "Bearer "
 )
 
 type middleware struct {
@@ -34,6 +35,8 @@ func NewMiddleware(log zerolog.Logger, auth auth.Authorizer) *middleware {
 	}
 }
 
+// AuthMiddleware rejects requests without a valid access token and stores the
+// authorized user in the request context.
 func (m *middleware) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodOptions {
@@ -41,96 +44,86 @@ func (m *middleware) AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		if !doAuth(r) {
-			m.log.Warn().Msgf(
-				`[ SIGN_UP_REQUEST ] 
-				datetime: %v 
-				request_id: %v 
-				request_id: %v 
-				remote_addr: %v 
-				host: %v 
-				user_agent: %v 
-				referer: %v`, time.Now(),
-				ctxtools.RequestId(r.Context()),
-				r.RemoteAddr,
-				r.Host,
-				r.UserAgent(),
-				r.Referer(),
-			)
-
-			userID := uuid.New()
-
-			ctxR := r.WithContext(
-				context.WithValue(r.Context(), ctxtools.UserIDCtxKey, userID),
-			)
-
-			token, err := m.auth.CreateToken(auth.ClaimJWTParams{
-				UserId: userID.String(),
+		tokenStr := bearerToken(r)
+		if tokenStr == "" {
+			m.log.Warn().Msgf("error auth: %v", logger.LogFields{
+				"datetime":    time.Now(),
+				"request_id":  ctxtools.RequestId(r.Context()),
+				"remote_addr": r.RemoteAddr,
+				"host":        r.Host,
+				"user_agent":  r.UserAgent(),
+				"referer":     r.Referer(),
+				"error":       "missing token",
 			})
-			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
+			deny(w, errors2.ErrUnauthorized)
+			return
+		}
 
-			ctxR.Header.Add(H_Authorization, token)
-
-			next.ServeHTTP(w, ctxR)
-		} else {
-			tokenStr := r.Header.Get(H_Authorization)
-			if tokenStr == "" {
-				m.log.Error().Msgf("error auth: %v", logger.LogFields{
-					"datetime":    time.Now(),
-					"request_id":  ctxtools.RequestId(r.Context()),
-					"remote_addr": r.RemoteAddr,
-					"host":        r.Host,
-					"user_agent":  r.UserAgent(),
-					"referer":     r.Referer(),
-				})
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
+		token, err := m.auth.ParseToken(tokenStr)
+		if err != nil {
+			m.log.Warn().Msgf("error parse token: %v", logger.LogFields{
+				"datetime":    time.Now(),
+				"request_id":  ctxtools.RequestId(r.Context()),
+				"remote_addr": r.RemoteAddr,
+				"host":        r.Host,
+				"user_agent":  r.UserAgent(),
+				"referer":     r.Referer(),
+				"error":       err,
+			})
+			deny(w, err)
+			return
+		}
 
-			token, err := jwt.ParseWithClaims(tokenStr, make(jwt.MapClaims),
-				func(token *jwt.Token) (interface{}, error) {
-					if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
-						return nil, errors2.ErrInvalidToken
-					}
-					return m.auth.Secret(), nil
-				})
-			if err != nil {
-				m.log.Error().Msgf("error parse token: %v", logger.LogFields{
-					"datetime":    time.Now(),
-					"request_id":  ctxtools.RequestId(r.Context()),
-					"remote_addr": r.RemoteAddr,
-					"host":        r.Host,
-					"user_agent":  r.UserAgent(),
-					"referer":     r.Referer(),
-					"error":       err,
-				})
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
+		user, err := m.auth.Authorize(token)
+		if err != nil {
+			m.log.Warn().Msgf("error auth: %v", logger.LogFields{
+				"datetime":    time.Now(),
+				"request_id":  ctxtools.RequestId(r.Context()),
+				"remote_addr": r.RemoteAddr,
+				"host":        r.Host,
+				"user_agent":  r.UserAgent(),
+				"referer":     r.Referer(),
+				"error":       err,
+			})
+			deny(w, err)
+			return
+		}
 
-			user, err := m.auth.Authorize(token)
-			if err != nil {
-				m.log.Error().Msgf("error auth: %v", logger.LogFields{
-					"datetime":    time.Now(),
-					"request_id":  ctxtools.RequestId(r.Context()),
-					"remote_addr": r.RemoteAddr,
-					"host":        r.Host,
-					"user_agent":  r.UserAgent(),
-					"referer":     r.Referer(),
-					"error":       err,
-				})
-				w.WriteHeader(http.StatusInternalServerError)
+		ctx := context.WithValue(r.Context(), ctxtools.UserCtxKey, user)
+		ctx = context.WithValue(ctx, ctxtools.UserIDCtxKey, user.Id)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequireRole lets through users with one of the roles only. It reads the
+// user stored by AuthMiddleware, so it must be mounted after it.
+func (m *middleware) RequireRole(roles ...models.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := ctxtools.User(r.Context())
+			if user == nil {
+				deny(w, errors2.ErrUnauthorized)
 				return
 			}
 
-			ctxR := r.WithContext(context.WithValue(r.Context(), ctxtools.UserCtxKey, user))
+			for _, role := range roles {
+				if user.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
 
-			next.ServeHTTP(w, ctxR)
-		}
-	})
+			m.log.Warn().Msgf("error access: %v", logger.LogFields{
+				"datetime":   time.Now(),
+				"request_id": ctxtools.RequestId(r.Context()),
+				"user_id":    user.Id,
+				"role":       user.Role,
+				"path":       r.URL.Path,
+			})
+			deny(w, errors2.ErrForbidden)
+		})
+	}
 }
 
 func (m *middleware) RequestValidationMiddleware(next http.Handler) http.Handler {
@@ -152,20 +145,19 @@ func (m *middleware) Recovery(next http.Handler) http.Handler {
 		defer func() {
 			err := recover()
 			if err != nil {
-				m.log.Error().Msgf(
-					"fatal error: %v",
-					"datetime", time.Now(),
-					"request_id", ctxtools.RequestId(r.Context()),
-					"scheme", r.URL.Scheme,
-					"method", r.Method,
-					"path", r.URL.Path,
-					"remote_addr", r.RemoteAddr,
-					"host", r.Host,
-					"user_agent", r.UserAgent(),
-					"referer", r.Referer(),
-					"content_type", r.Header.Get("Content-Type"),
-					"error", err,
-				)
+				m.log.Error().Msgf("fatal error: %v", logger.LogFields{
+					"datetime":     time.Now(),
+					"request_id":   ctxtools.RequestId(r.Context()),
+					"scheme":       r.URL.Scheme,
+					"method":       r.Method,
+					"path":         r.URL.Path,
+					"remote_addr":  r.RemoteAddr,
+					"host":         r.Host,
+					"user_agent":   r.UserAgent(),
+					"referer":      r.Referer(),
+					"content_type": r.Header.Get("Content-Type"),
+					"error":        err,
+				})
 
 				jsonBody, _ := json.Marshal(map[string]string{
 					"error": "Internal server error",
@@ -181,19 +173,39 @@ func (m *middleware) Recovery(next http.Handler) http.Handler {
 	})
 }
 
-var testKey string = "1234"
+// bearerToken returns the token from the Authorization header. Both
+// "Bearer <token>" and the bare token are accepted.
+func bearerToken(r *http.Request) string {
+	token := strings.TrimSpace(r.Header.Get(H_Authorization))
+	if len(token) > len(bearerPrefix) && strings.EqualFold(token[:len(bearerPrefix)], bearerPrefix) {
+		token = strings.TrimSpace(token[len(bearerPrefix):])
+	}
+
+	return token
+}
+
+// deny writes the 401 response, or 403 for users who are known but not
+// allowed in. The code and message of the error are passed on, so clients can
+// tell an expired token from a revoked one.
+func deny(w http.ResponseWriter, err error) {
+	code, msg, ok := errors2.Describe(err)
+	if !ok {
+		code, msg = errors2.CodeUnauthorized, "Unauthorized"
+	}
 
-func doAuth(r *http.Request) bool {
-	if r.Method == http.MethodOptions {
-		return false
+	status := http.StatusUnauthorized
+	if errors.Is(err, errors2.ErrForbidden) {
+		status = http.StatusForbidden
 	}
 
-	// if r.Header[H_SIGN_UP] == testKey && r.Method == http.MethodPost {
-	// 	return false
-	// }
+	jsonBody, _ := json.Marshal(map[string]interface{}{
+		"code":    code,
+		"message": msg,
+	})
 
-	//return true
-	return false
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonBody)
 }
 
 func (m *middleware) logRequest(r *http.Request, mw string) {
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"languago/infrastructure/repository/postgresql"
 	"languago/pkg/models"
+	"time"
 
 	// "languago/models/requests/rest"
 	// "languago/repository/postgresql"
@@ -13,25 +14,108 @@ import (
 
 type (
 	User struct {
-		Id       uuid.UUID `json:"id"`
-		Login    string    `json:"login"`
-		Password string    `json:"password"`
+		Id                    uuid.UUID   `json:"id"`
+		Login                 string      `json:"login"`
+		Password              string      `json:"-"`
+		Role                  models.Role `json:"role"`
+		DisabledAt            *time.Time  `json:"disabled_at,omitempty"`
+		PasswordResetRequired bool        `json:"password_reset_required"`
+		// PasswordResetHash is the hash of the token the reset has to be done
+		// with while a reset is required
+		PasswordResetHash string `json:"-"`
 	}
 
 	Flashcard struct {
 		ID             uuid.UUID `json:"id"`
+		Owner          uuid.UUID `json:"owner"`
 		NativeLanguage string    `json:"native_lang"`
 		TargetLang     string    `json:"target_lang"`
 		Meaning        string    `json:"word_in_native"`
 		Word           string    `json:"word_in_target"`
 		UsageExamples  []string  `json:"usage"`
+		Tags           []string  `json:"tags,omitempty"`
+		CreatedAt      time.Time `json:"created_at"`
+		UpdatedAt      time.Time `json:"updated_at"`
+		// Version is bumped by every update of the card
+		Version int64 `json:"version"`
+		// DeletedAt is set while the card is in the trash
+		DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	}
+
+	// FlashcardMatch is a search result, Snippet is the matching text HTML
+	// escaped with the matched words in <b> tags.
+	FlashcardMatch struct {
+		Flashcard *Flashcard `json:"flashcard"`
+		Rank      float64    `json:"rank"`
+		Snippet   string     `json:"snippet"`
 	}
 
 	Deck struct {
-		Id    string    `json:"id"`
-		Name  string    `json:"name"`
-		Owner uuid.UUID `json:"owner"`
+		Id        uuid.UUID  `json:"id"`
+		Name      string     `json:"name"`
+		Owner     uuid.UUID  `json:"owner"`
+		Version   int64      `json:"version"`
+		DeletedAt *time.Time `json:"deleted_at,omitempty"`
 	}
+
+	Review struct {
+		UserID         uuid.UUID  `json:"user_id"`
+		FlashcardID    uuid.UUID  `json:"flashcard_id"`
+		EaseFactor     float64    `json:"ease_factor"`
+		Interval       int        `json:"interval"`
+		Repetitions    int        `json:"repetitions"`
+		Lapses         int        `json:"lapses"`
+		DueAt          time.Time  `json:"due_at"`
+		LastReviewedAt *time.Time `json:"last_reviewed_at,omitempty"`
+		Flashcard      *Flashcard `json:"flashcard,omitempty"`
+	}
+
+	// Revision is an entry of the history of a flashcard or a deck. Changes
+	// holds the old and the new value of every changed field and Snapshot the
+	// state of the entity after the change, a card can be reverted to it.
+	Revision struct {
+		ID         uuid.UUID              `json:"id"`
+		EntityType EntityType             `json:"entity_type"`
+		EntityID   uuid.UUID              `json:"entity_id"`
+		Owner      uuid.UUID              `json:"owner"`
+		Version    int64                  `json:"version"`
+		Action     RevisionAction         `json:"action"`
+		ActorID    uuid.UUID              `json:"actor_id"`
+		RequestID  string                 `json:"request_id,omitempty"`
+		Changes    map[string]FieldChange `json:"changes"`
+		Snapshot   json.RawMessage        `json:"snapshot"`
+		CreatedAt  time.Time              `json:"created_at"`
+	}
+
+	// FieldChange is the JSON value of a field before and after a change, Old
+	// is null for a created entity.
+	FieldChange struct {
+		Old json.RawMessage `json:"old"`
+		New json.RawMessage `json:"new"`
+	}
+
+	EntityType     string
+	RevisionAction string
+
+	Session struct {
+		ID          uuid.UUID  `json:"id"`
+		UserID      uuid.UUID  `json:"user_id"`
+		RefreshHash string     `json:"-"`
+		CreatedAt   time.Time  `json:"created_at"`
+		ExpiresAt   time.Time  `json:"expires_at"`
+		RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	}
+)
+
+const (
+	EntityFlashcard EntityType = "flashcard"
+	EntityDeck      EntityType = "deck"
+	EntityUser      EntityType = "user"
+
+	RevisionCreate  RevisionAction = "create"
+	RevisionUpdate  RevisionAction = "update"
+	RevisionDelete  RevisionAction = "delete"
+	RevisionRestore RevisionAction = "restore"
 )
 
 func (m *User) ToJson() ([]byte, error) {
@@ -54,17 +138,161 @@ func (m *Deck) ToJson() ([]byte, error) {
 	return json.Marshal(m)
 }
 
+func (m *Review) ToJson() ([]byte, error) {
+	return json.Marshal(m)
+}
+
 func UserFromPG(user postgresql.User) *User {
-	return &User{
-		Id:       user.ID,
-		Login:    user.Login.String,
-		Password: user.Password.String,
+	u := &User{
+		Id:                    user.ID,
+		Login:                 user.Login.String,
+		Password:              user.Password.String,
+		Role:                  models.Role(user.Role),
+		PasswordResetRequired: user.PasswordResetRequired,
+		PasswordResetHash:     user.PasswordResetHash,
 	}
+
+	if user.DisabledAt.Valid {
+		u.DisabledAt = &user.DisabledAt.Time
+	}
+
+	return u
 }
 
 func (u *User) ToModel() *models.User {
 	return &models.User{
 		Id:    u.Id,
 		Login: u.Login,
+		Role:  u.Role,
+	}
+}
+
+// Disabled reports whether an administrator disabled the account.
+func (u *User) Disabled() bool {
+	return u.DisabledAt != nil
+}
+
+func FlashcardFromPG(card postgresql.Flashcard) *Flashcard {
+	f := &Flashcard{
+		ID:            card.ID,
+		Owner:         card.Owner,
+		Word:          card.Word.String,
+		Meaning:       card.Meaning.String,
+		UsageExamples: card.Usage,
+		CreatedAt:     card.CreatedAt,
+		UpdatedAt:     card.UpdatedAt,
+		Version:       card.Version,
+	}
+
+	if card.DeletedAt.Valid {
+		f.DeletedAt = &card.DeletedAt.Time
+	}
+
+	return f
+}
+
+func DeckFromPG(deck postgresql.Deck) *Deck {
+	d := &Deck{
+		Id:      deck.ID,
+		Name:    deck.Name.String,
+		Owner:   deck.Owner,
+		Version: deck.Version,
+	}
+
+	if deck.DeletedAt.Valid {
+		d.DeletedAt = &deck.DeletedAt.Time
 	}
+
+	return d
+}
+
+func ReviewFromPG(review postgresql.Review) *Review {
+	r := &Review{
+		UserID:      review.UserID,
+		FlashcardID: review.FlashcardID,
+		EaseFactor:  review.EaseFactor,
+		Interval:    int(review.IntervalDays),
+		Repetitions: int(review.Repetitions),
+		Lapses:      int(review.Lapses),
+		DueAt:       review.DueAt,
+	}
+
+	if review.LastReviewedAt.Valid {
+		r.LastReviewedAt = &review.LastReviewedAt.Time
+	}
+
+	return r
+}
+
+func SessionFromPG(session postgresql.Session) *Session {
+	s := &Session{
+		ID:          session.ID,
+		UserID:      session.UserID,
+		RefreshHash: session.RefreshHash,
+		CreatedAt:   session.CreatedAt,
+		ExpiresAt:   session.ExpiresAt,
+	}
+
+	if session.RevokedAt.Valid {
+		s.RevokedAt = &session.RevokedAt.Time
+	}
+
+	return s
+}
+
+func RevisionFromPG(revision postgresql.Revision) *Revision {
+	return &Revision{
+		ID:         revision.ID,
+		EntityType: EntityType(revision.EntityType),
+		EntityID:   revision.EntityID,
+		Owner:      revision.Owner,
+		Version:    revision.Version,
+		Action:     RevisionAction(revision.Action),
+		ActorID:    revision.ActorID,
+		RequestID:  revision.RequestID,
+		Changes:    ChangesFromJSON(revision.Changes),
+		Snapshot:   json.RawMessage(revision.Snapshot),
+		CreatedAt:  revision.CreatedAt,
+	}
+}
+
+// ChangesFromJSON decodes the field changes of a revision. A malformed column
+// yields no changes.
+func ChangesFromJSON(raw string) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+	if err := json.Unmarshal([]byte(raw), &changes); err != nil {
+		return map[string]FieldChange{}
+	}
+
+	return changes
+}
+
+// Active reports whether the session is neither revoked nor expired at now.
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// UsageFromJSON decodes usage examples kept in a JSON column. A NULL or
+// malformed column yields no examples.
+func UsageFromJSON(raw json.RawMessage) []string {
+	var usage []string
+	if len(raw) == 0 {
+		return usage
+	}
+
+	if err := json.Unmarshal(raw, &usage); err != nil {
+		return nil
+	}
+
+	return usage
+}
+
+// UsageToJSON encodes usage examples for a JSON column, no examples are
+// stored as NULL.
+func UsageToJSON(usage []string) (json.RawMessage, error) {
+	if usage == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(usage)
 }
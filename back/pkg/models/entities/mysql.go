@@ -0,0 +1,108 @@
+package entities
+
+import (
+	"encoding/json"
+	"languago/infrastructure/repository/mysql"
+	"languago/pkg/models"
+)
+
+func UserFromMySQL(user mysql.User) *User {
+	u := &User{
+		Id:                    user.ID,
+		Login:                 user.Login.String,
+		Password:              user.Password.String,
+		Role:                  models.Role(user.Role),
+		PasswordResetRequired: user.PasswordResetRequired,
+		PasswordResetHash:     user.PasswordResetHash,
+	}
+
+	if user.DisabledAt.Valid {
+		u.DisabledAt = &user.DisabledAt.Time
+	}
+
+	return u
+}
+
+func FlashcardFromMySQL(card mysql.Flashcard) *Flashcard {
+	f := &Flashcard{
+		ID:            card.ID,
+		Owner:         card.Owner,
+		Word:          card.Word.String,
+		Meaning:       card.Meaning.String,
+		UsageExamples: UsageFromJSON([]byte(card.Usage.String)),
+		CreatedAt:     card.CreatedAt,
+		UpdatedAt:     card.UpdatedAt,
+		Version:       card.Version,
+	}
+
+	if card.DeletedAt.Valid {
+		f.DeletedAt = &card.DeletedAt.Time
+	}
+
+	return f
+}
+
+func DeckFromMySQL(deck mysql.Deck) *Deck {
+	d := &Deck{
+		Id:      deck.ID,
+		Name:    deck.Name.String,
+		Owner:   deck.Owner,
+		Version: deck.Version,
+	}
+
+	if deck.DeletedAt.Valid {
+		d.DeletedAt = &deck.DeletedAt.Time
+	}
+
+	return d
+}
+
+func ReviewFromMySQL(review mysql.Review) *Review {
+	r := &Review{
+		UserID:      review.UserID,
+		FlashcardID: review.FlashcardID,
+		EaseFactor:  review.EaseFactor,
+		Interval:    int(review.IntervalDays),
+		Repetitions: int(review.Repetitions),
+		Lapses:      int(review.Lapses),
+		DueAt:       review.DueAt,
+	}
+
+	if review.LastReviewedAt.Valid {
+		r.LastReviewedAt = &review.LastReviewedAt.Time
+	}
+
+	return r
+}
+
+func RevisionFromMySQL(revision mysql.Revision) *Revision {
+	return &Revision{
+		ID:         revision.ID,
+		EntityType: EntityType(revision.EntityType),
+		EntityID:   revision.EntityID,
+		Owner:      revision.Owner,
+		Version:    revision.Version,
+		Action:     RevisionAction(revision.Action),
+		ActorID:    revision.ActorID,
+		RequestID:  revision.RequestID,
+		Changes:    ChangesFromJSON(revision.Changes),
+		Snapshot:   json.RawMessage(revision.Snapshot),
+		CreatedAt:  revision.CreatedAt,
+	}
+}
+
+func SessionFromMySQL(session mysql.Session) *Session {
+	s := &Session{
+		ID:          session.ID,
+		UserID:      session.UserID,
+		RefreshHash: session.RefreshHash,
+		CreatedAt:   session.CreatedAt,
+		ExpiresAt:   session.ExpiresAt,
+	}
+
+	if session.RevokedAt.Valid {
+		s.RevokedAt = &session.RevokedAt.Time
+	}
+
+	return s
+}
@@ -0,0 +1,108 @@
+package entities
+
+import (
+	"encoding/json"
+	"languago/infrastructure/repository/sqlite"
+	"languago/pkg/models"
+)
+
+func UserFromSQLite(user sqlite.User) *User {
+	u := &User{
+		Id:                    user.ID,
+		Login:                 user.Login.String,
+		Password:              user.Password.String,
+		Role:                  models.Role(user.Role),
+		PasswordResetRequired: user.PasswordResetRequired,
+		PasswordResetHash:     user.PasswordResetHash,
+	}
+
+	if user.DisabledAt.Valid {
+		u.DisabledAt = &user.DisabledAt.Time
+	}
+
+	return u
+}
+
+func FlashcardFromSQLite(card sqlite.Flashcard) *Flashcard {
+	f := &Flashcard{
+		ID:            card.ID,
+		Owner:         card.Owner,
+		Word:          card.Word.String,
+		Meaning:       card.Meaning.String,
+		UsageExamples: UsageFromJSON([]byte(card.Usage.String)),
+		CreatedAt:     card.CreatedAt,
+		UpdatedAt:     card.UpdatedAt,
+		Version:       card.Version,
+	}
+
+	if card.DeletedAt.Valid {
+		f.DeletedAt = &card.DeletedAt.Time
+	}
+
+	return f
+}
+
+func DeckFromSQLite(deck sqlite.Deck) *Deck {
+	d := &Deck{
+		Id:      deck.ID,
+		Name:    deck.Name.String,
+		Owner:   deck.Owner,
+		Version: deck.Version,
+	}
+
+	if deck.DeletedAt.Valid {
+		d.DeletedAt = &deck.DeletedAt.Time
+	}
+
+	return d
+}
+
+func ReviewFromSQLite(review sqlite.Review) *Review {
+	r := &Review{
+		UserID:      review.UserID,
+		FlashcardID: review.FlashcardID,
+		EaseFactor:  review.EaseFactor,
+		Interval:    int(review.IntervalDays),
+		Repetitions: int(review.Repetitions),
+		Lapses:      int(review.Lapses),
+		DueAt:       review.DueAt,
+	}
+
+	if review.LastReviewedAt.Valid {
+		r.LastReviewedAt = &review.LastReviewedAt.Time
+	}
+
+	return r
+}
+
+func RevisionFromSQLite(revision sqlite.Revision) *Revision {
+	return &Revision{
+		ID:         revision.ID,
+		EntityType: EntityType(revision.EntityType),
+		EntityID:   revision.EntityID,
+		Owner:      revision.Owner,
+		Version:    revision.Version,
+		Action:     RevisionAction(revision.Action),
+		ActorID:    revision.ActorID,
+		RequestID:  revision.RequestID,
+		Changes:    ChangesFromJSON(revision.Changes),
+		Snapshot:   json.RawMessage(revision.Snapshot),
+		CreatedAt:  revision.CreatedAt,
+	}
+}
+
+func SessionFromSQLite(session sqlite.Session) *Session {
+	s := &Session{
+		ID:          session.ID,
+		UserID:      session.UserID,
+		RefreshHash: session.RefreshHash,
+		CreatedAt:   session.CreatedAt,
+		ExpiresAt:   session.ExpiresAt,
+	}
+
+	if session.RevokedAt.Valid {
+		s.RevokedAt = &session.RevokedAt.Time
+	}
+
+	return s
+}
@@ -2,10 +2,19 @@ package models
 
 import "github.com/google/uuid"
 
+const (
+	RoleUser    Role = "user"
+	RoleTeacher Role = "teacher"
+	RoleAdmin   Role = "admin"
+)
+
 type (
+	Role string
+
 	User struct {
 		Id    uuid.UUID
 		Login string
+		Role  Role
 	}
 
 	Flashcard struct {
@@ -17,8 +26,17 @@ type (
 	}
 
 	Deck struct {
-		Id    string
+		Id    uuid.UUID
 		Name  string
 		Owner uuid.UUID
 	}
 )
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleUser, RoleTeacher, RoleAdmin:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,27 @@
+package rest
+
+import (
+	"languago/pkg/models"
+	"languago/pkg/models/entities"
+)
+
+type (
+	ListUsersRequest struct {
+		Limit  int `json:"limit"`
+		Offset int `json:"offset"`
+	}
+
+	ListUsersResponse struct {
+		Users []*entities.User `json:"users"`
+	}
+
+	SetRoleRequest struct {
+		Role models.Role `json:"role"`
+	}
+
+	// PasswordResetResponse is the one-time token the user sets the new
+	// password with, it is not shown again.
+	PasswordResetResponse struct {
+		ResetToken string `json:"reset_token"`
+	}
+)
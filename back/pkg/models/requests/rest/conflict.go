@@ -0,0 +1,12 @@
+package rest
+
+// ConflictResponse is the body of 412 Precondition Failed. The write was made
+// on ExpectedVersion while the entity is at CurrentVersion, Current is its
+// state now.
+type ConflictResponse struct {
+	Code            int    `json:"code"`
+	Message         string `json:"message"`
+	ExpectedVersion int64  `json:"expected_version"`
+	CurrentVersion  int64  `json:"current_version"`
+	Current         any    `json:"current"`
+}
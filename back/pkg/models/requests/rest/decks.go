@@ -0,0 +1,34 @@
+package rest
+
+import (
+	"languago/pkg/models/entities"
+)
+
+type (
+	CreateDeckRequest struct {
+		Name string `json:"name"`
+	}
+
+	CreateDeckResponse struct {
+		Deck *entities.Deck `json:"deck"`
+	}
+
+	GetDeckResponse struct {
+		Deck       *entities.Deck        `json:"deck"`
+		Flashcards []*entities.Flashcard `json:"flashcards"`
+	}
+
+	ListDecksRequest struct {
+		Name string `json:"name"`
+	}
+
+	ListDecksResponse struct {
+		Decks []*entities.Deck `json:"decks"`
+	}
+
+	EditDeckRequest struct {
+		Name string `json:"name"`
+		// Version is taken from the If-Match header, 0 edits any version
+		Version int64 `json:"-"`
+	}
+)
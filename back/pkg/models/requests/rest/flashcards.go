@@ -2,6 +2,8 @@ package rest
 
 import (
 	"languago/pkg/models/entities"
+
+	"github.com/google/uuid"
 )
 
 type (
@@ -13,6 +15,10 @@ type (
 			WordInTarget  string   `json:"word_in_target"`
 			UsageExamples []string `json:"usage"`
 		} `json:"content"`
+		Tags []string `json:"tags,omitempty"`
+		// DeckID optionally adds the new card to the deck, the card isn't
+		// created if it can't be added
+		DeckID uuid.UUID `json:"deck_id,omitempty"`
 	}
 
 	NewFlashcardResponse struct {
@@ -28,6 +34,61 @@ type (
 		WordInNative  string   `json:"word_in_native,omitempty"`
 		WordInTarget  string   `json:"word_in_target,omitempty"`
 		UsageExamples []string `json:"usage,omitempty"`
+		// Tags replace the tags of the card, an empty list removes them
+		Tags []string `json:"tags,omitempty"`
+	}
+
+	// ListFlashcardsRequest is a page of the caller's cards. Cursor is the
+	// NextCursor of the previous page, the other params must stay the same.
+	ListFlashcardsRequest struct {
+		Limit  int       `json:"limit"`
+		Cursor string    `json:"cursor"`
+		Sort   string    `json:"sort"`
+		Order  string    `json:"order"`
+		DeckID uuid.UUID `json:"deck_id"`
+		Tag    string    `json:"tag"`
+		Prefix string    `json:"prefix"`
+		Due    bool      `json:"due"`
+	}
+
+	ListFlashcardsResponse struct {
+		Flashcards []*entities.Flashcard `json:"flashcards"`
+		// NextCursor is empty on the last page
+		NextCursor string `json:"next_cursor,omitempty"`
+	}
+
+	// PatchFlashcardRequest is a JSON Merge Patch of a card, the members
+	// which are null clear the field. The other members of the card can't be
+	// changed.
+	PatchFlashcardRequest struct {
+		WordInNative  Optional[string]   `json:"word_in_native"`
+		WordInTarget  Optional[string]   `json:"word_in_target"`
+		UsageExamples Optional[[]string] `json:"usage"`
+		Tags          Optional[[]string] `json:"tags"`
+		// Version is taken from the If-Match header, 0 patches any version
+		Version int64 `json:"-"`
+	}
+
+	SearchFlashcardsRequest struct {
+		Query string `json:"q"`
+		Limit int    `json:"limit"`
+	}
+
+	SearchFlashcardsResponse struct {
+		Results []*entities.FlashcardMatch `json:"results"`
+	}
+
+	// FlashcardHistoryResponse is every change of the card, the oldest first.
+	FlashcardHistoryResponse struct {
+		Revisions []*entities.Revision `json:"revisions"`
+	}
+
+	// RevertFlashcardRequest sets the word, the meaning, the usage and the
+	// tags of the card back to what they were after the revision.
+	RevertFlashcardRequest struct {
+		RevisionID uuid.UUID `json:"-"`
+		// Version is taken from the If-Match header, 0 reverts any version
+		Version int64 `json:"-"`
 	}
 )
 
@@ -0,0 +1,24 @@
+package rest
+
+import "encoding/json"
+
+// Optional is a member of a JSON Merge Patch (RFC 7396). Set tells a member
+// that is absent, which leaves the field as it is, from a given one, Null is
+// set if the member is null, which removes the field.
+type Optional[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+// UnmarshalJSON is only called for the members present in the document, also
+// for the null ones.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Null = true
+		return nil
+	}
+
+	return json.Unmarshal(data, &o.Value)
+}
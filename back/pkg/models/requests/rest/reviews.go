@@ -0,0 +1,23 @@
+package rest
+
+import (
+	"languago/pkg/models/entities"
+)
+
+type (
+	DueReviewsRequest struct {
+		Limit int `json:"limit"`
+	}
+
+	DueReviewsResponse struct {
+		Reviews []*entities.Review `json:"reviews"`
+	}
+
+	ReviewFlashcardRequest struct {
+		Grade int `json:"grade"`
+	}
+
+	ReviewFlashcardResponse struct {
+		Review *entities.Review `json:"review"`
+	}
+)
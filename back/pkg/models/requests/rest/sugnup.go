@@ -1,7 +1,8 @@
 package rest
 
 import (
-	"github.com/golang-jwt/jwt"
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -11,6 +12,43 @@ type SignUpRequest struct {
 }
 
 type SignUpResponse struct {
-	ID    uuid.UUID  `json:"id"`
-	Token *jwt.Token `json:"token"`
+	ID           uuid.UUID `json:"id"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type SignInRequest struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+type SignInResponse struct {
+	ID           uuid.UUID `json:"id"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshTokenResponse struct {
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type SignOutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ChangePasswordRequest proves the user with the current password or, after
+// an administrator required a reset, with the reset token.
+type ChangePasswordRequest struct {
+	Login       string `json:"login"`
+	Password    string `json:"password,omitempty"`
+	ResetToken  string `json:"reset_token,omitempty"`
+	NewPassword string `json:"new_password"`
 }
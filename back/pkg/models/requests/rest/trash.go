@@ -0,0 +1,12 @@
+package rest
+
+import (
+	"languago/pkg/models/entities"
+)
+
+type (
+	TrashResponse struct {
+		Flashcards []*entities.Flashcard `json:"flashcards"`
+		Decks      []*entities.Deck      `json:"decks"`
+	}
+)
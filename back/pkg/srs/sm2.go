@@ -0,0 +1,95 @@
+// Package srs implements the SM-2 spaced-repetition scheduling algorithm
+// used to plan flashcard reviews.
+package srs
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	errors2 "languago/pkg/errors"
+)
+
+const (
+	// DefaultEaseFactor is the ease factor assigned to a card that was never reviewed.
+	DefaultEaseFactor float64 = 2.5
+	// MinEaseFactor is the lower bound of the ease factor, as defined by SM-2.
+	MinEaseFactor float64 = 1.3
+
+	day = 24 * time.Hour
+)
+
+// Grade is the quality of a recall in the 0-5 range.
+// Grades below GradePass are treated as a failed recall.
+type Grade int
+
+const (
+	GradeBlackout Grade = iota
+	GradeIncorrect
+	GradeIncorrectEasy
+	GradePass
+	GradeHesitant
+	GradePerfect
+)
+
+var ErrInvalidGrade = errors2.New(errors2.CodeBadRequest, "grade must be in range 0-5", errors2.ErrValidation)
+
+func (g Grade) Valid() bool {
+	return g >= GradeBlackout && g <= GradePerfect
+}
+
+// State is the scheduling state of a single card for a single user.
+type State struct {
+	EaseFactor  float64
+	Interval    int // days
+	Repetitions int
+	Lapses      int
+	DueAt       time.Time
+}
+
+// NewState returns the initial state of a card that is due right away.
+func NewState(now time.Time) State {
+	return State{
+		EaseFactor: DefaultEaseFactor,
+		DueAt:      now,
+	}
+}
+
+// Review applies a graded recall to the state and returns the next one.
+func Review(s State, g Grade, now time.Time) (State, error) {
+	if !g.Valid() {
+		return s, fmt.Errorf("error review card: %w", ErrInvalidGrade)
+	}
+
+	if s.EaseFactor == 0 {
+		s.EaseFactor = DefaultEaseFactor
+	}
+
+	if g < GradePass {
+		if s.Repetitions > 0 {
+			s.Lapses++
+		}
+		s.Repetitions = 0
+		s.Interval = 1
+	} else {
+		switch s.Repetitions {
+		case 0:
+			s.Interval = 1
+		case 1:
+			s.Interval = 6
+		default:
+			s.Interval = int(math.Round(float64(s.Interval) * s.EaseFactor))
+		}
+		s.Repetitions++
+	}
+
+	q := float64(GradePerfect - g)
+	s.EaseFactor += 0.1 - q*(0.08+q*0.02)
+	if s.EaseFactor < MinEaseFactor {
+		s.EaseFactor = MinEaseFactor
+	}
+
+	s.DueAt = now.Add(time.Duration(s.Interval) * day)
+
+	return s, nil
+}
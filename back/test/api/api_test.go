@@ -0,0 +1,971 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"languago/infrastructure/config"
+	"languago/infrastructure/logger"
+	"languago/infrastructure/repository"
+	"languago/interface/api"
+	"languago/pkg/auth"
+	"languago/pkg/ctxtools"
+	"languago/pkg/models"
+	"languago/pkg/models/entities"
+	"languago/pkg/models/requests/rest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type mockConfig struct{}
+
+func (mockConfig) GetCredentials() repository.DBCredentials { return &repository.DBCred{} }
+func (mockConfig) IsMock() bool                             { return true }
+
+type client struct {
+	t      *testing.T
+	server *httptest.Server
+	api    *api.API
+	token  string
+	userID uuid.UUID
+}
+
+// newServer starts the API on top of the in-memory storage.
+func newServer(t *testing.T) (*httptest.Server, *api.API) {
+	t.Helper()
+
+	interactor, err := repository.NewDatabaseInteractor(mockConfig{})
+	if err != nil {
+		t.Fatalf("error create storage: %v", err)
+	}
+
+	a, err := api.NewAPI(
+		&config.LoggerConfig{Env: logger.EnvParam_LOCAL, Level: logger.LevelOff},
+		&config.AuthConfig{
+			CurrentKeyID:    "test",
+			Keys:            []auth.KeyConfig{{ID: "test", Algorithm: auth.AlgHS256, Secret: "secret"}},
+			Issuer:          "languago",
+			Audience:        "languago",
+			AccessTokenTTL:  time.Minute,
+			RefreshTokenTTL: time.Hour,
+		},
+		interactor,
+	)
+	if err != nil {
+		t.Fatalf("error create api: %v", err)
+	}
+
+	server := httptest.NewServer(a)
+	t.Cleanup(server.Close)
+
+	return server, a
+}
+
+// signUp registers a new user and returns a client authorized as this user.
+func signUp(t *testing.T, server *httptest.Server, a *api.API, login string) *client {
+	t.Helper()
+
+	c := &client{t: t, server: server, api: a}
+
+	var resp rest.SignUpResponse
+	status := c.do(http.MethodPost, "/signup", rest.SignUpRequest{Login: login, Password: "correct horse"}, &resp)
+	if status != http.StatusCreated {
+		t.Fatalf("sign up %s: want 201, got %d", login, status)
+	}
+
+	c.token = resp.Token
+	c.userID = resp.ID
+
+	return c
+}
+
+func (c *client) do(method, path string, body, resp any) int {
+	c.t.Helper()
+
+	res, raw := c.send(method, path, nil, body)
+	if resp != nil && res.StatusCode < 300 {
+		if err := json.Unmarshal(raw, resp); err != nil {
+			c.t.Fatalf("error decode %s %s response: %v", method, path, err)
+		}
+	}
+
+	return res.StatusCode
+}
+
+// send makes the request with the extra headers and returns the response with
+// its body read.
+func (c *client) send(method, path string, header http.Header, body any) (*http.Response, []byte) {
+	c.t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatalf("error marshal request: %v", err)
+		}
+		reader = bytes.NewReader(raw)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, c.server.URL+path, reader)
+	if err != nil {
+		c.t.Fatalf("error build request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	res, err := c.server.Client().Do(req)
+	if err != nil {
+		c.t.Fatalf("error %s %s: %v", method, path, err)
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.t.Fatalf("error read %s %s response: %v", method, path, err)
+	}
+
+	return res, raw
+}
+
+// flashcardID finds the id of the card with the word, POST /flashcard doesn't
+// return it.
+func (c *client) flashcardID(word string) uuid.UUID {
+	c.t.Helper()
+
+	ctx := context.WithValue(context.Background(), ctxtools.UserCtxKey, &models.User{Id: c.userID})
+	cards, err := c.api.Repo.Database().SelectFlashcard(ctx, repository.SelectFlashcardParams{})
+	if err != nil {
+		c.t.Fatalf("error select flashcards: %v", err)
+	}
+
+	for _, card := range cards {
+		if card.Word == word {
+			return card.ID
+		}
+	}
+
+	c.t.Fatalf("flashcard %q not found", word)
+	return uuid.Nil
+}
+
+func newFlashcard(word, meaning string) rest.NewFlashcardRequest {
+	var req rest.NewFlashcardRequest
+	req.Content.WordInTarget = word
+	req.Content.WordInNative = meaning
+	req.Content.UsageExamples = []string{"der " + word}
+
+	return req
+}
+
+func TestSignUp(t *testing.T) {
+	server, a := newServer(t)
+	signUp(t, server, a, "alice")
+
+	anonymous := &client{t: t, server: server, api: a}
+	if status := anonymous.do(http.MethodPost, "/signup", rest.SignUpRequest{Login: "alice", Password: "battery staple"}, nil); status != http.StatusConflict {
+		t.Errorf("duplicate login: want 409, got %d", status)
+	}
+
+	var resp rest.SignInResponse
+	if status := anonymous.do(http.MethodPost, "/signin", rest.SignInRequest{Login: "alice", Password: "correct horse"}, &resp); status != http.StatusOK {
+		t.Fatalf("sign in: want 200, got %d", status)
+	}
+
+	if status := anonymous.do(http.MethodPost, "/signin", rest.SignInRequest{Login: "alice", Password: "wrong password"}, nil); status != http.StatusUnauthorized {
+		t.Errorf("sign in with wrong password: want 401, got %d", status)
+	}
+}
+
+// TestLegacyPassword signs in users stored before the passwords were hashed,
+// their password is hashed on the way.
+func TestLegacyPassword(t *testing.T) {
+	server, a := newServer(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	err := a.Repo.Database().CreateUser(ctx, repository.CreateUserParams{ID: userID, Login: "alice", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("error create user: %v", err)
+	}
+
+	alice := &client{t: t, server: server, api: a}
+	if status := alice.signIn("alice", "correct horse"); status != http.StatusOK {
+		t.Fatalf("sign in with a legacy password: want 200, got %d", status)
+	}
+
+	user, err := a.Repo.Database().SelectUser(ctx, repository.SelectUserParams{ID: userID})
+	if err != nil {
+		t.Fatalf("error select user: %v", err)
+	}
+	if !strings.HasPrefix(user.Password, "$argon2id$") {
+		t.Errorf("password after sign in: want it hashed, got %q", user.Password)
+	}
+
+	// a corrupt hash is a failed sign in, not a server error
+	err = a.Repo.Database().UpdateUser(ctx, repository.UpdateUserParams{ID: userID, Password: "$argon2id$v=19$broken"})
+	if err != nil {
+		t.Fatalf("error update user: %v", err)
+	}
+
+	if status := alice.signIn("alice", "correct horse"); status != http.StatusUnauthorized {
+		t.Errorf("sign in with a corrupt hash: want 401, got %d", status)
+	}
+}
+
+func TestFlashcards(t *testing.T) {
+	server, a := newServer(t)
+	alice := signUp(t, server, a, "alice")
+	bob := signUp(t, server, a, "bobby")
+
+	if status := alice.do(http.MethodPost, "/flashcard", newFlashcard("hund", "dog"), nil); status != http.StatusOK {
+		t.Fatalf("create flashcard: want 200, got %d", status)
+	}
+	cardID := alice.flashcardID("hund")
+
+	var cards rest.GetFlashcardResponse
+	if status := alice.do(http.MethodGet, "/flashcard?id="+cardID.String(), nil, &cards); status != http.StatusOK {
+		t.Fatalf("get flashcard: want 200, got %d", status)
+	}
+
+	if len(cards.Flashcards) != 1 || cards.Flashcards[0].Meaning != "dog" {
+		t.Errorf("get flashcard: want the created card, got %+v", cards.Flashcards)
+	}
+
+	edit := rest.EditFlashcardRequest{Id: cardID.String(), WordInNative: "hound"}
+	if status := bob.do(http.MethodPut, "/flashcard", edit, nil); status != http.StatusNotFound {
+		t.Errorf("edit by another user: want 404, got %d", status)
+	}
+
+	if status := alice.do(http.MethodPut, "/flashcard", edit, nil); status != http.StatusOK {
+		t.Fatalf("edit flashcard: want 200, got %d", status)
+	}
+
+	alice.do(http.MethodGet, "/flashcard?id="+cardID.String(), nil, &cards)
+	if cards.Flashcards[0].Meaning != "hound" {
+		t.Errorf("edit flashcard: want meaning hound, got %q", cards.Flashcards[0].Meaning)
+	}
+
+	if status := bob.do(http.MethodGet, "/flashcard?id="+cardID.String(), nil, nil); status != http.StatusNotFound {
+		t.Errorf("get by another user: want 404, got %d", status)
+	}
+
+	if status := bob.do(http.MethodDelete, "/flashcard?id="+cardID.String(), nil, nil); status != http.StatusNotFound {
+		t.Errorf("delete by another user: want 404, got %d", status)
+	}
+
+	if status := alice.do(http.MethodDelete, "/flashcard?id="+cardID.String(), nil, nil); status != http.StatusOK {
+		t.Fatalf("delete flashcard: want 200, got %d", status)
+	}
+
+	if status := alice.do(http.MethodGet, "/flashcard?id="+cardID.String(), nil, nil); status != http.StatusNotFound {
+		t.Errorf("get deleted flashcard: want 404, got %d", status)
+	}
+}
+
+func TestDecks(t *testing.T) {
+	server, a := newServer(t)
+	alice := signUp(t, server, a, "alice")
+	bob := signUp(t, server, a, "bobby")
+
+	alice.do(http.MethodPost, "/flashcard", newFlashcard("hund", "dog"), nil)
+	cardID := alice.flashcardID("hund")
+
+	var created rest.CreateDeckResponse
+	if status := alice.do(http.MethodPost, "/decks", rest.CreateDeckRequest{Name: "german"}, &created); status != http.StatusCreated {
+		t.Fatalf("create deck: want 201, got %d", status)
+	}
+	deckPath := "/decks/" + created.Deck.Id.String()
+
+	if status := alice.do(http.MethodPost, deckPath+"/flashcards/"+cardID.String(), nil, nil); status >= 300 {
+		t.Fatalf("add to deck: want 2xx, got %d", status)
+	}
+
+	var deck rest.GetDeckResponse
+	if status := alice.do(http.MethodGet, deckPath, nil, &deck); status != http.StatusOK {
+		t.Fatalf("get deck: want 200, got %d", status)
+	}
+
+	if len(deck.Flashcards) != 1 || deck.Flashcards[0].ID != cardID {
+		t.Errorf("get deck: want the added card, got %+v", deck.Flashcards)
+	}
+
+	var cards rest.GetFlashcardResponse
+	alice.do(http.MethodGet, "/flashcard?deck_id="+created.Deck.Id.String()+"&word=hund", nil, &cards)
+	if len(cards.Flashcards) != 1 {
+		t.Errorf("get flashcard by word: want 1 card, got %d", len(cards.Flashcards))
+	}
+
+	if status := bob.do(http.MethodGet, deckPath, nil, nil); status != http.StatusNotFound {
+		t.Errorf("get deck of another user: want 404, got %d", status)
+	}
+
+	if status := bob.do(http.MethodDelete, deckPath, nil, nil); status != http.StatusNotFound {
+		t.Errorf("delete deck of another user: want 404, got %d", status)
+	}
+
+	var list rest.ListDecksResponse
+	bob.do(http.MethodGet, "/decks", nil, &list)
+	if len(list.Decks) != 0 {
+		t.Errorf("list decks of another user: want none, got %+v", list.Decks)
+	}
+
+	if status := alice.do(http.MethodDelete, deckPath+"/flashcards/"+cardID.String(), nil, nil); status >= 300 {
+		t.Errorf("delete from deck: want 2xx, got %d", status)
+	}
+
+	if status := alice.do(http.MethodDelete, deckPath+"/flashcards/"+cardID.String(), nil, nil); status != http.StatusNotFound {
+		t.Errorf("delete from deck twice: want 404, got %d", status)
+	}
+}
+
+func TestRequiresToken(t *testing.T) {
+	server, a := newServer(t)
+	anonymous := &client{t: t, server: server, api: a}
+
+	if status := anonymous.do(http.MethodGet, "/decks", nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("list decks without token: want 401, got %d", status)
+	}
+
+	if status := anonymous.do(http.MethodPost, "/flashcard", newFlashcard("hund", "dog"), nil); status != http.StatusUnauthorized {
+		t.Errorf("create flashcard without token: want 401, got %d", status)
+	}
+}
+
+func TestCreateFlashcardInDeck(t *testing.T) {
+	server, a := newServer(t)
+	alice := signUp(t, server, a, "alice")
+
+	req := newFlashcard("hund", "dog")
+	req.DeckID = uuid.New()
+	if status := alice.do(http.MethodPost, "/flashcard", req, nil); status != http.StatusNotFound {
+		t.Errorf("create in missing deck: want 404, got %d", status)
+	}
+
+	ctx := context.WithValue(context.Background(), ctxtools.UserCtxKey, &models.User{Id: alice.userID})
+	cards, err := a.Repo.Database().SelectFlashcard(ctx, repository.SelectFlashcardParams{})
+	if err != nil || len(cards) != 0 {
+		t.Errorf("card is left after failing to add it to the deck, got %v, %v", cards, err)
+	}
+
+	var created rest.CreateDeckResponse
+	alice.do(http.MethodPost, "/decks", rest.CreateDeckRequest{Name: "german"}, &created)
+
+	req.DeckID = created.Deck.Id
+	if status := alice.do(http.MethodPost, "/flashcard", req, nil); status != http.StatusOK {
+		t.Fatalf("create in deck: want 200, got %d", status)
+	}
+
+	var deck rest.GetDeckResponse
+	alice.do(http.MethodGet, "/decks/"+created.Deck.Id.String(), nil, &deck)
+	if len(deck.Flashcards) != 1 || deck.Flashcards[0].Word != "hund" {
+		t.Errorf("get deck: want the created card, got %+v", deck.Flashcards)
+	}
+}
+
+func TestListFlashcards(t *testing.T) {
+	server, a := newServer(t)
+	alice := signUp(t, server, a, "alice")
+	bob := signUp(t, server, a, "bobby")
+
+	for _, word := range []string{"katze", "hund", "maus", "vogel", "fisch"} {
+		req := newFlashcard(word, "")
+		req.Tags = []string{" Tier "}
+		if status := alice.do(http.MethodPost, "/flashcard", req, nil); status != http.StatusOK {
+			t.Fatalf("create flashcard: want 200, got %d", status)
+		}
+	}
+	bob.do(http.MethodPost, "/flashcard", newFlashcard("baum", "tree"), nil)
+
+	var (
+		words []string
+		page  rest.ListFlashcardsResponse
+		path  = "/flashcards?sort=word&limit=2&tag=tier"
+	)
+	for {
+		page = rest.ListFlashcardsResponse{}
+		if status := alice.do(http.MethodGet, path, nil, &page); status != http.StatusOK {
+			t.Fatalf("list flashcards: want 200, got %d", status)
+		}
+
+		for _, card := range page.Flashcards {
+			words = append(words, card.Word)
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		path = "/flashcards?sort=word&limit=2&tag=tier&cursor=" + page.NextCursor
+	}
+
+	if got := strings.Join(words, " "); got != "fisch hund katze maus vogel" {
+		t.Errorf("list flashcards: want the cards by word, got %s", got)
+	}
+
+	alice.do(http.MethodGet, "/flashcards?sort=word&limit=2", nil, &page)
+	for path, want := range map[string]int{
+		"/flashcards?sort=meaning":                                   http.StatusBadRequest,
+		"/flashcards?limit=1000":                                     http.StatusBadRequest,
+		"/flashcards?limit=two":                                      http.StatusBadRequest,
+		"/flashcards?deck_id=deck":                                   http.StatusBadRequest,
+		"/flashcards?cursor=garbage":                                 http.StatusBadRequest,
+		"/flashcards?sort=created&cursor=" + page.NextCursor:         http.StatusBadRequest,
+		"/flashcards?sort=word&order=desc&cursor=" + page.NextCursor: http.StatusBadRequest,
+		"/flashcards?deck_id=" + uuid.NewString():                    http.StatusNotFound,
+	} {
+		if status := alice.do(http.MethodGet, path, nil, nil); status != want {
+			t.Errorf("GET %s: want %d, got %d", path, want, status)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	server, a := newServer(t)
+	alice := signUp(t, server, a, "alice")
+
+	alice.do(http.MethodPost, "/flashcard", newFlashcard("Café", "coffee house"), nil)
+	alice.do(http.MethodPost, "/flashcard", newFlashcard("hund", "dog"), nil)
+
+	var resp rest.SearchFlashcardsResponse
+	if status := alice.do(http.MethodGet, "/search?q=cafe", nil, &resp); status != http.StatusOK {
+		t.Fatalf("search: want 200, got %d", status)
+	}
+
+	if len(resp.Results) != 1 || resp.Results[0].Flashcard.Word != "Café" || !strings.Contains(resp.Results[0].Snippet, "<b>Café</b>") {
+		t.Errorf("search: want the highlighted card, got %+v", resp.Results)
+	}
+
+	for _, path := range []string{"/search", "/search?q=+", "/search?q=hund&limit=1000", "/search?q=" + strings.Repeat("a", 201)} {
+		if status := alice.do(http.MethodGet, path, nil, nil); status != http.StatusBadRequest {
+			t.Errorf("GET %s: want 400, got %d", path, status)
+		}
+	}
+}
+
+func TestEditConflicts(t *testing.T) {
+	server, a := newServer(t)
+	alice := signUp(t, server, a, "alice")
+
+	alice.do(http.MethodPost, "/flashcard", newFlashcard("hund", "dog"), nil)
+	cardID := alice.flashcardID("hund")
+	path := "/flashcard?id=" + cardID.String()
+
+	res, _ := alice.send(http.MethodGet, path, nil, nil)
+	tag := res.Header.Get("ETag")
+	if tag != `"1"` {
+		t.Fatalf("get flashcard: want ETag \"1\", got %q", tag)
+	}
+
+	ifMatch := func(tag string) http.Header {
+		return http.Header{"If-Match": {tag}}
+	}
+
+	// the first device edits the card, the second one edits it on the same version
+	res, _ = alice.send(http.MethodPut, "/flashcard", ifMatch(tag), rest.EditFlashcardRequest{Id: cardID.String(), WordInNative: "hound"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("edit on the current version: want 200, got %d", res.StatusCode)
+	}
+
+	res, raw := alice.send(http.MethodPut, "/flashcard", ifMatch(tag), rest.EditFlashcardRequest{Id: cardID.String(), WordInNative: "puppy"})
+	if res.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("edit on a stale version: want 412, got %d", res.StatusCode)
+	}
+
+	var conflict struct {
+		rest.ConflictResponse
+		Current entities.Flashcard `json:"current"`
+	}
+	if err := json.Unmarshal(raw, &conflict); err != nil {
+		t.Fatalf("error decode conflict: %v", err)
+	}
+
+	if conflict.ExpectedVersion != 1 || conflict.CurrentVersion != 2 || conflict.Current.Meaning != "hound" || res.Header.Get("ETag") != `"2"` {
+		t.Errorf("conflict: want version 1 against 2 with the current card, got %s", raw)
+	}
+
+	for _, tag := range []string{"2", `"2", 3`, `"2", *`, `"2`} {
+		res, _ := alice.send(http.MethodPut, "/flashcard", ifMatch(tag), rest.EditFlashcardRequest{Id: cardID.String(), WordInNative: "puppy"})
+		if res.StatusCode != http.StatusBadRequest {
+			t.Errorf("edit with If-Match %s: want 400, got %d", tag, res.StatusCode)
+		}
+	}
+
+	// weak tags and tags of no version are well formed but never match
+	for _, tag := range []string{`W/"2"`, `"two"`, `W/"2", "two"`} {
+		res, _ := alice.send(http.MethodPut, "/flashcard", ifMatch(tag), rest.EditFlashcardRequest{Id: cardID.String(), WordInNative: "puppy"})
+		if res.StatusCode != http.StatusPreconditionFailed || res.Header.Get("ETag") != `"2"` {
+			t.Errorf("edit with If-Match %s: want 412 with ETag \"2\", got %d %q", tag, res.StatusCode, res.Header.Get("ETag"))
+		}
+	}
+
+	// any tag of a list may be the current one
+	res, _ = alice.send(http.MethodPut, "/flashcard", ifMatch(`"1", W/"2", "2"`), rest.EditFlashcardRequest{Id: cardID.String(), WordInNative: "puppy"})
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("edit with a list holding the current version: want 200, got %d", res.StatusCode)
+	}
+
+	res, raw = alice.send(http.MethodPut, "/flashcard", ifMatch(`"1", "2"`), rest.EditFlashcardRequest{Id: cardID.String(), WordInNative: "hound"})
+	if res.StatusCode != http.StatusPreconditionFailed || res.Header.Get("ETag") != `"3"` {
+		t.Errorf("edit with a list of stale versions: want 412 with ETag \"3\", got %d %s", res.StatusCode, raw)
+	}
+
+	if status := alice.do(http.MethodPut, "/flashcard", rest.EditFlashcardRequest{Id: cardID.String(), WordInNative: "puppy"}, nil); status != http.StatusOK {
+		t.Errorf("edit without If-Match: want 200, got %d", status)
+	}
+
+	var created rest.CreateDeckResponse
+	alice.do(http.MethodPost, "/decks", rest.CreateDeckRequest{Name: "german"}, &created)
+	deckPath := "/decks/" + created.Deck.Id.String()
+
+	if res, _ := alice.send(http.MethodPut, deckPath, ifMatch(`"1"`), rest.EditDeckRequest{Name: "deutsch"}); res.StatusCode != http.StatusOK {
+		t.Fatalf("edit deck on the current version: want 200, got %d", res.StatusCode)
+	}
+
+	res, raw = alice.send(http.MethodPut, deckPath, ifMatch(`"1"`), rest.EditDeckRequest{Name: "german"})
+	if res.StatusCode != http.StatusPreconditionFailed || !strings.Contains(string(raw), `"name":"deutsch"`) {
+		t.Errorf("edit deck on a stale version: want 412 with the current deck, got %d %s", res.StatusCode, raw)
+	}
+}
+
+func TestPatchFlashcard(t *testing.T) {
+	server, a := newServer(t)
+	alice := signUp(t, server, a, "alice")
+	bob := signUp(t, server, a, "bobby")
+
+	req := newFlashcard("hund", "dog")
+	req.Tags = []string{"noun"}
+	alice.do(http.MethodPost, "/flashcard", req, nil)
+	cardID := alice.flashcardID("hund")
+	path := "/flashcards/" + cardID.String()
+
+	patch := func(c *client, header http.Header, body string) (*http.Response, []byte) {
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Set("Content-Type", "application/merge-patch+json")
+
+		return c.send(http.MethodPatch, path, header, json.RawMessage(body))
+	}
+
+	// every member is applied, null clears the field and absent ones are kept
+	res, raw := patch(alice, nil, `{"word_in_target": "katze", "word_in_native": null, "usage": ["die Katze"]}`)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("patch flashcard: want 200, got %d %s", res.StatusCode, raw)
+	}
+
+	var card entities.Flashcard
+	if err := json.Unmarshal(raw, &card); err != nil {
+		t.Fatalf("error decode patched card: %v", err)
+	}
+
+	if card.Word != "katze" || card.Meaning != "" || fmt.Sprint(card.UsageExamples) != "[die Katze]" || fmt.Sprint(card.Tags) != "[noun]" {
+		t.Errorf("patch flashcard: got %+v", card)
+	}
+
+	if res.Header.Get("ETag") != `"2"` || card.Version != 2 {
+		t.Errorf("patch flashcard: want version 2, got ETag %s and version %d", res.Header.Get("ETag"), card.Version)
+	}
+
+	res, raw = patch(alice, http.Header{"If-Match": {`"1"`}}, `{"tags": null}`)
+	if res.StatusCode != http.StatusPreconditionFailed || !strings.Contains(string(raw), `"current_version":2`) {
+		t.Errorf("patch a stale version: want 412, got %d %s", res.StatusCode, raw)
+	}
+
+	res, raw = patch(alice, http.Header{"If-Match": {`"2"`}}, `{"tags": null, "usage": []}`)
+	card = entities.Flashcard{}
+	if err := json.Unmarshal(raw, &card); err != nil || res.StatusCode != http.StatusOK || len(card.Tags) != 0 || len(card.UsageExamples) != 0 {
+		t.Errorf("clear tags and usage: got %d %s", res.StatusCode, raw)
+	}
+
+	for body, want := range map[string]int{
+		`{"id": "` + uuid.NewString() + `"}`: http.StatusBadRequest,
+		`{"version": 7}`:                     http.StatusBadRequest,
+		`["word_in_target"]`:                 http.StatusBadRequest,
+		`null`:                               http.StatusBadRequest,
+		`{"word_in_target": 1}`:              http.StatusBadRequest,
+		`{"tags": [" "]}`:                    http.StatusBadRequest,
+	} {
+		if res, raw := patch(alice, nil, body); res.StatusCode != want {
+			t.Errorf("patch %s: want %d, got %d %s", body, want, res.StatusCode, raw)
+		}
+	}
+
+	if res, _ := patch(bob, nil, `{"word_in_target": "baum"}`); res.StatusCode != http.StatusNotFound {
+		t.Errorf("patch by another user: want 404, got %d", res.StatusCode)
+	}
+
+	res, _ = alice.send(http.MethodPatch, path, nil, map[string]string{"word_in_target": "maus"})
+	if res.StatusCode != http.StatusUnsupportedMediaType || res.Header.Get("Accept-Patch") != "application/merge-patch+json" {
+		t.Errorf("patch as application/json: want 415, got %d", res.StatusCode)
+	}
+
+	// PUT changes every field which is given too
+	alice.do(http.MethodPut, "/flashcard", rest.EditFlashcardRequest{Id: cardID.String(), WordInTarget: "maus", WordInNative: "mouse"}, nil)
+
+	var cards rest.GetFlashcardResponse
+	alice.do(http.MethodGet, "/flashcard?id="+cardID.String(), nil, &cards)
+	if cards.Flashcards[0].Word != "maus" || cards.Flashcards[0].Meaning != "mouse" {
+		t.Errorf("edit flashcard: want word and meaning changed, got %+v", cards.Flashcards[0])
+	}
+}
+
+func TestTrash(t *testing.T) {
+	server, a := newServer(t)
+	alice := signUp(t, server, a, "alice")
+	bob := signUp(t, server, a, "bobby")
+
+	alice.do(http.MethodPost, "/flashcard", newFlashcard("hund", "dog"), nil)
+	cardID := alice.flashcardID("hund")
+
+	var created rest.CreateDeckResponse
+	alice.do(http.MethodPost, "/decks", rest.CreateDeckRequest{Name: "german"}, &created)
+	deckID := created.Deck.Id.String()
+
+	if status := alice.do(http.MethodDelete, "/flashcard?id="+cardID.String(), nil, nil); status != http.StatusOK {
+		t.Fatalf("delete flashcard: want 200, got %d", status)
+	}
+
+	if status := alice.do(http.MethodDelete, "/decks/"+deckID, nil, nil); status != http.StatusOK {
+		t.Fatalf("delete deck: want 200, got %d", status)
+	}
+
+	var trash rest.TrashResponse
+	if status := alice.do(http.MethodGet, "/trash", nil, &trash); status != http.StatusOK {
+		t.Fatalf("list trash: want 200, got %d", status)
+	}
+
+	if len(trash.Flashcards) != 1 || trash.Flashcards[0].ID != cardID || trash.Flashcards[0].DeletedAt == nil {
+		t.Errorf("trash: want the deleted card, got %+v", trash.Flashcards)
+	}
+
+	if len(trash.Decks) != 1 || trash.Decks[0].Id != created.Deck.Id {
+		t.Errorf("trash: want the deleted deck, got %+v", trash.Decks)
+	}
+
+	trash = rest.TrashResponse{}
+	bob.do(http.MethodGet, "/trash", nil, &trash)
+	if len(trash.Flashcards) != 0 || len(trash.Decks) != 0 {
+		t.Errorf("trash of another user: want empty, got %+v", trash)
+	}
+
+	if status := bob.do(http.MethodPost, "/trash/flashcards/"+cardID.String()+"/restore", nil, nil); status != http.StatusNotFound {
+		t.Errorf("restore card of another user: want 404, got %d", status)
+	}
+
+	if status := alice.do(http.MethodPost, "/trash/flashcards/"+cardID.String()+"/restore", nil, nil); status != http.StatusOK {
+		t.Fatalf("restore flashcard: want 200, got %d", status)
+	}
+
+	if status := alice.do(http.MethodGet, "/flashcard?id="+cardID.String(), nil, nil); status != http.StatusOK {
+		t.Errorf("get restored flashcard: want 200, got %d", status)
+	}
+
+	if status := alice.do(http.MethodPost, "/trash/flashcards/"+cardID.String()+"/restore", nil, nil); status != http.StatusNotFound {
+		t.Errorf("restore twice: want 404, got %d", status)
+	}
+
+	if status := alice.do(http.MethodPost, "/trash/decks/"+deckID+"/restore", nil, nil); status != http.StatusOK {
+		t.Fatalf("restore deck: want 200, got %d", status)
+	}
+
+	if status := alice.do(http.MethodGet, "/decks/"+deckID, nil, nil); status != http.StatusOK {
+		t.Errorf("get restored deck: want 200, got %d", status)
+	}
+
+	if status := alice.do(http.MethodPost, "/trash/decks/not-a-uuid/restore", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("restore with a bad id: want 400, got %d", status)
+	}
+}
+
+func TestFlashcardHistory(t *testing.T) {
+	server, a := newServer(t)
+	alice := signUp(t, server, a, "alice")
+	bob := signUp(t, server, a, "bobby")
+
+	alice.do(http.MethodPost, "/flashcard", newFlashcard("hund", "dog"), nil)
+	cardID := alice.flashcardID("hund")
+	path := "/flashcards/" + cardID.String() + "/history"
+
+	if status := alice.do(http.MethodPut, "/flashcard", rest.EditFlashcardRequest{Id: cardID.String(), WordInNative: "hound"}, nil); status != http.StatusOK {
+		t.Fatalf("edit flashcard: want 200, got %d", status)
+	}
+
+	var history rest.FlashcardHistoryResponse
+	if status := alice.do(http.MethodGet, path, nil, &history); status != http.StatusOK {
+		t.Fatalf("get history: want 200, got %d", status)
+	}
+
+	if len(history.Revisions) != 2 || history.Revisions[0].Action != entities.RevisionCreate || history.Revisions[1].Action != entities.RevisionUpdate {
+		t.Fatalf("history: want create and update, got %+v", history.Revisions)
+	}
+
+	update := history.Revisions[1]
+	if update.ActorID != alice.userID || update.RequestID == "" || string(update.Changes["meaning"].New) != `"hound"` {
+		t.Errorf("update revision: want alice, the request id and the meaning, got %+v", update)
+	}
+
+	if status := bob.do(http.MethodGet, path, nil, nil); status != http.StatusNotFound {
+		t.Errorf("history of another user: want 404, got %d", status)
+	}
+
+	revert := func(c *client, revisionID uuid.UUID, tag string) (*http.Response, []byte) {
+		header := http.Header{}
+		if tag != "" {
+			header.Set("If-Match", tag)
+		}
+
+		return c.send(http.MethodPost, path+"/"+revisionID.String()+"/revert", header, nil)
+	}
+
+	res, raw := revert(alice, history.Revisions[0].ID, `"2"`)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("revert: want 200, got %d %s", res.StatusCode, raw)
+	}
+
+	var card entities.Flashcard
+	if err := json.Unmarshal(raw, &card); err != nil || card.Meaning != "dog" || res.Header.Get("ETag") != `"3"` {
+		t.Errorf("revert: want the first meaning and version 3, got %s %s", res.Header.Get("ETag"), raw)
+	}
+
+	if res, raw := revert(alice, history.Revisions[1].ID, `"2"`); res.StatusCode != http.StatusPreconditionFailed || !strings.Contains(string(raw), `"current_version":3`) {
+		t.Errorf("revert a stale version: want 412, got %d %s", res.StatusCode, raw)
+	}
+
+	if res, _ := revert(bob, history.Revisions[1].ID, ""); res.StatusCode != http.StatusNotFound {
+		t.Errorf("revert by another user: want 404, got %d", res.StatusCode)
+	}
+
+	if res, _ := revert(alice, uuid.New(), ""); res.StatusCode != http.StatusNotFound {
+		t.Errorf("revert to an unknown revision: want 404, got %d", res.StatusCode)
+	}
+
+	history = rest.FlashcardHistoryResponse{}
+	alice.do(http.MethodGet, path, nil, &history)
+	if len(history.Revisions) != 3 || history.Revisions[2].Action != entities.RevisionUpdate || string(history.Revisions[2].Changes["meaning"].New) != `"dog"` {
+		t.Errorf("history after revert: want the revert as an update, got %+v", history.Revisions)
+	}
+}
+
+// signIn signs the client in as login and keeps the new token, it returns the
+// status of the sign in.
+func (c *client) signIn(login, password string) int {
+	c.t.Helper()
+
+	var resp rest.SignInResponse
+	status := c.do(http.MethodPost, "/signin", rest.SignInRequest{Login: login, Password: password}, &resp)
+	if status == http.StatusOK {
+		c.token = resp.Token
+	}
+
+	return status
+}
+
+// newAdmin registers a user, promotes it to admin in the storage and signs in
+// again to get a token with the role.
+func newAdmin(t *testing.T, server *httptest.Server, a *api.API, login string) *client {
+	t.Helper()
+
+	c := signUp(t, server, a, login)
+	err := a.Repo.Database().UpdateUser(context.Background(), repository.UpdateUserParams{
+		ID:   c.userID,
+		Role: models.RoleAdmin,
+	})
+	if err != nil {
+		t.Fatalf("error promote %s: %v", login, err)
+	}
+
+	if status := c.signIn(login, "correct horse"); status != http.StatusOK {
+		t.Fatalf("sign in %s: want 200, got %d", login, status)
+	}
+
+	return c
+}
+
+func TestAdminRequiresRole(t *testing.T) {
+	server, a := newServer(t)
+	alice := signUp(t, server, a, "alice")
+	bob := signUp(t, server, a, "bobby")
+
+	requests := []struct {
+		method string
+		path   string
+		body   any
+	}{
+		{http.MethodGet, "/admin/users", nil},
+		{http.MethodPost, "/admin/users/" + bob.userID.String() + "/disable", nil},
+		{http.MethodPost, "/admin/users/" + bob.userID.String() + "/enable", nil},
+		{http.MethodPost, "/admin/users/" + bob.userID.String() + "/password-reset", nil},
+		{http.MethodPut, "/admin/users/" + alice.userID.String() + "/role", rest.SetRoleRequest{Role: models.RoleAdmin}},
+	}
+
+	for _, req := range requests {
+		if status := alice.do(req.method, req.path, req.body, nil); status != http.StatusForbidden {
+			t.Errorf("%s %s as a user: want 403, got %d", req.method, req.path, status)
+		}
+	}
+
+	if status := bob.do(http.MethodGet, "/decks", nil, nil); status != http.StatusOK {
+		t.Errorf("list decks after the denied admin requests: want 200, got %d", status)
+	}
+
+	admin := newAdmin(t, server, a, "carol")
+	if status := admin.do(http.MethodGet, "/admin/users", nil, nil); status != http.StatusOK {
+		t.Errorf("list users as an admin: want 200, got %d", status)
+	}
+}
+
+func TestDisabledUser(t *testing.T) {
+	server, a := newServer(t)
+	admin := newAdmin(t, server, a, "carol")
+	alice := signUp(t, server, a, "alice")
+
+	path := "/admin/users/" + alice.userID.String()
+	if status := admin.do(http.MethodPost, path+"/disable", nil, nil); status != http.StatusNoContent {
+		t.Fatalf("disable user: want 204, got %d", status)
+	}
+
+	if status := alice.do(http.MethodGet, "/decks", nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("list decks with a token from before: want 401, got %d", status)
+	}
+
+	if status := alice.signIn("alice", "correct horse"); status != http.StatusForbidden {
+		t.Errorf("sign in while disabled: want 403, got %d", status)
+	}
+
+	if status := admin.do(http.MethodPost, path+"/enable", nil, nil); status != http.StatusNoContent {
+		t.Fatalf("enable user: want 204, got %d", status)
+	}
+
+	if status := alice.signIn("alice", "correct horse"); status != http.StatusOK {
+		t.Fatalf("sign in after enable: want 200, got %d", status)
+	}
+
+	if status := alice.do(http.MethodGet, "/decks", nil, nil); status != http.StatusOK {
+		t.Errorf("list decks after enable: want 200, got %d", status)
+	}
+}
+
+func TestPasswordResetRequired(t *testing.T) {
+	server, a := newServer(t)
+	admin := newAdmin(t, server, a, "carol")
+	alice := signUp(t, server, a, "alice")
+
+	var reset rest.PasswordResetResponse
+	path := "/admin/users/" + alice.userID.String() + "/password-reset"
+	if status := admin.do(http.MethodPost, path, nil, &reset); status != http.StatusOK || reset.ResetToken == "" {
+		t.Fatalf("require password reset: want 200 with a token, got %d, %+v", status, reset)
+	}
+
+	if status := alice.do(http.MethodGet, "/decks", nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("list decks with a token from before: want 401, got %d", status)
+	}
+
+	if status := alice.signIn("alice", "correct horse"); status != http.StatusForbidden {
+		t.Errorf("sign in before the reset: want 403, got %d", status)
+	}
+
+	// the old password is no proof while the reset is pending
+	change := rest.ChangePasswordRequest{Login: "alice", Password: "correct horse", NewPassword: "battery staple"}
+	if status := alice.do(http.MethodPost, "/password/change", change, nil); status != http.StatusForbidden {
+		t.Errorf("change password with the old password: want 403, got %d", status)
+	}
+
+	change = rest.ChangePasswordRequest{Login: "alice", ResetToken: "guessed", NewPassword: "battery staple"}
+	if status := alice.do(http.MethodPost, "/password/change", change, nil); status != http.StatusUnauthorized {
+		t.Errorf("change password with a wrong reset token: want 401, got %d", status)
+	}
+
+	change = rest.ChangePasswordRequest{Login: "alice", ResetToken: reset.ResetToken, NewPassword: "battery staple"}
+	if status := alice.do(http.MethodPost, "/password/change", change, nil); status != http.StatusOK {
+		t.Fatalf("change password with the reset token: want 200, got %d", status)
+	}
+
+	change.NewPassword = "tr0ub4dor&3"
+	if status := alice.do(http.MethodPost, "/password/change", change, nil); status != http.StatusUnauthorized {
+		t.Errorf("reuse the reset token: want 401, got %d", status)
+	}
+
+	if status := alice.signIn("alice", "correct horse"); status != http.StatusUnauthorized {
+		t.Errorf("sign in with the old password: want 401, got %d", status)
+	}
+
+	if status := alice.signIn("alice", "battery staple"); status != http.StatusOK {
+		t.Fatalf("sign in with the new password: want 200, got %d", status)
+	}
+
+	if status := alice.do(http.MethodGet, "/decks", nil, nil); status != http.StatusOK {
+		t.Errorf("list decks after the reset: want 200, got %d", status)
+	}
+}
+
+func TestChangePasswordRevokesSessions(t *testing.T) {
+	server, a := newServer(t)
+	alice := signUp(t, server, a, "alice")
+
+	other := &client{t: t, server: server, api: a}
+	if status := other.signIn("alice", "correct horse"); status != http.StatusOK {
+		t.Fatalf("sign in: want 200, got %d", status)
+	}
+
+	var resp rest.SignInResponse
+	change := rest.ChangePasswordRequest{Login: "alice", Password: "correct horse", NewPassword: "battery staple"}
+	if status := alice.do(http.MethodPost, "/password/change", change, &resp); status != http.StatusOK {
+		t.Fatalf("change password: want 200, got %d", status)
+	}
+	alice.token = resp.Token
+
+	if status := other.do(http.MethodGet, "/decks", nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("list decks in a session from before: want 401, got %d", status)
+	}
+
+	if status := alice.do(http.MethodGet, "/decks", nil, nil); status != http.StatusOK {
+		t.Errorf("list decks in the session of the change: want 200, got %d", status)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	server, a := newServer(t)
+	alice := signUp(t, server, a, "alice")
+	alice.do(http.MethodPost, "/flashcard", newFlashcard("hund", "dog"), nil)
+	id := alice.flashcardID("hund")
+
+	origin := http.Header{"Origin": {"https://languago.example"}}
+
+	// an edit sends If-Match, so the preflight has to allow it
+	preflight := http.Header{
+		"Origin":                         {"https://languago.example"},
+		"Access-Control-Request-Method":  {http.MethodPut},
+		"Access-Control-Request-Headers": {"Authorization, Content-Type, If-Match"},
+	}
+	res, _ := alice.send(http.MethodOptions, "/flashcard", preflight, nil)
+	if allowed := strings.ToLower(res.Header.Get("Access-Control-Allow-Headers")); !strings.Contains(allowed, "if-match") {
+		t.Errorf("preflight with If-Match: want it allowed, got %q", res.Header.Get("Access-Control-Allow-Headers"))
+	}
+
+	preflight.Set("Access-Control-Request-Method", http.MethodPatch)
+	res, _ = alice.send(http.MethodOptions, "/flashcards/"+id.String(), preflight, nil)
+	if allowed := res.Header.Get("Access-Control-Allow-Methods"); !strings.Contains(allowed, http.MethodPatch) {
+		t.Errorf("preflight for a patch: want PATCH allowed, got %q", allowed)
+	}
+
+	// the client reads the version to send back from the ETag
+	res, _ = alice.send(http.MethodGet, "/flashcard?id="+id.String(), origin, nil)
+	if exposed := strings.ToLower(res.Header.Get("Access-Control-Expose-Headers")); !strings.Contains(exposed, "etag") {
+		t.Errorf("get flashcard: want ETag exposed, got %q", res.Header.Get("Access-Control-Expose-Headers"))
+	}
+}
@@ -0,0 +1,105 @@
+package api_test
+
+import (
+	"languago/pkg/models/requests/rest"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDeckRoutes(t *testing.T) {
+	server, a := newServer(t)
+	alice := signUp(t, server, a, "alice")
+
+	var created rest.CreateDeckResponse
+	if status := alice.do(http.MethodPost, "/decks", rest.CreateDeckRequest{Name: "german"}, &created); status != http.StatusCreated {
+		t.Fatalf("create deck: want 201, got %d", status)
+	}
+	path := "/decks/" + created.Deck.Id.String()
+
+	var list rest.ListDecksResponse
+	if status := alice.do(http.MethodGet, "/decks", nil, &list); status != http.StatusOK || len(list.Decks) != 1 {
+		t.Errorf("list decks: want 200 and 1 deck, got %d and %+v", status, list.Decks)
+	}
+
+	if status := alice.do(http.MethodPut, path, rest.EditDeckRequest{Name: "deutsch"}, nil); status != http.StatusOK {
+		t.Errorf("edit deck: want 200, got %d", status)
+	}
+
+	var got rest.GetDeckResponse
+	if status := alice.do(http.MethodGet, path, nil, &got); status != http.StatusOK || got.Deck.Name != "deutsch" {
+		t.Errorf("get deck: want 200 and deutsch, got %d and %+v", status, got.Deck)
+	}
+
+	if status := alice.do(http.MethodDelete, path, nil, nil); status != http.StatusOK {
+		t.Errorf("delete deck: want 200, got %d", status)
+	}
+
+	if status := alice.do(http.MethodGet, path, nil, nil); status != http.StatusNotFound {
+		t.Errorf("get deleted deck: want 404, got %d", status)
+	}
+
+	if status := alice.do(http.MethodGet, "/decks/not-a-uuid", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("get deck with a malformed id: want 400, got %d", status)
+	}
+}
+
+func TestDeckRoutesValidateName(t *testing.T) {
+	server, a := newServer(t)
+	alice := signUp(t, server, a, "alice")
+
+	var created rest.CreateDeckResponse
+	alice.do(http.MethodPost, "/decks", rest.CreateDeckRequest{Name: "german"}, &created)
+	path := "/decks/" + created.Deck.Id.String()
+
+	for _, name := range []string{"", "  ", strings.Repeat("a", 201)} {
+		if status := alice.do(http.MethodPost, "/decks", rest.CreateDeckRequest{Name: name}, nil); status != http.StatusBadRequest {
+			t.Errorf("create deck named %q: want 400, got %d", name, status)
+		}
+
+		if status := alice.do(http.MethodPut, path, rest.EditDeckRequest{Name: name}, nil); status != http.StatusBadRequest {
+			t.Errorf("rename deck to %q: want 400, got %d", name, status)
+		}
+	}
+
+	if status := alice.do(http.MethodPost, "/decks", rest.CreateDeckRequest{Name: strings.Repeat("a", 200)}, nil); status != http.StatusCreated {
+		t.Errorf("create deck with a 200 characters name: want 201, got %d", status)
+	}
+}
+
+func TestDeckRoutesOwner(t *testing.T) {
+	server, a := newServer(t)
+	alice, bob := signUp(t, server, a, "alice"), signUp(t, server, a, "bobby")
+
+	var created rest.CreateDeckResponse
+	alice.do(http.MethodPost, "/decks", rest.CreateDeckRequest{Name: "german"}, &created)
+	path := "/decks/" + created.Deck.Id.String()
+
+	for _, req := range []struct {
+		method string
+		body   any
+	}{
+		{http.MethodGet, nil},
+		{http.MethodPut, rest.EditDeckRequest{Name: "mine"}},
+		{http.MethodDelete, nil},
+	} {
+		if status := bob.do(req.method, path, req.body, nil); status != http.StatusNotFound {
+			t.Errorf("%s deck of another user: want 404, got %d", req.method, status)
+		}
+	}
+
+	var list rest.ListDecksResponse
+	if status := bob.do(http.MethodGet, "/decks", nil, &list); status != http.StatusOK || len(list.Decks) != 0 {
+		t.Errorf("list decks of another user: want none, got %d and %+v", status, list.Decks)
+	}
+
+	var got rest.GetDeckResponse
+	if status := alice.do(http.MethodGet, path, nil, &got); status != http.StatusOK || got.Deck.Name != "german" {
+		t.Errorf("get deck after the attempts of another user: want it unchanged, got %d and %+v", status, got.Deck)
+	}
+
+	anonymous := &client{t: t, server: server}
+	if status := anonymous.do(http.MethodGet, "/decks", nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("list decks without a token: want 401, got %d", status)
+	}
+}
@@ -0,0 +1,64 @@
+package auth_test
+
+import (
+	"context"
+	"languago/infrastructure/config"
+	"languago/infrastructure/repository"
+	"languago/pkg/auth"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type mockConfig struct{}
+
+func (mockConfig) GetCredentials() repository.DBCredentials { return &repository.DBCred{} }
+func (mockConfig) IsMock() bool                             { return true }
+
+func authConfig() *config.AuthConfig {
+	return &config.AuthConfig{
+		CurrentKeyID:    "test",
+		Keys:            []auth.KeyConfig{{ID: "test", Algorithm: auth.AlgHS256, Secret: "secret"}},
+		Issuer:          "languago",
+		Audience:        "languago",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+		Leeway:          30 * time.Second,
+	}
+}
+
+// newAuthorizer returns an authorizer over the in-memory storage, with the
+// keyring it signs with.
+func newAuthorizer(t *testing.T, cfg *config.AuthConfig) (auth.Authorizer, *auth.Keyring, repository.Storage) {
+	t.Helper()
+
+	db, err := repository.NewDatabaseInteractor(mockConfig{})
+	if err != nil {
+		t.Fatalf("error create storage: %v", err)
+	}
+
+	keys, err := auth.NewKeyring(cfg)
+	if err != nil {
+		t.Fatalf("error create keyring: %v", err)
+	}
+
+	return auth.NewAuthorizer(zerolog.Nop(), db.Database(), keys, cfg), keys, db.Database()
+}
+
+func newUser(t *testing.T, storage repository.Storage) uuid.UUID {
+	t.Helper()
+
+	id := uuid.New()
+	err := storage.CreateUser(context.Background(), repository.CreateUserParams{
+		ID:       id,
+		Login:    "user-" + id.String(),
+		Password: "hash",
+	})
+	if err != nil {
+		t.Fatalf("error create user: %v", err)
+	}
+
+	return id
+}
@@ -0,0 +1,100 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"languago/pkg/auth"
+	"testing"
+	"time"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+func TestClaims(t *testing.T) {
+	a, keys, storage := newAuthorizer(t, authConfig())
+	userID := newUser(t, storage)
+
+	pair, err := a.CreateSession(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("error create session: %v", err)
+	}
+
+	now := time.Now()
+	// the leeway is 30s
+	at := func(d time.Duration) int64 { return now.Add(d).Unix() }
+
+	tests := []struct {
+		name   string
+		change func(jwt.MapClaims)
+		want   error
+		code   errors2.Code
+	}{
+		{"valid", func(jwt.MapClaims) {}, nil, 0},
+		{"audience list", func(c jwt.MapClaims) { c["aud"] = []string{"other", "languago"} }, nil, 0},
+		{"without nbf", func(c jwt.MapClaims) { delete(c, "nbf") }, nil, 0},
+		{"expired within the leeway", func(c jwt.MapClaims) { c["exp"] = at(-10 * time.Second) }, nil, 0},
+		{"issued within the leeway", func(c jwt.MapClaims) { c["iat"] = at(10 * time.Second) }, nil, 0},
+		{"nbf within the leeway", func(c jwt.MapClaims) { c["nbf"] = at(10 * time.Second) }, nil, 0},
+
+		{"expired", func(c jwt.MapClaims) { c["exp"] = at(-time.Minute) }, auth.ErrTokenExpired, errors2.CodeTokenExpired},
+		{"issued in the future", func(c jwt.MapClaims) { c["iat"] = at(time.Minute) }, auth.ErrTokenNotValidYet, errors2.CodeTokenNotValidYet},
+		{"not valid yet", func(c jwt.MapClaims) { c["nbf"] = at(time.Minute) }, auth.ErrTokenNotValidYet, errors2.CodeTokenNotValidYet},
+		{"without exp", func(c jwt.MapClaims) { delete(c, "exp") }, auth.ErrInvalidClaims, errors2.CodeInvalidClaims},
+		{"exp not a number", func(c jwt.MapClaims) { c["exp"] = "tomorrow" }, auth.ErrInvalidClaims, errors2.CodeInvalidClaims},
+		{"without iat", func(c jwt.MapClaims) { delete(c, "iat") }, auth.ErrInvalidClaims, errors2.CodeInvalidClaims},
+		{"nbf not a number", func(c jwt.MapClaims) { c["nbf"] = "soon" }, auth.ErrInvalidClaims, errors2.CodeInvalidClaims},
+		{"without jti", func(c jwt.MapClaims) { delete(c, "jti") }, auth.ErrInvalidClaims, errors2.CodeInvalidClaims},
+		{"empty jti", func(c jwt.MapClaims) { c["jti"] = "" }, auth.ErrInvalidClaims, errors2.CodeInvalidClaims},
+		{"other issuer", func(c jwt.MapClaims) { c["iss"] = "someone" }, auth.ErrInvalidIssuer, errors2.CodeInvalidIssuer},
+		{"without issuer", func(c jwt.MapClaims) { delete(c, "iss") }, auth.ErrInvalidIssuer, errors2.CodeInvalidIssuer},
+		{"other audience", func(c jwt.MapClaims) { c["aud"] = "someone" }, auth.ErrInvalidAudience, errors2.CodeInvalidAudience},
+		{"audience list without ours", func(c jwt.MapClaims) { c["aud"] = []string{"someone"} }, auth.ErrInvalidAudience, errors2.CodeInvalidAudience},
+		{"without audience", func(c jwt.MapClaims) { delete(c, "aud") }, auth.ErrInvalidAudience, errors2.CodeInvalidAudience},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := jwt.MapClaims{
+				"sub":  userID.String(),
+				"sid":  pair.SessionID.String(),
+				"role": "user",
+				"exp":  at(time.Minute),
+				"iat":  at(0),
+				"nbf":  at(0),
+				"jti":  uuid.NewString(),
+				"iss":  "languago",
+				"aud":  "languago",
+			}
+			tt.change(claims)
+
+			signed, err := keys.Sign(claims)
+			if err != nil {
+				t.Fatalf("error sign: %v", err)
+			}
+
+			token, err := a.ParseToken(signed)
+			if err != nil {
+				t.Fatalf("error parse: %v", err)
+			}
+
+			_, err = a.Authorize(token)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("authorize: want the token accepted, got %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("authorize: want %v, got %v", tt.want, err)
+			}
+
+			if code, _, ok := errors2.Describe(err); !ok || code != tt.code {
+				t.Errorf("code: want %d, got %d", tt.code, code)
+			}
+		})
+	}
+}
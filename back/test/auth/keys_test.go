@@ -0,0 +1,263 @@
+package auth_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"languago/infrastructure/config"
+	"languago/pkg/auth"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// keyFile writes the PEM block to a file of the test and returns its path.
+func keyFile(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("error write key file: %v", err)
+	}
+
+	return path
+}
+
+func ecKeyFile(t *testing.T, curve elliptic.Curve) (string, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	private, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatalf("error generate key: %v", err)
+	}
+
+	der, err := x509.MarshalECPrivateKey(private)
+	if err != nil {
+		t.Fatalf("error marshal key: %v", err)
+	}
+
+	return keyFile(t, "EC PRIVATE KEY", der), private
+}
+
+func publicKeyFile(t *testing.T, public crypto.PublicKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatalf("error marshal key: %v", err)
+	}
+
+	return keyFile(t, "PUBLIC KEY", der)
+}
+
+func edKeyFile(t *testing.T) (string, ed25519.PrivateKey) {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("error generate key: %v", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("error marshal key: %v", err)
+	}
+
+	return keyFile(t, "PRIVATE KEY", der), private
+}
+
+func keysConfig(current string, keys ...auth.KeyConfig) *config.AuthConfig {
+	cfg := authConfig()
+	cfg.CurrentKeyID = current
+	cfg.Keys = keys
+	return cfg
+}
+
+func TestKeyring(t *testing.T) {
+	ecFile, _ := ecKeyFile(t, elliptic.P256())
+	edFile, _ := edKeyFile(t)
+	hs := auth.KeyConfig{ID: "hs", Algorithm: auth.AlgHS256, Secret: "secret"}
+	es := auth.KeyConfig{ID: "es", Algorithm: auth.AlgES256, File: ecFile}
+	ed := auth.KeyConfig{ID: "ed", Algorithm: auth.AlgEdDSA, File: edFile}
+
+	for _, current := range []string{"hs", "es", "ed"} {
+		t.Run(current, func(t *testing.T) {
+			signer, err := auth.NewKeyring(keysConfig(current, hs, es, ed))
+			if err != nil {
+				t.Fatalf("error create keyring: %v", err)
+			}
+
+			signed, err := signer.Sign(jwt.MapClaims{"sub": "user"})
+			if err != nil {
+				t.Fatalf("error sign: %v", err)
+			}
+
+			// a node which rotated to another key still accepts the token
+			for _, other := range []string{"hs", "es", "ed"} {
+				verifier, _ := auth.NewKeyring(keysConfig(other, hs, es, ed))
+				token, err := jwt.Parse(signed, verifier.Keyfunc)
+				if err != nil || token.Header["kid"] != current {
+					t.Errorf("verify with %s current: want the token of key %s, got %v", other, current, err)
+				}
+			}
+
+			// the key was retired and removed
+			var rest []auth.KeyConfig
+			for _, kc := range []auth.KeyConfig{hs, es, ed} {
+				if kc.ID != current {
+					rest = append(rest, kc)
+				}
+			}
+
+			verifier, _ := auth.NewKeyring(keysConfig(rest[0].ID, rest...))
+			if _, err := jwt.Parse(signed, verifier.Keyfunc); err == nil || !errors.Is(err.(*jwt.ValidationError).Inner, auth.ErrUnknownKey) {
+				t.Errorf("verify with a removed key: want ErrUnknownKey, got %v", err)
+			}
+		})
+	}
+}
+
+func TestKeyringAlgorithmMismatch(t *testing.T) {
+	ecFile, private := ecKeyFile(t, elliptic.P256())
+	cfg := keysConfig("es",
+		auth.KeyConfig{ID: "es", Algorithm: auth.AlgES256, File: ecFile},
+		auth.KeyConfig{ID: "hs", Algorithm: auth.AlgHS256, Secret: "secret"},
+	)
+	a, _, _ := newAuthorizer(t, cfg)
+
+	publicDER, _ := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		token := jwt.NewWithClaims(method, jwt.MapClaims{"sub": "user"})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("error sign: %v", err)
+		}
+		return signed
+	}
+
+	tests := map[string]string{
+		// the public key of the ES256 key used as HMAC secret
+		"HS256 with an ES256 kid":   sign(jwt.SigningMethodHS256, "es", publicPEM),
+		"ES256 with an HS256 kid":   sign(jwt.SigningMethodES256, "hs", private),
+		"none":                      sign(jwt.SigningMethodNone, "es", jwt.UnsafeAllowNoneSignatureType),
+		"HS256 with a wrong secret": sign(jwt.SigningMethodHS256, "hs", []byte("guess")),
+	}
+
+	for name, signed := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := a.ParseToken(signed); !errors.Is(err, errors2.ErrInvalidToken) {
+				t.Errorf("parse: want ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+
+	unknown := sign(jwt.SigningMethodHS256, "other", []byte("secret"))
+	if _, err := a.ParseToken(unknown); !errors.Is(err, auth.ErrUnknownKey) {
+		t.Errorf("parse with an unknown kid: want ErrUnknownKey, got %v", err)
+	}
+}
+
+func TestKeyringConfig(t *testing.T) {
+	p384File, _ := ecKeyFile(t, elliptic.P384())
+	ecFile, private := ecKeyFile(t, elliptic.P256())
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+
+	tests := map[string]*config.AuthConfig{
+		"P-384 private key": keysConfig("es", auth.KeyConfig{ID: "es", Algorithm: auth.AlgES256, File: p384File}),
+		"P-384 public key": keysConfig("hs",
+			auth.KeyConfig{ID: "hs", Algorithm: auth.AlgHS256, Secret: "secret"},
+			auth.KeyConfig{ID: "es", Algorithm: auth.AlgES256, File: publicKeyFile(t, &p384.PublicKey)},
+		),
+		"current key is public only": keysConfig("es", auth.KeyConfig{ID: "es", Algorithm: auth.AlgES256, File: publicKeyFile(t, &private.PublicKey)}),
+		"EdDSA key for ES256":        keysConfig("es", auth.KeyConfig{ID: "es", Algorithm: auth.AlgES256, File: func() string { f, _ := edKeyFile(t); return f }()}),
+		"ES256 key for EdDSA":        keysConfig("ed", auth.KeyConfig{ID: "ed", Algorithm: auth.AlgEdDSA, File: ecFile}),
+		"empty secret":               keysConfig("hs", auth.KeyConfig{ID: "hs", Algorithm: auth.AlgHS256, SecretEnv: "LANGUAGO_TEST_UNSET"}),
+		"unknown algorithm":          keysConfig("rs", auth.KeyConfig{ID: "rs", Algorithm: "RS256", File: ecFile}),
+		"missing current key":        keysConfig("other", auth.KeyConfig{ID: "hs", Algorithm: auth.AlgHS256, Secret: "secret"}),
+		"duplicate id": keysConfig("hs",
+			auth.KeyConfig{ID: "hs", Algorithm: auth.AlgHS256, Secret: "secret"},
+			auth.KeyConfig{ID: "hs", Algorithm: auth.AlgHS256, Secret: "other"},
+		),
+	}
+
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := auth.NewKeyring(cfg); err == nil {
+				t.Errorf("new keyring: want an error")
+			}
+		})
+	}
+
+	if _, err := auth.NewKeyring(keysConfig("es", auth.KeyConfig{ID: "es", Algorithm: auth.AlgES256, File: ecFile})); err != nil {
+		t.Errorf("P-256 key: want it loaded, got %v", err)
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	ecFile, ecKey := ecKeyFile(t, elliptic.P256())
+	edFile, edKey := edKeyFile(t)
+
+	keys, err := auth.NewKeyring(keysConfig("hs",
+		auth.KeyConfig{ID: "hs", Algorithm: auth.AlgHS256, Secret: "secret"},
+		auth.KeyConfig{ID: "es", Algorithm: auth.AlgES256, File: ecFile},
+		auth.KeyConfig{ID: "ed", Algorithm: auth.AlgEdDSA, File: edFile},
+		// retired keys are published by their public part
+		auth.KeyConfig{ID: "retired", Algorithm: auth.AlgES256, File: publicKeyFile(t, &ecKey.PublicKey)},
+	))
+	if err != nil {
+		t.Fatalf("error create keyring: %v", err)
+	}
+
+	set := keys.JWKS()
+
+	var kids []string
+	for _, jwk := range set.Keys {
+		kids = append(kids, jwk.Kid)
+	}
+	if strings.Join(kids, ",") != "ed,es,retired" {
+		t.Fatalf("jwks: want the asymmetric keys sorted by kid, got %v", kids)
+	}
+
+	decode := func(s string) []byte {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatalf("error decode %q: %v", s, err)
+		}
+		return b
+	}
+
+	ed := set.Keys[0]
+	if ed.Kty != "OKP" || ed.Crv != "Ed25519" || ed.Alg != auth.AlgEdDSA || ed.Use != "sig" || ed.Y != "" ||
+		!bytes.Equal(decode(ed.X), edKey.Public().(ed25519.PublicKey)) {
+		t.Errorf("EdDSA jwk: got %+v", ed)
+	}
+
+	for _, es := range set.Keys[1:] {
+		x, y := decode(es.X), decode(es.Y)
+		if es.Kty != "EC" || es.Crv != "P-256" || es.Alg != auth.AlgES256 || len(x) != 32 || len(y) != 32 ||
+			!bytes.Equal(x, ecKey.X.FillBytes(make([]byte, 32))) || !bytes.Equal(y, ecKey.Y.FillBytes(make([]byte, 32))) {
+			t.Errorf("ES256 jwk: got %+v", es)
+		}
+	}
+
+	for _, jwk := range set.Keys {
+		if strings.Contains(jwk.X+jwk.Y, base64.RawURLEncoding.EncodeToString([]byte("secret"))) {
+			t.Errorf("jwks: want no HMAC secret, got %+v", jwk)
+		}
+	}
+}
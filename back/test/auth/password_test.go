@@ -0,0 +1,107 @@
+package auth_test
+
+import (
+	"errors"
+	"languago/pkg/auth"
+	"strings"
+	"testing"
+)
+
+// cheap keeps the tests fast, the defaults take 19MiB per hash
+var cheap = auth.PasswordParams{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func TestPasswordHash(t *testing.T) {
+	hasher := auth.NewPasswordHasher(cheap)
+
+	encoded, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("error hash: %v", err)
+	}
+
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("hash: want a PHC argon2id string, got %s", encoded)
+	}
+
+	if other, _ := hasher.Hash("correct horse"); other == encoded {
+		t.Errorf("hash twice: want different salts")
+	}
+
+	match, rehash, err := hasher.Verify("correct horse", encoded)
+	if !match || rehash || err != nil {
+		t.Errorf("verify: want a match without rehash, got %v, %v, %v", match, rehash, err)
+	}
+
+	match, rehash, err = hasher.Verify("wrong horse", encoded)
+	if match || rehash || err != nil {
+		t.Errorf("verify wrong password: want no match, got %v, %v, %v", match, rehash, err)
+	}
+}
+
+func TestPasswordRehash(t *testing.T) {
+	old, err := auth.NewPasswordHasher(cheap).Hash("correct horse")
+	if err != nil {
+		t.Fatalf("error hash: %v", err)
+	}
+
+	stronger := cheap
+	stronger.Iterations = 2
+	hasher := auth.NewPasswordHasher(stronger)
+
+	// the stored params are used to verify, the new ones only to rehash
+	match, rehash, err := hasher.Verify("correct horse", old)
+	if !match || !rehash || err != nil {
+		t.Errorf("verify outdated hash: want a match and a rehash, got %v, %v, %v", match, rehash, err)
+	}
+
+	match, rehash, _ = hasher.Verify("wrong horse", old)
+	if match || rehash {
+		t.Errorf("verify outdated hash with a wrong password: want no match and no rehash, got %v, %v", match, rehash)
+	}
+}
+
+func TestPasswordMalformedHash(t *testing.T) {
+	hasher := auth.NewPasswordHasher(cheap)
+	valid, _ := hasher.Hash("correct horse")
+	parts := strings.Split(valid, "$")
+
+	tests := map[string]string{
+		"missing part":  strings.Join(parts[:5], "$"),
+		"other version": strings.Replace(valid, "v=19", "v=16", 1),
+		"bad params":    strings.Replace(valid, "m=1024", "m=lots", 1),
+		"bad salt":      strings.Join([]string{"", parts[1], parts[2], parts[3], "!!", parts[5]}, "$"),
+		"bad key":       strings.Join([]string{"", parts[1], parts[2], parts[3], parts[4], "!!"}, "$"),
+	}
+
+	for name, encoded := range tests {
+		t.Run(name, func(t *testing.T) {
+			match, _, err := hasher.Verify("correct horse", encoded)
+			if match || !errors.Is(err, auth.ErrInvalidHash) {
+				t.Errorf("verify: want ErrInvalidHash, got %v, %v", match, err)
+			}
+		})
+	}
+}
+
+// TestPasswordLegacy covers the passwords stored as they were signed up with,
+// before they were hashed.
+func TestPasswordLegacy(t *testing.T) {
+	hasher := auth.NewPasswordHasher(cheap)
+
+	match, rehash, err := hasher.Verify("correct horse", "correct horse")
+	if !match || !rehash || err != nil {
+		t.Errorf("verify legacy password: want a match and a rehash, got %v, %v, %v", match, rehash, err)
+	}
+
+	for _, stored := range []string{"wrong horse", "", "$2a$10$abcdefghijklmnopqrstuuS2R5Yp7BkN0zxCdRn.t0y5WQvYx6a"} {
+		match, rehash, err := hasher.Verify("correct horse", stored)
+		if match || rehash || err != nil {
+			t.Errorf("verify against legacy %q: want no match, got %v, %v, %v", stored, match, rehash, err)
+		}
+	}
+}
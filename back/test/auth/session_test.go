@@ -0,0 +1,163 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"languago/infrastructure/repository"
+	"languago/pkg/auth"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// authorize checks the access token like the middleware does.
+func authorize(a auth.Authorizer, accessToken string) error {
+	token, err := a.ParseToken(accessToken)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.Authorize(token)
+	return err
+}
+
+func TestRefreshRotation(t *testing.T) {
+	a, _, storage := newAuthorizer(t, authConfig())
+	ctx := context.Background()
+
+	pair, err := a.CreateSession(ctx, newUser(t, storage))
+	if err != nil {
+		t.Fatalf("error create session: %v", err)
+	}
+
+	if err := authorize(a, pair.AccessToken); err != nil {
+		t.Fatalf("authorize: want the access token accepted, got %v", err)
+	}
+
+	next, err := a.Refresh(ctx, pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("error refresh: %v", err)
+	}
+
+	if next.SessionID != pair.SessionID || next.RefreshToken == pair.RefreshToken || next.AccessToken == pair.AccessToken {
+		t.Errorf("refresh: want new tokens for the same session, got %+v", next)
+	}
+
+	if err := authorize(a, next.AccessToken); err != nil {
+		t.Errorf("authorize refreshed token: want it accepted, got %v", err)
+	}
+
+	last, err := a.Refresh(ctx, next.RefreshToken)
+	if err != nil {
+		t.Fatalf("error refresh the rotated token: %v", err)
+	}
+
+	// the rotated token shows up again, so the whole session ends
+	if _, err := a.Refresh(ctx, pair.RefreshToken); !errors.Is(err, auth.ErrSessionRevoked) {
+		t.Errorf("reuse: want ErrSessionRevoked, got %v", err)
+	}
+
+	if _, err := a.Refresh(ctx, last.RefreshToken); !errors.Is(err, auth.ErrSessionRevoked) {
+		t.Errorf("refresh after reuse: want ErrSessionRevoked, got %v", err)
+	}
+
+	if err := authorize(a, last.AccessToken); !errors.Is(err, auth.ErrSessionRevoked) {
+		t.Errorf("authorize after reuse: want ErrSessionRevoked, got %v", err)
+	}
+}
+
+func TestSignOut(t *testing.T) {
+	a, _, storage := newAuthorizer(t, authConfig())
+	ctx := context.Background()
+	userID := newUser(t, storage)
+
+	pair, _ := a.CreateSession(ctx, userID)
+	other, _ := a.CreateSession(ctx, userID)
+
+	if err := a.Revoke(ctx, pair.RefreshToken); err != nil {
+		t.Fatalf("error revoke: %v", err)
+	}
+
+	if err := authorize(a, pair.AccessToken); !errors.Is(err, auth.ErrSessionRevoked) {
+		t.Errorf("authorize after sign out: want ErrSessionRevoked, got %v", err)
+	}
+
+	if _, err := a.Refresh(ctx, pair.RefreshToken); !errors.Is(err, auth.ErrSessionRevoked) {
+		t.Errorf("refresh after sign out: want ErrSessionRevoked, got %v", err)
+	}
+
+	// the other sessions of the user are kept
+	if err := authorize(a, other.AccessToken); err != nil {
+		t.Errorf("authorize another session: want it accepted, got %v", err)
+	}
+
+	// only the current refresh token signs out
+	next, _ := a.Refresh(ctx, other.RefreshToken)
+	if err := a.Revoke(ctx, other.RefreshToken); !errors.Is(err, auth.ErrInvalidRefreshToken) {
+		t.Errorf("revoke with a rotated token: want ErrInvalidRefreshToken, got %v", err)
+	}
+
+	if err := authorize(a, next.AccessToken); err != nil {
+		t.Errorf("authorize after a failed sign out: want it accepted, got %v", err)
+	}
+}
+
+func TestExpiredSession(t *testing.T) {
+	cfg := authConfig()
+	cfg.RefreshTokenTTL = -time.Minute
+	a, _, storage := newAuthorizer(t, cfg)
+	ctx := context.Background()
+
+	pair, err := a.CreateSession(ctx, newUser(t, storage))
+	if err != nil {
+		t.Fatalf("error create session: %v", err)
+	}
+
+	if _, err := a.Refresh(ctx, pair.RefreshToken); !errors.Is(err, auth.ErrSessionRevoked) {
+		t.Errorf("refresh expired session: want ErrSessionRevoked, got %v", err)
+	}
+
+	if err := authorize(a, pair.AccessToken); !errors.Is(err, auth.ErrSessionRevoked) {
+		t.Errorf("authorize expired session: want ErrSessionRevoked, got %v", err)
+	}
+}
+
+func TestDisabledUserSession(t *testing.T) {
+	a, _, storage := newAuthorizer(t, authConfig())
+	ctx := context.Background()
+	userID := newUser(t, storage)
+
+	pair, _ := a.CreateSession(ctx, userID)
+
+	disabled := true
+	if err := storage.UpdateUser(ctx, repository.UpdateUserParams{ID: userID, Disabled: &disabled}); err != nil {
+		t.Fatalf("error disable user: %v", err)
+	}
+
+	if _, err := a.Refresh(ctx, pair.RefreshToken); !errors.Is(err, auth.ErrUserDisabled) {
+		t.Errorf("refresh: want ErrUserDisabled, got %v", err)
+	}
+
+	if err := authorize(a, pair.AccessToken); !errors.Is(err, auth.ErrUserDisabled) {
+		t.Errorf("authorize: want ErrUserDisabled, got %v", err)
+	}
+
+	if _, err := a.CreateSession(ctx, userID); !errors.Is(err, auth.ErrUserDisabled) {
+		t.Errorf("create session: want ErrUserDisabled, got %v", err)
+	}
+}
+
+func TestInvalidRefreshToken(t *testing.T) {
+	a, _, _ := newAuthorizer(t, authConfig())
+
+	for _, token := range []string{"", "token", "not-a-uuid.secret", uuid.NewString() + ".", uuid.NewString() + ".secret"} {
+		if _, err := a.Refresh(context.Background(), token); !errors.Is(err, auth.ErrInvalidRefreshToken) {
+			t.Errorf("refresh %q: want ErrInvalidRefreshToken, got %v", token, err)
+		}
+
+		if err := a.Revoke(context.Background(), token); !errors.Is(err, auth.ErrInvalidRefreshToken) {
+			t.Errorf("revoke %q: want ErrInvalidRefreshToken, got %v", token, err)
+		}
+	}
+}
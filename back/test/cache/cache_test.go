@@ -0,0 +1,192 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"languago/pkg/cache"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInMemory(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewInMemory(0, 0)
+
+	if _, ok := c.Get(ctx, "hund"); ok {
+		t.Errorf("get missing key: want a miss")
+	}
+
+	value := []byte("dog")
+	if err := c.Set(ctx, "hund", value, 0); err != nil {
+		t.Fatalf("error set: %v", err)
+	}
+
+	// the cache keeps its own copy
+	value[0] = 'f'
+	got, ok := c.Get(ctx, "hund")
+	if !ok || string(got) != "dog" {
+		t.Errorf("get: want dog, got %q, %v", got, ok)
+	}
+
+	got[0] = 'f'
+	if got, _ := c.Get(ctx, "hund"); string(got) != "dog" {
+		t.Errorf("get after changing the value: want dog, got %q", got)
+	}
+
+	if err := c.Delete(ctx, "hund"); err != nil {
+		t.Fatalf("error delete: %v", err)
+	}
+
+	if _, ok := c.Get(ctx, "hund"); ok {
+		t.Errorf("get deleted key: want a miss")
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 2 || stats.Misses != 2 || stats.Entries != 0 || stats.Bytes != 0 {
+		t.Errorf("stats: want 2 hits and 2 misses of an empty cache, got %+v", stats)
+	}
+
+	c.Set(ctx, "hund", []byte("dog"), 0)
+	c.Set(ctx, "katze", []byte("cat"), 0)
+	if err := c.Flush(ctx); err != nil {
+		t.Fatalf("error flush: %v", err)
+	}
+
+	if stats := c.Stats(); stats.Entries != 0 || stats.Bytes != 0 {
+		t.Errorf("flush: want an empty cache, got %+v", stats)
+	}
+}
+
+func TestInMemoryTTL(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewInMemory(0, 0)
+
+	c.Set(ctx, "hund", []byte("dog"), 20*time.Millisecond)
+	c.Set(ctx, "katze", []byte("cat"), 0)
+
+	if _, ok := c.Get(ctx, "hund"); !ok {
+		t.Errorf("get before the ttl: want a hit")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+
+	if _, ok := c.Get(ctx, "hund"); ok {
+		t.Errorf("get after the ttl: want a miss")
+	}
+
+	if _, ok := c.Get(ctx, "katze"); !ok {
+		t.Errorf("get without ttl: want a hit")
+	}
+
+	if stats := c.Stats(); stats.Entries != 1 {
+		t.Errorf("expired entry: want it removed, got %+v", stats)
+	}
+}
+
+func TestInMemoryEviction(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("entries", func(t *testing.T) {
+		c := cache.NewInMemory(2, 0)
+
+		c.Set(ctx, "hund", []byte("dog"), 0)
+		c.Set(ctx, "katze", []byte("cat"), 0)
+		// hund is used last, so katze is evicted
+		c.Get(ctx, "hund")
+		c.Set(ctx, "maus", []byte("mouse"), 0)
+
+		if _, ok := c.Get(ctx, "katze"); ok {
+			t.Errorf("least recently used: want katze evicted")
+		}
+
+		for _, key := range []string{"hund", "maus"} {
+			if _, ok := c.Get(ctx, key); !ok {
+				t.Errorf("recently used: want %s kept", key)
+			}
+		}
+
+		if stats := c.Stats(); stats.Entries != 2 || stats.Evictions != 1 {
+			t.Errorf("stats: want 2 entries and 1 eviction, got %+v", stats)
+		}
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		c := cache.NewInMemory(0, 1024)
+
+		value := make([]byte, 300)
+		for i := 0; i < 10; i++ {
+			if err := c.Set(ctx, fmt.Sprint(i), value, 0); err != nil {
+				t.Fatalf("error set: %v", err)
+			}
+		}
+
+		stats := c.Stats()
+		if stats.Bytes > 1024 || stats.Entries == 0 || stats.Entries >= 10 || stats.Evictions == 0 {
+			t.Errorf("stats: want the cache within 1024 bytes, got %+v", stats)
+		}
+
+		if _, ok := c.Get(ctx, "9"); !ok {
+			t.Errorf("last set: want it kept")
+		}
+
+		if err := c.Set(ctx, "large", make([]byte, 2048), 0); !errors.Is(err, cache.ErrTooLarge) {
+			t.Errorf("set a value larger than the cache: want ErrTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("expired first", func(t *testing.T) {
+		c := cache.NewInMemory(2, 0)
+
+		c.Set(ctx, "hund", []byte("dog"), 0)
+		c.Set(ctx, "katze", []byte("cat"), time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+		// katze is the most recently used but it has expired
+		c.Set(ctx, "maus", []byte("mouse"), 0)
+
+		if _, ok := c.Get(ctx, "hund"); !ok {
+			t.Errorf("want hund kept over the expired katze")
+		}
+
+		if stats := c.Stats(); stats.Evictions != 0 {
+			t.Errorf("removing an expired entry: want no eviction, got %+v", stats)
+		}
+	})
+}
+
+// TestInMemoryConcurrent is meant for go test -race.
+func TestInMemoryConcurrent(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewInMemory(50, 4096)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 500; j++ {
+				key := fmt.Sprint(j % 80)
+				switch (i + j) % 4 {
+				case 0:
+					c.Set(ctx, key, []byte(key), time.Millisecond)
+				case 1:
+					c.Delete(ctx, key)
+				default:
+					c.Get(ctx, key)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stats := c.Stats()
+	if stats.Entries > 50 || stats.Bytes > 4096 {
+		t.Errorf("stats: want the cache within its limits, got %+v", stats)
+	}
+
+	if stats.Hits+stats.Misses != 8*250 {
+		t.Errorf("stats: want every get counted, got %+v", stats)
+	}
+}
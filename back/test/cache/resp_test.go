@@ -0,0 +1,454 @@
+package cache_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"languago/pkg/cache"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// respServer is an in-process stand-in for a Redis server, it knows the
+// commands the cache sends.
+type respServer struct {
+	t        *testing.T
+	listener net.Listener
+	password string
+
+	mu      sync.Mutex
+	entries map[string]respEntry
+	conns   map[net.Conn]struct{}
+	dials   int
+}
+
+type respEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func newRESPServer(t *testing.T, password string) *respServer {
+	t.Helper()
+
+	s := &respServer{
+		t:        t,
+		password: password,
+		entries:  make(map[string]respEntry),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	s.listen("127.0.0.1:0")
+	t.Cleanup(s.stop)
+
+	return s
+}
+
+func (s *respServer) listen(address string) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		s.t.Fatalf("error listen: %v", err)
+	}
+	s.listener = listener
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			s.mu.Lock()
+			s.conns[conn] = struct{}{}
+			s.dials++
+			s.mu.Unlock()
+
+			go s.serve(conn)
+		}
+	}()
+}
+
+func (s *respServer) address() string {
+	return s.listener.Addr().String()
+}
+
+// stop closes the listener and every connection, the entries are kept.
+func (s *respServer) stop() {
+	s.listener.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+		delete(s.conns, conn)
+	}
+}
+
+func (s *respServer) serve(conn net.Conn) {
+	defer func() {
+		conn.Close()
+
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	r := bufio.NewReader(conn)
+	authenticated := s.password == ""
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		name := strings.ToUpper(args[0])
+		if !authenticated && name != "AUTH" {
+			fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+
+		switch name {
+		case "AUTH":
+			if args[1] != s.password {
+				fmt.Fprint(conn, "-WRONGPASS invalid password\r\n")
+				continue
+			}
+			authenticated = true
+			fmt.Fprint(conn, "+OK\r\n")
+		case "SELECT":
+			fmt.Fprint(conn, "+OK\r\n")
+		case "GET":
+			value, ok := s.get(args[1])
+			if !ok {
+				fmt.Fprint(conn, "$-1\r\n")
+				continue
+			}
+			fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(value), value)
+		case "SET":
+			e := respEntry{value: []byte(args[2])}
+			if len(args) == 5 && strings.ToUpper(args[3]) == "PX" {
+				ms, _ := strconv.Atoi(args[4])
+				e.expiresAt = time.Now().Add(time.Duration(ms) * time.Millisecond)
+			}
+
+			s.mu.Lock()
+			s.entries[args[1]] = e
+			s.mu.Unlock()
+			fmt.Fprint(conn, "+OK\r\n")
+		case "DEL":
+			s.mu.Lock()
+			deleted := 0
+			for _, key := range args[1:] {
+				if _, ok := s.entries[key]; ok {
+					delete(s.entries, key)
+					deleted++
+				}
+			}
+			s.mu.Unlock()
+			fmt.Fprintf(conn, ":%d\r\n", deleted)
+		case "SCAN":
+			// a single page, MATCH is a prefix followed by *
+			prefix := strings.TrimSuffix(args[3], "*")
+			prefix = strings.ReplaceAll(prefix, `\`, "")
+
+			s.mu.Lock()
+			var keys []string
+			for key := range s.entries {
+				if strings.HasPrefix(key, prefix) {
+					keys = append(keys, key)
+				}
+			}
+			s.mu.Unlock()
+
+			fmt.Fprintf(conn, "*2\r\n$1\r\n0\r\n*%d\r\n", len(keys))
+			for _, key := range keys {
+				fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(key), key)
+			}
+		default:
+			fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+		}
+	}
+}
+
+func (s *respServer) get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+		delete(s.entries, key)
+		return nil, false
+	}
+
+	return e.value, true
+}
+
+func (s *respServer) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.entries))
+	for key := range s.entries {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// open waits a while for the clients to close their connections and returns
+// how many are still open.
+func (s *respServer) open() int {
+	deadline := time.Now().Add(time.Second)
+	for {
+		s.mu.Lock()
+		n := len(s.conns)
+		s.mu.Unlock()
+
+		if n == 0 || time.Now().After(deadline) {
+			return n
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if line[0] != '*' || err != nil {
+		return nil, fmt.Errorf("error unexpected command %q", line)
+	}
+
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+
+		b := make([]byte, size+2)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		args = append(args, string(b[:size]))
+	}
+
+	return args, nil
+}
+
+func TestRESP(t *testing.T) {
+	ctx := context.Background()
+	server := newRESPServer(t, "secret")
+
+	c := cache.NewRESP(cache.RESPOptions{Address: server.address(), Password: "secret", DB: 1, Namespace: "languago:test"})
+	t.Cleanup(func() { c.Close() })
+
+	if _, ok := c.Get(ctx, "hund"); ok {
+		t.Errorf("get missing key: want a miss")
+	}
+
+	if err := c.Set(ctx, "hund", []byte("dog\r\n"), 0); err != nil {
+		t.Fatalf("error set: %v", err)
+	}
+
+	if value, ok := c.Get(ctx, "hund"); !ok || string(value) != "dog\r\n" {
+		t.Errorf("get: want dog, got %q, %v", value, ok)
+	}
+
+	if keys := server.keys(); len(keys) != 1 || keys[0] != "languago:test:hund" {
+		t.Errorf("keys: want the key in the namespace, got %v", keys)
+	}
+
+	c.Set(ctx, "katze", []byte("cat"), 20*time.Millisecond)
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := c.Get(ctx, "katze"); ok {
+		t.Errorf("get after the ttl: want a miss")
+	}
+
+	if err := c.Delete(ctx, "hund"); err != nil {
+		t.Fatalf("error delete: %v", err)
+	}
+
+	if _, ok := c.Get(ctx, "hund"); ok {
+		t.Errorf("get deleted key: want a miss")
+	}
+
+	if stats := c.Stats(); stats.Hits != 1 || stats.Misses != 3 {
+		t.Errorf("stats: want 1 hit and 3 misses, got %+v", stats)
+	}
+
+	// another environment keeps its keys when this one is flushed
+	other := cache.NewRESP(cache.RESPOptions{Address: server.address(), Password: "secret", Namespace: "languago:other"})
+	t.Cleanup(func() { other.Close() })
+
+	c.Set(ctx, "hund", []byte("dog"), 0)
+	other.Set(ctx, "hund", []byte("hound"), 0)
+	if err := c.Flush(ctx); err != nil {
+		t.Fatalf("error flush: %v", err)
+	}
+
+	if _, ok := c.Get(ctx, "hund"); ok {
+		t.Errorf("get after flush: want a miss")
+	}
+
+	if value, ok := other.Get(ctx, "hund"); !ok || string(value) != "hound" {
+		t.Errorf("get from another namespace: want hound, got %q, %v", value, ok)
+	}
+
+	wrong := cache.NewRESP(cache.RESPOptions{Address: server.address(), Password: "wrong"})
+	t.Cleanup(func() { wrong.Close() })
+	if err := wrong.Set(ctx, "hund", []byte("dog"), 0); err == nil {
+		t.Errorf("set with a wrong password: want an error")
+	}
+}
+
+func TestRESPPool(t *testing.T) {
+	ctx := context.Background()
+	server := newRESPServer(t, "")
+
+	c := cache.NewRESP(cache.RESPOptions{Address: server.address(), PoolSize: 3})
+	t.Cleanup(func() { c.Close() })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				key := fmt.Sprint(i, "-", j)
+				if err := c.Set(ctx, key, []byte(key), 0); err != nil {
+					t.Errorf("error set: %v", err)
+					return
+				}
+
+				if value, ok := c.Get(ctx, key); !ok || string(value) != key {
+					t.Errorf("get %s: got %q, %v", key, value, ok)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	server.mu.Lock()
+	dials := server.dials
+	server.mu.Unlock()
+	if dials == 0 || dials > 3 {
+		t.Errorf("pool: want 1-3 connections, got %d", dials)
+	}
+}
+
+func TestRESPUnavailable(t *testing.T) {
+	ctx := context.Background()
+	server := newRESPServer(t, "")
+	address := server.address()
+
+	c := cache.NewRESP(cache.RESPOptions{Address: address, Timeout: 50 * time.Millisecond})
+	t.Cleanup(func() { c.Close() })
+
+	c.Set(ctx, "hund", []byte("dog"), 0)
+	server.stop()
+
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		if _, ok := c.Get(ctx, "hund"); ok {
+			t.Fatalf("get while the server is down: want a miss")
+		}
+	}
+
+	// the server is left alone after the first failure
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("misses while the server is down: want them fast, took %v", elapsed)
+	}
+
+	if err := c.Set(ctx, "hund", []byte("dog"), 0); !errors.Is(err, cache.ErrUnavailable) {
+		t.Errorf("set while the server is down: want ErrUnavailable, got %v", err)
+	}
+
+	// the server comes back on the same address with its data
+	server.listen(address)
+	time.Sleep(60 * time.Millisecond)
+
+	if value, ok := c.Get(ctx, "hund"); !ok || string(value) != "dog" {
+		t.Errorf("get after the server is back: want dog, got %q, %v", value, ok)
+	}
+}
+
+func TestRESPDeleteWhileDown(t *testing.T) {
+	ctx := context.Background()
+	server := newRESPServer(t, "")
+	address := server.address()
+
+	c := cache.NewRESP(cache.RESPOptions{Address: address, Namespace: "languago:test", Timeout: time.Second})
+	t.Cleanup(func() { c.Close() })
+
+	c.Set(ctx, "hund", []byte("dog"), 0)
+	c.Set(ctx, "katze", []byte("cat"), 0)
+
+	// the server restarts, the pooled connection is broken and the next
+	// reads skip the server for a while
+	server.stop()
+	c.Get(ctx, "hund")
+	server.listen(address)
+
+	if _, ok := c.Get(ctx, "hund"); ok {
+		t.Errorf("get while the server is left alone: want a miss")
+	}
+
+	if err := c.Delete(ctx, "hund"); err != nil {
+		t.Fatalf("error delete while the server is left alone: %v", err)
+	}
+
+	if keys := server.keys(); len(keys) != 1 || keys[0] != "languago:test:katze" {
+		t.Errorf("keys after delete: want only katze, got %v", keys)
+	}
+
+	// a delete on a connection broken meanwhile is sent again
+	server.stop()
+	server.listen(address)
+
+	if err := c.Flush(ctx); err != nil {
+		t.Fatalf("error flush after a restart: %v", err)
+	}
+
+	if keys := server.keys(); len(keys) != 0 {
+		t.Errorf("keys after flush: want none, got %v", keys)
+	}
+}
+
+func TestRESPClose(t *testing.T) {
+	ctx := context.Background()
+	server := newRESPServer(t, "")
+
+	c := cache.NewRESP(cache.RESPOptions{Address: server.address()})
+	c.Set(ctx, "hund", []byte("dog"), 0)
+	if err := c.Close(); err != nil {
+		t.Fatalf("error close: %v", err)
+	}
+
+	if n := server.open(); n != 0 {
+		t.Errorf("open connections after close: want 0, got %d", n)
+	}
+
+	// a connection given back after close isn't kept
+	c.Get(ctx, "hund")
+	if n := server.open(); n != 0 {
+		t.Errorf("open connections after a command past close: want 0, got %d", n)
+	}
+}
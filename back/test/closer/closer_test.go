@@ -0,0 +1,65 @@
+package closer_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"languago/pkg/closer"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCloser(t *testing.T) {
+	c := closer.NewCloser()
+
+	var closed []string
+	c.Add(func() error {
+		closed = append(closed, "database")
+		return nil
+	})
+	c.Add(func() error {
+		closed = append(closed, "cache")
+		return errors.New("cache is gone")
+	})
+	c.AddShutdown(func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Errorf("shutdown: want the context of Close")
+		}
+		closed = append(closed, "server")
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := c.Close(ctx)
+	if err == nil || !strings.Contains(err.Error(), "cache is gone") {
+		t.Errorf("close: want the error of the cache, got %v", err)
+	}
+
+	if got := fmt.Sprint(closed); got != "[server cache database]" {
+		t.Errorf("close order: want the last added first, got %s", got)
+	}
+
+	// everything is closed once
+	if err := c.Close(ctx); err != nil || len(closed) != 3 {
+		t.Errorf("close again: want nothing to close, got %v, %v", closed, err)
+	}
+}
+
+func TestCloserTimeout(t *testing.T) {
+	c := closer.NewCloser()
+
+	c.AddShutdown(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := c.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("close after the timeout: want DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package decks_test
+
+import (
+	"context"
+	"errors"
+	"languago/infrastructure/repository"
+	"languago/pkg/controllers/decks"
+	"languago/pkg/ctxtools"
+	"languago/pkg/models"
+	"languago/pkg/models/requests/rest"
+	"strings"
+	"testing"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type mockConfig struct{}
+
+func (mockConfig) GetCredentials() repository.DBCredentials { return &repository.DBCred{} }
+func (mockConfig) IsMock() bool                             { return true }
+
+func newController(t *testing.T) (decks.DecksController, repository.Storage) {
+	t.Helper()
+
+	interactor, err := repository.NewDatabaseInteractor(mockConfig{})
+	if err != nil {
+		t.Fatalf("error create storage: %v", err)
+	}
+
+	return decks.NewDecksController(zerolog.Nop(), interactor), interactor.Database()
+}
+
+// asUser creates a user and returns a context authorized as this user.
+func asUser(t *testing.T, storage repository.Storage) context.Context {
+	t.Helper()
+
+	id := uuid.New()
+	err := storage.CreateUser(context.Background(), repository.CreateUserParams{
+		ID:       id,
+		Login:    "user-" + id.String(),
+		Password: "hash",
+	})
+	if err != nil {
+		t.Fatalf("error create user: %v", err)
+	}
+
+	return context.WithValue(context.Background(), ctxtools.UserCtxKey, &models.User{Id: id})
+}
+
+func TestDecks(t *testing.T) {
+	c, storage := newController(t)
+	alice := asUser(t, storage)
+
+	created, err := c.CreateDeck(alice, &rest.CreateDeckRequest{Name: " german "})
+	if err != nil {
+		t.Fatalf("error create deck: %v", err)
+	}
+
+	deckID := created.Deck.Id
+	if created.Deck.Name != "german" {
+		t.Errorf("create deck: want the name trimmed, got %q", created.Deck.Name)
+	}
+
+	c.CreateDeck(alice, &rest.CreateDeckRequest{Name: "french"})
+
+	list, err := c.ListDecks(alice, &rest.ListDecksRequest{})
+	if err != nil || len(list.Decks) != 2 {
+		t.Fatalf("list decks: want 2, got %+v, %v", list, err)
+	}
+
+	list, err = c.ListDecks(alice, &rest.ListDecksRequest{Name: "french"})
+	if err != nil || len(list.Decks) != 1 || list.Decks[0].Name != "french" {
+		t.Errorf("list decks by name: want french, got %+v, %v", list, err)
+	}
+
+	if err := c.EditDeck(alice, deckID, &rest.EditDeckRequest{Name: "deutsch"}); err != nil {
+		t.Fatalf("error edit deck: %v", err)
+	}
+
+	got, err := c.GetDeck(alice, deckID)
+	if err != nil || got.Deck.Name != "deutsch" {
+		t.Errorf("get deck after edit: want deutsch, got %+v, %v", got, err)
+	}
+
+	if err := c.DeleteDeck(alice, deckID); err != nil {
+		t.Fatalf("error delete deck: %v", err)
+	}
+
+	if _, err := c.GetDeck(alice, deckID); !errors.Is(err, errors2.ErrNotFound) {
+		t.Errorf("get deleted deck: want ErrNotFound, got %v", err)
+	}
+
+	if err := c.DeleteDeck(alice, deckID); !errors.Is(err, errors2.ErrNotFound) {
+		t.Errorf("delete deleted deck: want ErrNotFound, got %v", err)
+	}
+}
+
+func TestDeckName(t *testing.T) {
+	c, storage := newController(t)
+	alice := asUser(t, storage)
+
+	created, err := c.CreateDeck(alice, &rest.CreateDeckRequest{Name: "german"})
+	if err != nil {
+		t.Fatalf("error create deck: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		valid bool
+	}{
+		{"", false},
+		{"   ", false},
+		{"a", true},
+		{strings.Repeat("a", 200), true},
+		{strings.Repeat("ä", 200), true},
+		{strings.Repeat("a", 201), false},
+		{" " + strings.Repeat("a", 200) + " ", true},
+	} {
+		_, err := c.CreateDeck(alice, &rest.CreateDeckRequest{Name: tt.name})
+		if tt.valid && err != nil {
+			t.Errorf("create deck named %q: want no error, got %v", tt.name, err)
+		}
+		if !tt.valid && (!errors.Is(err, decks.ErrInvalidDeckName) || !errors.Is(err, errors2.ErrValidation)) {
+			t.Errorf("create deck named %q: want ErrInvalidDeckName, got %v", tt.name, err)
+		}
+
+		err = c.EditDeck(alice, created.Deck.Id, &rest.EditDeckRequest{Name: tt.name})
+		if tt.valid && err != nil {
+			t.Errorf("rename deck to %q: want no error, got %v", tt.name, err)
+		}
+		if !tt.valid && !errors.Is(err, decks.ErrInvalidDeckName) {
+			t.Errorf("rename deck to %q: want ErrInvalidDeckName, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestDeckOwner(t *testing.T) {
+	c, storage := newController(t)
+	alice, bob := asUser(t, storage), asUser(t, storage)
+
+	created, err := c.CreateDeck(alice, &rest.CreateDeckRequest{Name: "german"})
+	if err != nil {
+		t.Fatalf("error create deck: %v", err)
+	}
+	deckID := created.Deck.Id
+
+	if _, err := c.GetDeck(bob, deckID); !errors.Is(err, errors2.ErrNotFound) {
+		t.Errorf("get deck of another user: want ErrNotFound, got %v", err)
+	}
+
+	if err := c.EditDeck(bob, deckID, &rest.EditDeckRequest{Name: "mine"}); !errors.Is(err, errors2.ErrNotFound) {
+		t.Errorf("edit deck of another user: want ErrNotFound, got %v", err)
+	}
+
+	if err := c.DeleteDeck(bob, deckID); !errors.Is(err, errors2.ErrNotFound) {
+		t.Errorf("delete deck of another user: want ErrNotFound, got %v", err)
+	}
+
+	if err := c.AddFlashcard(bob, deckID, uuid.New()); !errors.Is(err, errors2.ErrNotFound) {
+		t.Errorf("add to deck of another user: want ErrNotFound, got %v", err)
+	}
+
+	if list, err := c.ListDecks(bob, &rest.ListDecksRequest{}); err != nil || len(list.Decks) != 0 {
+		t.Errorf("list decks of another user: want none, got %+v, %v", list, err)
+	}
+
+	if got, err := c.GetDeck(alice, deckID); err != nil || got.Deck.Name != "german" {
+		t.Errorf("get deck after the attempts of another user: want it unchanged, got %+v, %v", got, err)
+	}
+
+	if _, err := c.CreateDeck(context.Background(), &rest.CreateDeckRequest{Name: "german"}); !errors.Is(err, errors2.ErrUnauthorized) {
+		t.Errorf("create deck without a user: want ErrUnauthorized, got %v", err)
+	}
+}
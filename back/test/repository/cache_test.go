@@ -0,0 +1,156 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"languago/infrastructure/repository"
+	"languago/pkg/cache"
+	"languago/pkg/models"
+	"testing"
+	"time"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+func cached(db repository.DatabaseInteractor) (repository.DatabaseInteractor, cache.Cache) {
+	c := cache.NewInMemory(0, 0)
+	return repository.NewCachedInteractor(db, c, repository.CacheTTL{
+		User:      time.Minute,
+		Deck:      time.Minute,
+		Flashcard: time.Minute,
+	}), c
+}
+
+func TestCachedUser(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			cachedDB, c := cached(db)
+			storage := cachedDB.Database()
+			userID, _ := newUser(t, storage)
+
+			for i := 0; i < 2; i++ {
+				if _, err := storage.SelectUser(context.Background(), repository.SelectUserParams{ID: userID}); err != nil {
+					t.Fatalf("error select user: %v", err)
+				}
+			}
+
+			if stats := c.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+				t.Errorf("select twice: want a miss and a hit, got %+v", stats)
+			}
+
+			// a change made around the cache is seen once the entry expires
+			err := db.Database().UpdateUser(context.Background(), repository.UpdateUserParams{ID: userID, Role: models.RoleTeacher})
+			if err != nil {
+				t.Fatalf("error update user: %v", err)
+			}
+
+			if user, _ := storage.SelectUser(context.Background(), repository.SelectUserParams{ID: userID}); user.Role != models.RoleUser {
+				t.Errorf("change around the cache: want the cached role, got %s", user.Role)
+			}
+
+			err = storage.UpdateUser(context.Background(), repository.UpdateUserParams{ID: userID, Role: models.RoleAdmin})
+			if err != nil {
+				t.Fatalf("error update user: %v", err)
+			}
+
+			if user, _ := storage.SelectUser(context.Background(), repository.SelectUserParams{ID: userID}); user.Role != models.RoleAdmin {
+				t.Errorf("change through the cache: want admin, got %s", user.Role)
+			}
+
+			// the login lookup isn't cached, it needs the password hash
+			user, err := storage.SelectUser(context.Background(), repository.SelectUserParams{Login: "user-" + userID.String()})
+			if err != nil || user.Password != "hash" {
+				t.Errorf("select by login: want the password hash, got %+v, %v", user, err)
+			}
+		})
+	}
+}
+
+func TestCachedFlashcardsAndDecks(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			cachedDB, _ := cached(db)
+			storage := cachedDB.Database()
+			_, user := newUser(t, storage)
+
+			cardID := uuid.New()
+			if err := storage.CreateFlashcard(user, repository.CreateFlashcardParams{ID: cardID, Word: "hund", Meaning: "dog"}); err != nil {
+				t.Fatalf("error create flashcard: %v", err)
+			}
+
+			selectCard := func() (string, error) {
+				cards, err := storage.SelectFlashcard(user, repository.SelectFlashcardParams{ID: cardID})
+				if err != nil {
+					return "", err
+				}
+				return cards[0].Meaning, nil
+			}
+
+			selectCard()
+			if err := storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: cardID, Meaning: ptr("hound")}); err != nil {
+				t.Fatalf("error update flashcard: %v", err)
+			}
+
+			if meaning, err := selectCard(); meaning != "hound" {
+				t.Errorf("update: want hound, got %q, %v", meaning, err)
+			}
+
+			// a rolled back change leaves nothing behind in the cache
+			err := cachedDB.WithTx(user, func(storage repository.Storage) error {
+				if err := storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: cardID, Meaning: ptr("puppy")}); err != nil {
+					return err
+				}
+
+				cards, err := storage.SelectFlashcard(user, repository.SelectFlashcardParams{ID: cardID})
+				if err != nil || cards[0].Meaning != "puppy" {
+					t.Errorf("select within the transaction: want puppy, got %+v, %v", cards, err)
+				}
+
+				return errors.New("rollback")
+			})
+			if err == nil {
+				t.Fatalf("transaction: want the error, got nil")
+			}
+
+			if meaning, err := selectCard(); meaning != "hound" {
+				t.Errorf("rolled back update: want hound, got %q, %v", meaning, err)
+			}
+
+			err = cachedDB.WithTx(user, func(storage repository.Storage) error {
+				return storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: cardID, Meaning: ptr("puppy")})
+			})
+			if err != nil {
+				t.Fatalf("error update within a transaction: %v", err)
+			}
+
+			if meaning, err := selectCard(); meaning != "puppy" {
+				t.Errorf("committed update: want puppy, got %q, %v", meaning, err)
+			}
+
+			if err := storage.DeleteFlashcard(user, cardID); err != nil {
+				t.Fatalf("error delete flashcard: %v", err)
+			}
+
+			if _, err := selectCard(); !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("deleted card: want ErrNotFound, got %v", err)
+			}
+
+			deckID := uuid.New()
+			if err := storage.CreateDeck(user, repository.CreateDeckParams{ID: deckID, Name: "german"}); err != nil {
+				t.Fatalf("error create deck: %v", err)
+			}
+
+			storage.SelectDeck(user, repository.SelectDeckParams{ID: deckID})
+			if err := storage.UpdateDeck(user, repository.UpdateDeckParams{ID: deckID, Name: "deutsch"}); err != nil {
+				t.Fatalf("error update deck: %v", err)
+			}
+
+			deck, err := storage.SelectDeck(user, repository.SelectDeckParams{ID: deckID})
+			if err != nil || deck.Name != "deutsch" || deck.Version != 2 {
+				t.Errorf("updated deck: want deutsch at version 2, got %+v, %v", deck, err)
+			}
+		})
+	}
+}
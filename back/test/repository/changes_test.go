@@ -0,0 +1,105 @@
+package repository_test
+
+import (
+	"context"
+	"languago/infrastructure/repository"
+	"languago/pkg/changes"
+	"languago/pkg/models/entities"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestLocalBus(t *testing.T) {
+	bus := changes.NewLocalBus()
+
+	var got []changes.Change
+	cancel := bus.Subscribe(func(change changes.Change) { got = append(got, change) })
+
+	change := changes.Change{Entity: entities.EntityDeck, ID: uuid.New(), Owner: uuid.New()}
+	bus.Publish(context.Background(), change)
+	cancel()
+	bus.Publish(context.Background(), changes.All)
+
+	if len(got) != 1 || got[0] != change {
+		t.Errorf("subscriber: want the change before cancel, got %+v", got)
+	}
+}
+
+func TestChangesAcrossNodes(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			// two nodes with caches of their own over the same database
+			first, _ := cached(db)
+			second, secondCache := cached(db)
+			_, user := newUser(t, first.Database())
+
+			cardID := uuid.New()
+			err := first.Database().CreateFlashcard(user, repository.CreateFlashcardParams{ID: cardID, Word: "hund", Meaning: "dog"})
+			if err != nil {
+				t.Fatalf("error create flashcard: %v", err)
+			}
+
+			selectCard := func(node repository.DatabaseInteractor) string {
+				cards, err := node.Database().SelectFlashcard(user, repository.SelectFlashcardParams{ID: cardID})
+				if err != nil {
+					t.Fatalf("error select flashcard: %v", err)
+				}
+				return cards[0].Meaning
+			}
+
+			selectCard(first)
+			selectCard(second)
+
+			err = first.Database().UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: cardID, Meaning: ptr("hound")})
+			if err != nil {
+				t.Fatalf("error update flashcard: %v", err)
+			}
+
+			if meaning := selectCard(second); meaning != "hound" {
+				t.Errorf("update on another node: want hound, got %s", meaning)
+			}
+
+			// a change within a transaction is published once it is committed
+			selectCard(second)
+			err = first.WithTx(user, func(storage repository.Storage) error {
+				if err := storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: cardID, Meaning: ptr("puppy")}); err != nil {
+					return err
+				}
+
+				if meaning := selectCard(second); meaning != "hound" {
+					t.Errorf("uncommitted update on another node: want hound, got %s", meaning)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("error update within a transaction: %v", err)
+			}
+
+			if meaning := selectCard(second); meaning != "puppy" {
+				t.Errorf("committed update on another node: want puppy, got %s", meaning)
+			}
+
+			deckID := uuid.New()
+			if err := first.Database().CreateDeck(user, repository.CreateDeckParams{ID: deckID, Name: "german"}); err != nil {
+				t.Fatalf("error create deck: %v", err)
+			}
+
+			second.Database().SelectDeck(user, repository.SelectDeckParams{ID: deckID})
+			if err := first.Database().DeleteDeck(user, repository.DeleteDeckParams{ID: deckID}); err != nil {
+				t.Fatalf("error delete deck: %v", err)
+			}
+
+			if _, err := second.Database().SelectDeck(user, repository.SelectDeckParams{ID: deckID}); err == nil {
+				t.Errorf("deck deleted on another node: want an error, got nil")
+			}
+
+			// missed changes drop everything
+			selectCard(second)
+			db.Changes().Publish(context.Background(), changes.All)
+			if stats := secondCache.Stats(); stats.Entries != 0 {
+				t.Errorf("all changed: want an empty cache, got %d entries", stats.Entries)
+			}
+		})
+	}
+}
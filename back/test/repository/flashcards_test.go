@@ -0,0 +1,170 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"languago/infrastructure/repository"
+	"languago/pkg/ctxtools"
+	"languago/pkg/models"
+	"languago/pkg/models/entities"
+	"testing"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+type mockConfig struct{}
+
+func (mockConfig) GetCredentials() repository.DBCredentials { return &repository.DBCred{} }
+func (mockConfig) IsMock() bool                             { return true }
+
+func newMockStorage(t *testing.T) repository.Storage {
+	t.Helper()
+
+	db, err := repository.NewDatabaseInteractor(mockConfig{})
+	if err != nil {
+		t.Fatalf("error create mock storage: %v", err)
+	}
+
+	return db.Database()
+}
+
+func asUser(id uuid.UUID) context.Context {
+	return context.WithValue(context.Background(), ctxtools.UserCtxKey, &models.User{Id: id})
+}
+
+// newUser creates a user and returns a context acting on its behalf.
+func newUser(t *testing.T, storage repository.Storage) (uuid.UUID, context.Context) {
+	t.Helper()
+
+	id := uuid.New()
+	err := storage.CreateUser(context.Background(), repository.CreateUserParams{
+		ID:       id,
+		Login:    "user-" + id.String(),
+		Password: "hash",
+	})
+	if err != nil {
+		t.Fatalf("error create user: %v", err)
+	}
+
+	return id, asUser(id)
+}
+
+func TestFlashcardOwnership(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+
+			_, owner := newUser(t, storage)
+			_, stranger := newUser(t, storage)
+			cardID := uuid.New()
+
+			err := storage.CreateFlashcard(owner, repository.CreateFlashcardParams{
+				ID:      cardID,
+				Word:    "hund",
+				Meaning: "dog",
+			})
+			if err != nil {
+				t.Fatalf("error create flashcard: %v", err)
+			}
+
+			cards, err := storage.SelectFlashcard(owner, repository.SelectFlashcardParams{ID: cardID})
+			if err != nil || len(cards) != 1 || cards[0].Word != "hund" {
+				t.Fatalf("owner should read own flashcard, got %v, %v", cards, err)
+			}
+
+			cards, err = storage.SelectFlashcard(stranger, repository.SelectFlashcardParams{})
+			if err != nil || len(cards) != 0 {
+				t.Fatalf("stranger should see no flashcards, got %v, %v", cards, err)
+			}
+
+			if _, err := storage.SelectFlashcard(stranger, repository.SelectFlashcardParams{ID: cardID}); !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("select by stranger: want ErrNotFound, got %v", err)
+			}
+
+			err = storage.UpdateFlashcard(stranger, repository.UpdateFlashcardParams{ID: cardID, Word: ptr("katze")})
+			if !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("update by stranger: want ErrNotFound, got %v", err)
+			}
+
+			if err := storage.DeleteFlashcard(stranger, cardID); !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("delete by stranger: want ErrNotFound, got %v", err)
+			}
+
+			if err := storage.DeleteFlashcard(owner, cardID); err != nil {
+				t.Errorf("delete by owner: %v", err)
+			}
+		})
+	}
+}
+
+func TestFlashcardRequiresCaller(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+			ctx := context.Background()
+
+			err := storage.CreateFlashcard(ctx, repository.CreateFlashcardParams{ID: uuid.New()})
+			if !errors.Is(err, errors2.ErrUnauthorized) {
+				t.Errorf("create without caller: want ErrUnauthorized, got %v", err)
+			}
+
+			if _, err := storage.SelectFlashcard(ctx, repository.SelectFlashcardParams{}); !errors.Is(err, errors2.ErrUnauthorized) {
+				t.Errorf("select without caller: want ErrUnauthorized, got %v", err)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestUpdateFlashcardFields(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+			_, user := newUser(t, storage)
+
+			cardID := uuid.New()
+			err := storage.CreateFlashcard(user, repository.CreateFlashcardParams{
+				ID: cardID, Word: "hund", Meaning: "dog", Usage: []string{"der Hund bellt"}, Tags: []string{"noun"},
+			})
+			if err != nil {
+				t.Fatalf("error create flashcard: %v", err)
+			}
+
+			card := func() *entities.Flashcard {
+				cards, err := storage.SelectFlashcard(user, repository.SelectFlashcardParams{ID: cardID})
+				if err != nil {
+					t.Fatalf("error select flashcard: %v", err)
+				}
+				return cards[0]
+			}
+
+			// every field which is set is changed at once
+			err = storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{
+				ID: cardID, Word: ptr("katze"), Meaning: ptr("cat"), Usage: []string{"die Katze schläft"},
+			})
+			if err != nil {
+				t.Fatalf("error update flashcard: %v", err)
+			}
+
+			got := card()
+			if got.Word != "katze" || got.Meaning != "cat" || fmt.Sprint(got.UsageExamples) != "[die Katze schläft]" || fmt.Sprint(got.Tags) != "[noun]" {
+				t.Errorf("want every given field changed and the tags kept, got %+v", got)
+			}
+
+			if err := storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: cardID, Meaning: ptr(""), Usage: []string{}}); err != nil {
+				t.Fatalf("error clear fields: %v", err)
+			}
+
+			got = card()
+			if got.Word != "katze" || got.Meaning != "" || len(got.UsageExamples) != 0 {
+				t.Errorf("want meaning and usage cleared, got %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"languago/infrastructure/repository"
+	"languago/pkg/models/entities"
+	"testing"
+
+	errors2 "languago/pkg/errors"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+// actions returns the action of each revision, each with the fields it
+// changed.
+func actions(revisions []*entities.Revision) []string {
+	resp := make([]string, 0, len(revisions))
+	for _, revision := range revisions {
+		fields := make([]string, 0, len(revision.Changes))
+		for _, name := range []string{"word", "meaning", "usage", "tags", "name", "deleted_at"} {
+			if _, ok := revision.Changes[name]; ok {
+				fields = append(fields, name)
+			}
+		}
+		resp = append(resp, fmt.Sprintf("%s%v", revision.Action, fields))
+	}
+	return resp
+}
+
+func TestFlashcardHistory(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+			userID, user := newUser(t, storage)
+			_, stranger := newUser(t, storage)
+			user = context.WithValue(user, chimw.RequestIDKey, "request-1")
+
+			cardID := uuid.New()
+			err := storage.CreateFlashcard(user, repository.CreateFlashcardParams{ID: cardID, Word: "hund", Meaning: "dog", Tags: []string{"noun"}})
+			if err != nil {
+				t.Fatalf("error create flashcard: %v", err)
+			}
+
+			err = storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: cardID, Meaning: ptr("hound"), Usage: []string{"der Hund bellt"}})
+			if err != nil {
+				t.Fatalf("error update flashcard: %v", err)
+			}
+
+			// a change to the same values is no revision
+			if err := storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: cardID, Word: ptr("hund")}); err != nil {
+				t.Fatalf("error update flashcard: %v", err)
+			}
+
+			if err := storage.DeleteFlashcard(user, cardID); err != nil {
+				t.Fatalf("error delete flashcard: %v", err)
+			}
+
+			if err := storage.RestoreFlashcard(user, cardID); err != nil {
+				t.Fatalf("error restore flashcard: %v", err)
+			}
+
+			params := repository.SelectRevisionsParams{EntityType: entities.EntityFlashcard, EntityID: cardID}
+			revisions, err := storage.SelectRevisions(user, params)
+			if err != nil {
+				t.Fatalf("error select revisions: %v", err)
+			}
+
+			want := "[create[word meaning usage tags] update[meaning usage] delete[deleted_at] restore[deleted_at]]"
+			if got := fmt.Sprint(actions(revisions)); got != want {
+				t.Fatalf("history: want %s, got %s", want, got)
+			}
+
+			update := revisions[1]
+			if string(update.Changes["meaning"].Old) != `"dog"` || string(update.Changes["meaning"].New) != `"hound"` {
+				t.Errorf("update diff: want dog to hound, got %+v", update.Changes["meaning"])
+			}
+
+			if update.ActorID != userID || update.RequestID != "request-1" || update.Owner != userID || update.Version != 2 {
+				t.Errorf("update revision: want actor, request id, owner and version 2, got %+v", update)
+			}
+
+			var snapshot repository.FlashcardSnapshot
+			if err := json.Unmarshal(update.Snapshot, &snapshot); err != nil || snapshot.Meaning != "hound" || fmt.Sprint(snapshot.Tags) != "[noun]" {
+				t.Errorf("update snapshot: want the card after the update, got %+v, %v", snapshot, err)
+			}
+
+			revision, err := storage.SelectRevision(user, repository.SelectRevisionsParams{
+				ID: update.ID, EntityType: entities.EntityFlashcard, EntityID: cardID,
+			})
+			if err != nil || revision.ID != update.ID || len(revision.Changes) != 2 {
+				t.Errorf("select revision: want the update, got %+v, %v", revision, err)
+			}
+
+			if revisions, _ := storage.SelectRevisions(stranger, params); len(revisions) != 0 {
+				t.Errorf("history of another user: want none, got %d", len(revisions))
+			}
+
+			_, err = storage.SelectRevision(stranger, repository.SelectRevisionsParams{
+				ID: update.ID, EntityType: entities.EntityFlashcard, EntityID: cardID,
+			})
+			if !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("revision of another user: want ErrNotFound, got %v", err)
+			}
+
+			_, err = storage.SelectRevision(user, repository.SelectRevisionsParams{
+				ID: update.ID, EntityType: entities.EntityDeck, EntityID: cardID,
+			})
+			if !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("revision of another entity type: want ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDeckHistory(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+			_, user := newUser(t, storage)
+
+			deckID := uuid.New()
+			if err := storage.CreateDeck(user, repository.CreateDeckParams{ID: deckID, Name: "german"}); err != nil {
+				t.Fatalf("error create deck: %v", err)
+			}
+
+			if err := storage.UpdateDeck(user, repository.UpdateDeckParams{ID: deckID, Name: "deutsch"}); err != nil {
+				t.Fatalf("error update deck: %v", err)
+			}
+
+			if err := storage.DeleteDeck(user, repository.DeleteDeckParams{ID: deckID}); err != nil {
+				t.Fatalf("error delete deck: %v", err)
+			}
+
+			// a failed transaction leaves no revision behind
+			err := db.WithTx(user, func(storage repository.Storage) error {
+				if err := storage.RestoreDeck(user, repository.RestoreDeckParams{ID: deckID}); err != nil {
+					return err
+				}
+
+				return errors.New("rollback")
+			})
+			if err == nil {
+				t.Fatalf("transaction: want the error, got nil")
+			}
+
+			revisions, err := storage.SelectRevisions(user, repository.SelectRevisionsParams{EntityType: entities.EntityDeck, EntityID: deckID})
+			if err != nil {
+				t.Fatalf("error select revisions: %v", err)
+			}
+
+			want := "[create[name] update[name] delete[deleted_at]]"
+			if got := fmt.Sprint(actions(revisions)); got != want {
+				t.Fatalf("history: want %s, got %s", want, got)
+			}
+
+			if change := revisions[1].Changes["name"]; string(change.Old) != `"german"` || string(change.New) != `"deutsch"` {
+				t.Errorf("update diff: want german to deutsch, got %+v", change)
+			}
+		})
+	}
+}
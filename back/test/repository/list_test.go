@@ -0,0 +1,216 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"languago/infrastructure/repository"
+	"languago/pkg/models/entities"
+	"testing"
+	"time"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// listAll pages through the cards, the pages must not overlap.
+func listAll(t *testing.T, ctx context.Context, storage repository.Storage, arg repository.SelectFlashcardParams) []*entities.Flashcard {
+	t.Helper()
+
+	var cards []*entities.Flashcard
+	for {
+		page, err := storage.SelectFlashcard(ctx, arg)
+		if err != nil {
+			t.Fatalf("error list flashcards: %v", err)
+		}
+
+		if len(page) > arg.Limit {
+			t.Fatalf("page of %d cards exceeds the limit of %d", len(page), arg.Limit)
+		}
+
+		cards = append(cards, page...)
+		if len(page) < arg.Limit {
+			return cards
+		}
+
+		arg.After = repository.NewFlashcardCursor(page[len(page)-1], arg.Sort)
+	}
+}
+
+func words(cards []*entities.Flashcard) []string {
+	resp := make([]string, 0, len(cards))
+	for _, card := range cards {
+		resp = append(resp, card.Word)
+	}
+	return resp
+}
+
+func TestListFlashcards(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+			_, user := newUser(t, storage)
+			_, stranger := newUser(t, storage)
+
+			deckID := uuid.New()
+			if err := storage.CreateDeck(user, repository.CreateDeckParams{ID: deckID, Name: "german"}); err != nil {
+				t.Fatalf("error create deck: %v", err)
+			}
+
+			// word00 is the newest card, word24 the oldest
+			for i := 24; i >= 0; i-- {
+				cardID := uuid.New()
+				params := repository.CreateFlashcardParams{ID: cardID, Word: fmt.Sprintf("word%02d", i)}
+				if i%5 == 0 {
+					params.Tags = []string{"noun", "animal"}
+				}
+
+				if err := storage.CreateFlashcard(user, params); err != nil {
+					t.Fatalf("error create flashcard: %v", err)
+				}
+
+				if i%2 == 0 {
+					err := storage.AddToDeck(user, repository.AddToDeckParams{DeckID: deckID, FlashcardID: cardID})
+					if err != nil {
+						t.Fatalf("error add to deck: %v", err)
+					}
+				}
+
+				if i < 3 {
+					err := storage.UpsertReview(user, repository.UpsertReviewParams{
+						FlashcardID: cardID,
+						EaseFactor:  2.5,
+						DueAt:       time.Now().Add(time.Duration(i-1) * time.Hour),
+					})
+					if err != nil {
+						t.Fatalf("error upsert review: %v", err)
+					}
+				}
+
+				time.Sleep(time.Millisecond)
+			}
+
+			if err := storage.CreateFlashcard(stranger, repository.CreateFlashcardParams{ID: uuid.New(), Word: "word99"}); err != nil {
+				t.Fatalf("error create flashcard: %v", err)
+			}
+
+			for _, tc := range []struct {
+				sort  repository.FlashcardSort
+				desc  bool
+				first string
+			}{
+				{repository.SortByWord, false, "word00"},
+				{repository.SortByWord, true, "word24"},
+				{repository.SortByCreated, false, "word24"},
+				{repository.SortByCreated, true, "word00"},
+				{repository.SortByUpdated, true, "word00"},
+			} {
+				cards := listAll(t, user, storage, repository.SelectFlashcardParams{Sort: tc.sort, Desc: tc.desc, Limit: 7})
+				if len(cards) != 25 {
+					t.Fatalf("sort %s desc %v: want 25 cards, got %d: %v", tc.sort, tc.desc, len(cards), words(cards))
+				}
+
+				if cards[0].Word != tc.first {
+					t.Errorf("sort %s desc %v: want %s first, got %v", tc.sort, tc.desc, tc.first, words(cards))
+				}
+
+				seen := make(map[uuid.UUID]bool)
+				for _, card := range cards {
+					if seen[card.ID] {
+						t.Fatalf("sort %s desc %v: %s is listed twice", tc.sort, tc.desc, card.Word)
+					}
+					seen[card.ID] = true
+				}
+			}
+
+			tagged := listAll(t, user, storage, repository.SelectFlashcardParams{Tag: "animal", Limit: 2})
+			if got := fmt.Sprint(words(tagged)); got != "[word00 word05 word10 word15 word20]" {
+				t.Errorf("tag filter: got %s", got)
+			}
+
+			if len(tagged[0].Tags) != 2 || tagged[0].Tags[0] != "animal" {
+				t.Errorf("want sorted tags, got %v", tagged[0].Tags)
+			}
+
+			inDeck := listAll(t, user, storage, repository.SelectFlashcardParams{DeckID: deckID, Tag: "noun", Limit: 10})
+			if got := fmt.Sprint(words(inDeck)); got != "[word00 word10 word20]" {
+				t.Errorf("deck and tag filter: got %s", got)
+			}
+
+			prefixed := listAll(t, user, storage, repository.SelectFlashcardParams{Prefix: "WORD1", Limit: 100})
+			if len(prefixed) != 10 {
+				t.Errorf("prefix filter: want 10 cards, got %v", words(prefixed))
+			}
+
+			if cards, _ := storage.SelectFlashcard(user, repository.SelectFlashcardParams{Prefix: "word_"}); len(cards) != 0 {
+				t.Errorf("prefix wildcards must match literally, got %v", words(cards))
+			}
+
+			due := listAll(t, user, storage, repository.SelectFlashcardParams{DueAt: time.Now(), Limit: 10})
+			if got := fmt.Sprint(words(due)); got != "[word00 word01]" {
+				t.Errorf("due filter: got %s", got)
+			}
+
+			_, err := storage.SelectFlashcard(stranger, repository.SelectFlashcardParams{DeckID: deckID})
+			if !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("list deck of another user: want ErrNotFound, got %v", err)
+			}
+
+			_, err = storage.SelectFlashcard(user, repository.SelectFlashcardParams{Sort: "meaning"})
+			if !errors.Is(err, errors2.ErrValidation) {
+				t.Errorf("unknown sort: want ErrValidation, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUpdateFlashcardTags(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+			_, user := newUser(t, storage)
+
+			cardID := uuid.New()
+			if err := storage.CreateFlashcard(user, repository.CreateFlashcardParams{ID: cardID, Word: "hund", Tags: []string{"noun"}}); err != nil {
+				t.Fatalf("error create flashcard: %v", err)
+			}
+
+			cards, _ := storage.SelectFlashcard(user, repository.SelectFlashcardParams{ID: cardID})
+			created := cards[0]
+
+			time.Sleep(time.Millisecond)
+			if err := storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: cardID, Tags: []string{"animal", "noun", "animal"}}); err != nil {
+				t.Fatalf("error update tags: %v", err)
+			}
+
+			cards, _ = storage.SelectFlashcard(user, repository.SelectFlashcardParams{ID: cardID})
+			if got := fmt.Sprint(cards[0].Tags); got != "[animal noun]" {
+				t.Errorf("want tags [animal noun], got %s", got)
+			}
+
+			if !cards[0].UpdatedAt.After(created.UpdatedAt) || !cards[0].CreatedAt.Equal(created.CreatedAt) {
+				t.Errorf("update must only move updated_at, created %v -> %v, updated %v -> %v",
+					created.CreatedAt, cards[0].CreatedAt, created.UpdatedAt, cards[0].UpdatedAt)
+			}
+
+			if err := storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: cardID, Word: ptr("katze")}); err != nil {
+				t.Fatalf("error update word: %v", err)
+			}
+
+			cards, _ = storage.SelectFlashcard(user, repository.SelectFlashcardParams{ID: cardID})
+			if len(cards[0].Tags) != 2 {
+				t.Errorf("update without tags must keep them, got %v", cards[0].Tags)
+			}
+
+			if err := storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: cardID, Tags: []string{}}); err != nil {
+				t.Fatalf("error remove tags: %v", err)
+			}
+
+			cards, _ = storage.SelectFlashcard(user, repository.SelectFlashcardParams{ID: cardID})
+			if len(cards[0].Tags) != 0 {
+				t.Errorf("want no tags, got %v", cards[0].Tags)
+			}
+		})
+	}
+}
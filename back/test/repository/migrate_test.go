@@ -0,0 +1,213 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"languago/infrastructure/repository"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+	_ "modernc.org/sqlite"
+)
+
+type sqliteConfig struct {
+	path string
+}
+
+func (c sqliteConfig) GetCredentials() repository.DBCredentials {
+	return &repository.DBCred{Driver: "sqlite", Path: c.path}
+}
+
+func (sqliteConfig) IsMock() bool { return false }
+
+func newSQLite(t *testing.T, path string) repository.DatabaseInteractor {
+	t.Helper()
+
+	db, err := repository.NewDatabaseInteractor(sqliteConfig{path: path})
+	if err != nil {
+		t.Fatalf("error open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.CloseConnection() })
+
+	return db
+}
+
+// mysqlDSNEnv names the DSN of a MySQL database the tests may wipe, the MySQL
+// tests are skipped without it.
+const mysqlDSNEnv = "LANGUAGO_TEST_MYSQL_DSN"
+
+type mysqlConfig struct {
+	cfg *mysql.Config
+}
+
+func (c mysqlConfig) GetCredentials() repository.DBCredentials {
+	return &repository.DBCred{
+		Driver:    "mysql",
+		DbAddress: c.cfg.Addr,
+		User:      c.cfg.User,
+		Secret:    c.cfg.Passwd,
+		DBName:    c.cfg.DBName,
+	}
+}
+
+func (mysqlConfig) IsMock() bool { return false }
+
+// newMySQL opens the database of mysqlDSNEnv with its schema recreated, so
+// every test starts from empty tables. It returns nil if the DSN isn't set.
+func newMySQL(t *testing.T) repository.DatabaseInteractor {
+	t.Helper()
+
+	dsn := os.Getenv(mysqlDSNEnv)
+	if dsn == "" {
+		return nil
+	}
+
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("error parse %s: %v", mysqlDSNEnv, err)
+	}
+
+	db, err := repository.NewDatabaseInteractor(mysqlConfig{cfg: cfg})
+	if err != nil {
+		t.Fatalf("error open mysql: %v", err)
+	}
+	t.Cleanup(func() { db.CloseConnection() })
+
+	ctx := context.Background()
+	status, err := db.Migrator().Status(ctx)
+	if err != nil {
+		t.Fatalf("error migration status: %v", err)
+	}
+	if err := db.Migrator().Down(ctx, len(status)); err != nil {
+		t.Fatalf("error migrate down: %v", err)
+	}
+	if err := db.Migrator().Up(ctx); err != nil {
+		t.Fatalf("error migrate up: %v", err)
+	}
+
+	return db
+}
+
+func createUser(ctx context.Context, storage repository.Storage) error {
+	id := uuid.New()
+	return storage.CreateUser(ctx, repository.CreateUserParams{ID: id, Login: id.String(), Password: "hash"})
+}
+
+func TestMigrateUpDown(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLite(t, filepath.Join(t.TempDir(), "languago.db"))
+	migrator := db.Migrator()
+
+	if err := createUser(ctx, db.Database()); err == nil {
+		t.Fatal("create user before migrating should fail")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := migrator.Up(ctx); err != nil {
+			t.Fatalf("error migrate up: %v", err)
+		}
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("error migration status: %v", err)
+	}
+
+	if len(status) == 0 {
+		t.Fatal("no migrations found")
+	}
+
+	for _, s := range status {
+		if s.AppliedAt == nil {
+			t.Errorf("migration %d_%s is pending after up", s.Version, s.Name)
+		}
+	}
+
+	if err := createUser(ctx, db.Database()); err != nil {
+		t.Fatalf("error create user: %v", err)
+	}
+
+	if err := migrator.Down(ctx, len(status)); err != nil {
+		t.Fatalf("error migrate down: %v", err)
+	}
+
+	status, _ = migrator.Status(ctx)
+	for _, s := range status {
+		if s.AppliedAt != nil {
+			t.Errorf("migration %d_%s is applied after down", s.Version, s.Name)
+		}
+	}
+
+	if err := createUser(ctx, db.Database()); err == nil {
+		t.Error("create user after reverting all migrations should fail")
+	}
+
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("error migrate up again: %v", err)
+	}
+}
+
+func TestMigrateVerifiesApplied(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "languago.db")
+	db := newSQLite(t, path)
+
+	if err := db.Migrator().Up(ctx); err != nil {
+		t.Fatalf("error migrate up: %v", err)
+	}
+
+	raw, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("error open sqlite: %v", err)
+	}
+	defer raw.Close()
+
+	if _, err := raw.Exec("UPDATE schema_migrations SET checksum = 'changed' WHERE version = 1"); err != nil {
+		t.Fatalf("error change checksum: %v", err)
+	}
+
+	if err := db.Migrator().Up(ctx); !errors.Is(err, repository.ErrMigrationChecksum) {
+		t.Errorf("changed migration: want ErrMigrationChecksum, got %v", err)
+	}
+
+	if _, err := raw.Exec("DELETE FROM schema_migrations WHERE version = 1"); err != nil {
+		t.Fatalf("error delete migration: %v", err)
+	}
+
+	if _, err := raw.Exec("INSERT INTO schema_migrations (version, name, checksum) VALUES (99999, 'future', 'x')"); err != nil {
+		t.Fatalf("error insert migration: %v", err)
+	}
+
+	if _, err := db.Migrator().Status(ctx); !errors.Is(err, repository.ErrUnknownMigration) {
+		t.Errorf("unknown migration: want ErrUnknownMigration, got %v", err)
+	}
+}
+
+func TestMigrateConcurrently(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "languago.db")
+
+	nodes := []repository.DatabaseInteractor{newSQLite(t, path), newSQLite(t, path), newSQLite(t, path)}
+
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(node repository.DatabaseInteractor) {
+			defer wg.Done()
+
+			if err := node.Migrator().Up(ctx); err != nil {
+				t.Errorf("error migrate up: %v", err)
+			}
+		}(node)
+	}
+	wg.Wait()
+
+	if err := createUser(ctx, nodes[0].Database()); err != nil {
+		t.Fatalf("error create user: %v", err)
+	}
+}
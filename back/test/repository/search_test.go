@@ -0,0 +1,100 @@
+package repository_test
+
+import (
+	"errors"
+	"fmt"
+	"languago/infrastructure/repository"
+	"testing"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+func TestSearchFlashcards(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+			_, user := newUser(t, storage)
+			_, stranger := newUser(t, storage)
+
+			for _, card := range []repository.CreateFlashcardParams{
+				{Word: "Café", Meaning: "coffee house", Usage: []string{"wir treffen uns im Café am Markt"}},
+				{Word: "Hund", Meaning: "dog"},
+				{Word: "Hündin", Meaning: "female dog"},
+				{Word: "Katze", Meaning: "cat", Usage: []string{"die Katze schläft"}},
+				{Word: "Maus", Meaning: "mouse", Usage: []string{"Tom & Jerry <3"}},
+			} {
+				card.ID = uuid.New()
+				if err := storage.CreateFlashcard(user, card); err != nil {
+					t.Fatalf("error create flashcard: %v", err)
+				}
+			}
+
+			if err := storage.CreateFlashcard(stranger, repository.CreateFlashcardParams{ID: uuid.New(), Word: "Hund", Meaning: "dog"}); err != nil {
+				t.Fatalf("error create flashcard: %v", err)
+			}
+
+			search := func(query string, limit int) []string {
+				t.Helper()
+
+				matches, err := storage.SearchFlashcards(user, repository.SearchFlashcardsParams{Query: query, Limit: limit})
+				if err != nil {
+					t.Fatalf("error search %q: %v", query, err)
+				}
+
+				resp := make([]string, 0, len(matches))
+				for _, match := range matches {
+					resp = append(resp, match.Flashcard.Word)
+				}
+				return resp
+			}
+
+			for query, want := range map[string]string{
+				"CAFE":       "[Café]",
+				"dog":        "[Hund Hündin]",
+				"female dog": "[Hündin]",
+				"hundd":      "[Hund]",
+				"kat":        "[Katze]",
+				"schlaft":    "[Katze]",
+				"elephant":   "[]",
+			} {
+				got := search(query, 10)
+				if query == "dog" && len(got) == 2 && got[0] == "Hündin" {
+					got[0], got[1] = got[1], got[0]
+				}
+
+				if fmt.Sprint(got) != want {
+					t.Errorf("search %q: want %s, got %v", query, want, got)
+				}
+			}
+
+			if got := search("dog", 1); len(got) != 1 {
+				t.Errorf("search with limit 1: got %v", got)
+			}
+
+			matches, _ := storage.SearchFlashcards(user, repository.SearchFlashcardsParams{Query: "katze", Limit: 10})
+			if len(matches) != 1 || matches[0].Snippet == "" || matches[0].Rank <= 0 {
+				t.Fatalf("search katze: want a ranked match with a snippet, got %+v", matches)
+			}
+
+			matches, _ = storage.SearchFlashcards(user, repository.SearchFlashcardsParams{Query: "cafe", Limit: 10})
+			if want := "<b>Café</b> coffee house wir treffen uns im <b>Café</b> am Markt"; len(matches) != 1 || matches[0].Snippet != want {
+				t.Errorf("search cafe: want snippet %q, got %+v", want, matches)
+			}
+
+			// the card text is escaped, only the highlights are markup
+			matches, _ = storage.SearchFlashcards(user, repository.SearchFlashcardsParams{Query: "maus", Limit: 10})
+			if want := "<b>Maus</b> mouse Tom &amp; Jerry &lt;3"; len(matches) != 1 || matches[0].Snippet != want {
+				t.Errorf("search maus: want snippet %q, got %+v", want, matches)
+			}
+
+			for _, query := range []string{"", " ,. "} {
+				_, err := storage.SearchFlashcards(user, repository.SearchFlashcardsParams{Query: query, Limit: 10})
+				if !errors.Is(err, errors2.ErrValidation) {
+					t.Errorf("search %q: want ErrValidation, got %v", query, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"languago/infrastructure/repository"
+	"sync"
+	"testing"
+	"time"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+func TestUserUniqueness(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+			ctx := context.Background()
+
+			id := uuid.New()
+			err := storage.CreateUser(ctx, repository.CreateUserParams{ID: id, Login: "alice", Password: "hash"})
+			if err != nil {
+				t.Fatalf("error create user: %v", err)
+			}
+
+			err = storage.CreateUser(ctx, repository.CreateUserParams{ID: uuid.New(), Login: "alice", Password: "hash"})
+			if !errors.Is(err, errors2.ErrAlreadyExists) {
+				t.Errorf("duplicate login: want ErrAlreadyExists, got %v", err)
+			}
+
+			err = storage.CreateUser(ctx, repository.CreateUserParams{ID: id, Login: "bob", Password: "hash"})
+			if !errors.Is(err, errors2.ErrAlreadyExists) {
+				t.Errorf("duplicate id: want ErrAlreadyExists, got %v", err)
+			}
+
+			if _, err := storage.SelectUser(ctx, repository.SelectUserParams{Login: "bob"}); !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("select missing user: want ErrNotFound, got %v", err)
+			}
+
+			err = storage.UpdateUser(ctx, repository.UpdateUserParams{ID: uuid.New(), Login: "carol"})
+			if !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("update missing user: want ErrNotFound, got %v", err)
+			}
+
+			err = storage.UpdateUser(ctx, repository.UpdateUserParams{ID: uuid.New(), Password: "hash"})
+			if !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("update password of missing user: want ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFlashcardRequiresExistingOwner(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+
+			err := storage.CreateFlashcard(asUser(uuid.New()), repository.CreateFlashcardParams{ID: uuid.New(), Word: "hund"})
+			if !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("create for unknown owner: want ErrNotFound, got %v", err)
+			}
+
+			_, owner := newUser(t, storage)
+			cardID := uuid.New()
+			if err := storage.CreateFlashcard(owner, repository.CreateFlashcardParams{ID: cardID, Word: "hund"}); err != nil {
+				t.Fatalf("error create flashcard: %v", err)
+			}
+
+			err = storage.CreateFlashcard(owner, repository.CreateFlashcardParams{ID: cardID, Word: "katze"})
+			if !errors.Is(err, errors2.ErrAlreadyExists) {
+				t.Errorf("duplicate id: want ErrAlreadyExists, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDeckOwnership(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+
+			_, owner := newUser(t, storage)
+			_, stranger := newUser(t, storage)
+
+			deckID := uuid.New()
+			if err := storage.CreateDeck(owner, repository.CreateDeckParams{ID: deckID, Name: "german"}); err != nil {
+				t.Fatalf("error create deck: %v", err)
+			}
+
+			cardID := uuid.New()
+			if err := storage.CreateFlashcard(owner, repository.CreateFlashcardParams{ID: cardID, Word: "hund"}); err != nil {
+				t.Fatalf("error create flashcard: %v", err)
+			}
+
+			strangerCard := uuid.New()
+			if err := storage.CreateFlashcard(stranger, repository.CreateFlashcardParams{ID: strangerCard, Word: "katze"}); err != nil {
+				t.Fatalf("error create flashcard: %v", err)
+			}
+
+			add := repository.AddToDeckParams{DeckID: deckID, FlashcardID: cardID}
+			if err := storage.AddToDeck(owner, add); err != nil {
+				t.Fatalf("error add to deck: %v", err)
+			}
+
+			if err := storage.AddToDeck(owner, add); err != nil {
+				t.Errorf("adding twice should be a no-op, got %v", err)
+			}
+
+			err := storage.AddToDeck(owner, repository.AddToDeckParams{DeckID: deckID, FlashcardID: strangerCard})
+			if !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("add card of another user: want ErrNotFound, got %v", err)
+			}
+
+			_, err = storage.SelectFromDeck(stranger, repository.SelectFromDeckParams{DeckID: deckID})
+			if !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("select deck of another user: want ErrNotFound, got %v", err)
+			}
+
+			err = storage.DeleteDeck(stranger, repository.DeleteDeckParams{ID: deckID})
+			if !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("delete deck of another user: want ErrNotFound, got %v", err)
+			}
+
+			cards, err := storage.SelectFlashcard(owner, repository.SelectFlashcardParams{DeckID: deckID})
+			if err != nil || len(cards) != 1 || cards[0].ID != cardID {
+				t.Fatalf("deck should hold the card, got %v, %v", cards, err)
+			}
+
+			// deleting the card removes it from the deck
+			if err := storage.DeleteFlashcard(owner, cardID); err != nil {
+				t.Fatalf("error delete flashcard: %v", err)
+			}
+
+			cards, err = storage.SelectFlashcard(owner, repository.SelectFlashcardParams{DeckID: deckID})
+			if err != nil || len(cards) != 0 {
+				t.Errorf("deck should be empty, got %v, %v", cards, err)
+			}
+		})
+	}
+}
+
+func TestDeleteUserCascades(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+
+			userID, user := newUser(t, storage)
+			cardID := uuid.New()
+			if err := storage.CreateFlashcard(user, repository.CreateFlashcardParams{ID: cardID, Word: "hund"}); err != nil {
+				t.Fatalf("error create flashcard: %v", err)
+			}
+
+			err := storage.UpsertReview(user, repository.UpsertReviewParams{
+				FlashcardID: cardID,
+				EaseFactor:  2.5,
+				DueAt:       time.Now(),
+			})
+			if err != nil {
+				t.Fatalf("error upsert review: %v", err)
+			}
+
+			if err := storage.DeleteUser(user, userID); err != nil {
+				t.Fatalf("error delete user: %v", err)
+			}
+
+			_, err = storage.SelectReview(user, repository.SelectReviewParams{FlashcardID: cardID})
+			if !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("review of deleted user: want ErrNotFound, got %v", err)
+			}
+
+			// the id is free again, nothing of the old user is left
+			if err := storage.CreateUser(user, repository.CreateUserParams{ID: userID, Login: "again", Password: "hash"}); err != nil {
+				t.Fatalf("error create user: %v", err)
+			}
+
+			cards, err := storage.SelectFlashcard(user, repository.SelectFlashcardParams{})
+			if err != nil || len(cards) != 0 {
+				t.Errorf("flashcards of deleted user are left, got %v, %v", cards, err)
+			}
+		})
+	}
+}
+
+func TestDueReviews(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+
+			_, user := newUser(t, storage)
+			now := time.Now()
+
+			for i, due := range []time.Duration{-time.Hour, time.Hour, -2 * time.Hour} {
+				cardID := uuid.New()
+				err := storage.CreateFlashcard(user, repository.CreateFlashcardParams{ID: cardID, Word: fmt.Sprint("word", i)})
+				if err != nil {
+					t.Fatalf("error create flashcard: %v", err)
+				}
+
+				err = storage.UpsertReview(user, repository.UpsertReviewParams{
+					FlashcardID: cardID,
+					EaseFactor:  2.5,
+					DueAt:       now.Add(due),
+				})
+				if err != nil {
+					t.Fatalf("error upsert review: %v", err)
+				}
+			}
+
+			due, err := storage.SelectDueReviews(user, repository.SelectDueReviewsParams{DueAt: now, Limit: 10})
+			if err != nil {
+				t.Fatalf("error select due reviews: %v", err)
+			}
+
+			if len(due) != 2 || due[0].Flashcard.Word != "word2" || due[1].Flashcard.Word != "word0" {
+				t.Errorf("want word2 and word0 due, got %v", due)
+			}
+		})
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+			_, user := newUser(t, storage)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					for j := 0; j < 50; j++ {
+						cardID := uuid.New()
+						if err := storage.CreateFlashcard(user, repository.CreateFlashcardParams{ID: cardID, Usage: []string{"x"}}); err != nil {
+							t.Errorf("error create flashcard: %v", err)
+							return
+						}
+
+						if err := storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: cardID, Word: ptr("w")}); err != nil {
+							t.Errorf("error update flashcard: %v", err)
+							return
+						}
+
+						if _, err := storage.SelectFlashcard(user, repository.SelectFlashcardParams{}); err != nil {
+							t.Errorf("error select flashcards: %v", err)
+							return
+						}
+					}
+				}()
+			}
+			wg.Wait()
+
+			cards, err := storage.SelectFlashcard(user, repository.SelectFlashcardParams{})
+			if err != nil || len(cards) != 8*50 {
+				t.Errorf("want %d flashcards, got %d, %v", 8*50, len(cards), err)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package repository_test
+
+import (
+	"errors"
+	"fmt"
+	"languago/infrastructure/repository"
+	"sort"
+	"testing"
+	"time"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+func TestTrash(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+			_, user := newUser(t, storage)
+			_, stranger := newUser(t, storage)
+
+			deckID := uuid.New()
+			if err := storage.CreateDeck(user, repository.CreateDeckParams{ID: deckID, Name: "german"}); err != nil {
+				t.Fatalf("error create deck: %v", err)
+			}
+
+			hund, katze := uuid.New(), uuid.New()
+			for id, word := range map[uuid.UUID]string{hund: "hund", katze: "katze"} {
+				if err := storage.CreateFlashcard(user, repository.CreateFlashcardParams{ID: id, Word: word, Tags: []string{"noun"}}); err != nil {
+					t.Fatalf("error create flashcard: %v", err)
+				}
+
+				if err := storage.AddToDeck(user, repository.AddToDeckParams{DeckID: deckID, FlashcardID: id}); err != nil {
+					t.Fatalf("error add to deck: %v", err)
+				}
+			}
+
+			err := storage.UpsertReview(user, repository.UpsertReviewParams{FlashcardID: hund, EaseFactor: 2.5, DueAt: time.Now().Add(-time.Hour)})
+			if err != nil {
+				t.Fatalf("error upsert review: %v", err)
+			}
+
+			if err := storage.DeleteFlashcard(user, hund); err != nil {
+				t.Fatalf("error delete flashcard: %v", err)
+			}
+
+			if _, err := storage.SelectFlashcard(user, repository.SelectFlashcardParams{ID: hund}); !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("select deleted card: want ErrNotFound, got %v", err)
+			}
+
+			if cards, _ := storage.SelectFlashcard(user, repository.SelectFlashcardParams{}); fmt.Sprint(words(cards)) != "[katze]" {
+				t.Errorf("list: want [katze], got %v", words(cards))
+			}
+
+			if cards, _ := storage.SelectFromDeck(user, repository.SelectFromDeckParams{DeckID: deckID}); fmt.Sprint(words(cards)) != "[katze]" {
+				t.Errorf("deck: want [katze], got %v", words(cards))
+			}
+
+			if reviews, _ := storage.SelectDueReviews(user, repository.SelectDueReviewsParams{DueAt: time.Now(), Limit: 10}); len(reviews) != 0 {
+				t.Errorf("due reviews of a deleted card: want none, got %d", len(reviews))
+			}
+
+			if matches, _ := storage.SearchFlashcards(user, repository.SearchFlashcardsParams{Query: "hund", Limit: 10}); len(matches) != 0 {
+				t.Errorf("search: want no deleted cards, got %d", len(matches))
+			}
+
+			if err := storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: hund, Meaning: ptr("dog")}); !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("update deleted card: want ErrNotFound, got %v", err)
+			}
+
+			if err := storage.DeleteFlashcard(user, hund); !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("delete twice: want ErrNotFound, got %v", err)
+			}
+
+			trash, err := storage.SelectDeletedFlashcards(user)
+			if err != nil {
+				t.Fatalf("error select deleted flashcards: %v", err)
+			}
+
+			if len(trash) != 1 || trash[0].ID != hund || trash[0].DeletedAt == nil || len(trash[0].Tags) != 1 {
+				t.Errorf("trash: want the deleted card with its tags, got %+v", trash)
+			}
+
+			if trash, _ := storage.SelectDeletedFlashcards(stranger); len(trash) != 0 {
+				t.Errorf("trash of another user: want empty, got %v", words(trash))
+			}
+
+			if err := storage.DeleteDeck(user, repository.DeleteDeckParams{ID: deckID}); err != nil {
+				t.Fatalf("error delete deck: %v", err)
+			}
+
+			if _, err := storage.SelectDeck(user, repository.SelectDeckParams{ID: deckID}); !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("select deleted deck: want ErrNotFound, got %v", err)
+			}
+
+			if decks, _ := storage.SelectDecks(user, repository.SelectDeckParams{}); len(decks) != 0 {
+				t.Errorf("list decks: want none, got %d", len(decks))
+			}
+
+			err = storage.AddToDeck(user, repository.AddToDeckParams{DeckID: deckID, FlashcardID: katze})
+			if !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("add to deleted deck: want ErrNotFound, got %v", err)
+			}
+
+			decks, err := storage.SelectDeletedDecks(user)
+			if err != nil || len(decks) != 1 || decks[0].Id != deckID || decks[0].DeletedAt == nil {
+				t.Errorf("deck trash: want the deleted deck, got %+v, %v", decks, err)
+			}
+
+			if err := storage.RestoreFlashcard(stranger, hund); !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("restore card of another user: want ErrNotFound, got %v", err)
+			}
+
+			if err := storage.RestoreDeck(stranger, repository.RestoreDeckParams{ID: deckID}); !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("restore deck of another user: want ErrNotFound, got %v", err)
+			}
+
+			if err := storage.RestoreFlashcard(user, hund); err != nil {
+				t.Fatalf("error restore flashcard: %v", err)
+			}
+
+			if err := storage.RestoreFlashcard(user, hund); !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("restore a card not in the trash: want ErrNotFound, got %v", err)
+			}
+
+			if err := storage.RestoreDeck(user, repository.RestoreDeckParams{ID: deckID}); err != nil {
+				t.Fatalf("error restore deck: %v", err)
+			}
+
+			// a restored card is back with its deck entries and reviews, the
+			// deck cards come in no particular order
+			cards, err := storage.SelectFromDeck(user, repository.SelectFromDeckParams{DeckID: deckID})
+			got := words(cards)
+			sort.Strings(got)
+			if err != nil || fmt.Sprint(got) != "[hund katze]" {
+				t.Errorf("restored deck: want [hund katze], got %v, %v", got, err)
+			}
+
+			if cards[0].DeletedAt != nil {
+				t.Errorf("restored card: want no deleted_at, got %v", cards[0].DeletedAt)
+			}
+
+			if reviews, _ := storage.SelectDueReviews(user, repository.SelectDueReviewsParams{DueAt: time.Now(), Limit: 10}); len(reviews) != 1 {
+				t.Errorf("due reviews of a restored card: want 1, got %d", len(reviews))
+			}
+		})
+	}
+}
+
+func TestPurgeDeleted(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+			_, user := newUser(t, storage)
+
+			deckID, cardID, keptID := uuid.New(), uuid.New(), uuid.New()
+			if err := storage.CreateDeck(user, repository.CreateDeckParams{ID: deckID, Name: "german"}); err != nil {
+				t.Fatalf("error create deck: %v", err)
+			}
+
+			for _, id := range []uuid.UUID{cardID, keptID} {
+				if err := storage.CreateFlashcard(user, repository.CreateFlashcardParams{ID: id, Word: "hund"}); err != nil {
+					t.Fatalf("error create flashcard: %v", err)
+				}
+			}
+
+			if err := storage.DeleteFlashcard(user, cardID); err != nil {
+				t.Fatalf("error delete flashcard: %v", err)
+			}
+
+			if err := storage.DeleteDeck(user, repository.DeleteDeckParams{ID: deckID}); err != nil {
+				t.Fatalf("error delete deck: %v", err)
+			}
+
+			// nothing was deleted an hour ago yet
+			purged, err := storage.PurgeDeleted(user, time.Now().Add(-time.Hour))
+			if err != nil || purged != 0 {
+				t.Fatalf("purge before deletion: want 0, got %d, %v", purged, err)
+			}
+
+			purged, err = storage.PurgeDeleted(user, time.Now().Add(time.Second))
+			if err != nil || purged != 2 {
+				t.Fatalf("purge after deletion: want 2, got %d, %v", purged, err)
+			}
+
+			if cards, _ := storage.SelectDeletedFlashcards(user); len(cards) != 0 {
+				t.Errorf("card trash after purge: want empty, got %v", words(cards))
+			}
+
+			if decks, _ := storage.SelectDeletedDecks(user); len(decks) != 0 {
+				t.Errorf("deck trash after purge: want empty, got %d", len(decks))
+			}
+
+			if err := storage.RestoreFlashcard(user, cardID); !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("restore purged card: want ErrNotFound, got %v", err)
+			}
+
+			// the purged id is free again, the live card is left alone
+			if err := storage.CreateFlashcard(user, repository.CreateFlashcardParams{ID: cardID, Word: "katze"}); err != nil {
+				t.Errorf("error create flashcard with a purged id: %v", err)
+			}
+
+			if _, err := storage.SelectFlashcard(user, repository.SelectFlashcardParams{ID: keptID}); err != nil {
+				t.Errorf("error select live card after purge: %v", err)
+			}
+		})
+	}
+}
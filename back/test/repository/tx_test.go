@@ -0,0 +1,109 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"languago/infrastructure/repository"
+	"path/filepath"
+	"testing"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// interactors returns every backend which runs in-process, migrated, and
+// MySQL if mysqlDSNEnv is set.
+func interactors(t *testing.T) map[string]repository.DatabaseInteractor {
+	t.Helper()
+
+	memory, err := repository.NewDatabaseInteractor(mockConfig{})
+	if err != nil {
+		t.Fatalf("error create storage: %v", err)
+	}
+
+	sqlite := newSQLite(t, filepath.Join(t.TempDir(), "languago.db"))
+	if err := sqlite.Migrator().Up(context.Background()); err != nil {
+		t.Fatalf("error migrate up: %v", err)
+	}
+
+	resp := map[string]repository.DatabaseInteractor{
+		"memory": memory,
+		"sqlite": sqlite,
+	}
+	if mysql := newMySQL(t); mysql != nil {
+		resp["mysql"] = mysql
+	}
+
+	return resp
+}
+
+func TestWithTx(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			_, user := newUser(t, db.Database())
+			count := func() int {
+				cards, err := db.Database().SelectFlashcard(user, repository.SelectFlashcardParams{})
+				if err != nil {
+					t.Fatalf("error select flashcards: %v", err)
+				}
+				return len(cards)
+			}
+
+			// adding the card to a missing deck rolls the card back
+			err := db.WithTx(user, func(storage repository.Storage) error {
+				cardID := uuid.New()
+				if err := storage.CreateFlashcard(user, repository.CreateFlashcardParams{ID: cardID, Word: "hund"}); err != nil {
+					return err
+				}
+
+				return storage.AddToDeck(user, repository.AddToDeckParams{DeckID: uuid.New(), FlashcardID: cardID})
+			})
+			if !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("add to missing deck: want ErrNotFound, got %v", err)
+			}
+
+			if n := count(); n != 0 {
+				t.Errorf("rolled back card is left, got %d cards", n)
+			}
+
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Error("panic in transaction isn't passed on")
+					}
+				}()
+
+				db.WithTx(user, func(storage repository.Storage) error {
+					storage.CreateFlashcard(user, repository.CreateFlashcardParams{ID: uuid.New(), Word: "katze"})
+					panic("boom")
+				})
+			}()
+
+			if n := count(); n != 0 {
+				t.Errorf("card of panicked transaction is left, got %d cards", n)
+			}
+
+			err = db.WithTx(user, func(storage repository.Storage) error {
+				deckID := uuid.New()
+				if err := storage.CreateDeck(user, repository.CreateDeckParams{ID: deckID, Name: "german"}); err != nil {
+					return err
+				}
+
+				cardID := uuid.New()
+				if err := storage.CreateFlashcard(user, repository.CreateFlashcardParams{ID: cardID, Word: "hund"}); err != nil {
+					return err
+				}
+
+				return storage.AddToDeck(user, repository.AddToDeckParams{DeckID: deckID, FlashcardID: cardID})
+			})
+			if err != nil {
+				t.Fatalf("error commit transaction: %v", err)
+			}
+
+			if n := count(); n != 1 {
+				t.Errorf("committed card is missing, got %d cards", n)
+			}
+		})
+	}
+}
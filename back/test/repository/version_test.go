@@ -0,0 +1,89 @@
+package repository_test
+
+import (
+	"errors"
+	"languago/infrastructure/repository"
+	"testing"
+
+	errors2 "languago/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+func TestUpdateVersions(t *testing.T) {
+	for name, db := range interactors(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := db.Database()
+			_, user := newUser(t, storage)
+
+			cardID := uuid.New()
+			if err := storage.CreateFlashcard(user, repository.CreateFlashcardParams{ID: cardID, Word: "hund"}); err != nil {
+				t.Fatalf("error create flashcard: %v", err)
+			}
+
+			version := func() int64 {
+				cards, err := storage.SelectFlashcard(user, repository.SelectFlashcardParams{ID: cardID})
+				if err != nil {
+					t.Fatalf("error select flashcard: %v", err)
+				}
+				return cards[0].Version
+			}
+
+			if v := version(); v != 1 {
+				t.Fatalf("new card: want version 1, got %d", v)
+			}
+
+			if err := storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: cardID, Meaning: ptr("dog"), Version: 1}); err != nil {
+				t.Fatalf("error update on the current version: %v", err)
+			}
+
+			err := storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: cardID, Meaning: ptr("hound"), Version: 1})
+			if !errors.Is(err, errors2.ErrPreconditionFailed) {
+				t.Errorf("update on a stale version: want ErrPreconditionFailed, got %v", err)
+			}
+
+			// a stale write is rejected even if it changes nothing
+			err = storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: cardID, Version: 1})
+			if !errors.Is(err, errors2.ErrPreconditionFailed) {
+				t.Errorf("empty update on a stale version: want ErrPreconditionFailed, got %v", err)
+			}
+
+			if err := storage.UpdateFlashcard(user, repository.UpdateFlashcardParams{ID: cardID, Tags: []string{"noun"}}); err != nil {
+				t.Fatalf("error update any version: %v", err)
+			}
+
+			if v := version(); v != 3 {
+				t.Errorf("card updated twice: want version 3, got %d", v)
+			}
+
+			cards, _ := storage.SelectFlashcard(user, repository.SelectFlashcardParams{Limit: 10})
+			if len(cards) != 1 || cards[0].Version != 3 {
+				t.Errorf("listed card: want version 3, got %+v", cards)
+			}
+
+			deckID := uuid.New()
+			if err := storage.CreateDeck(user, repository.CreateDeckParams{ID: deckID, Name: "german"}); err != nil {
+				t.Fatalf("error create deck: %v", err)
+			}
+
+			if err := storage.UpdateDeck(user, repository.UpdateDeckParams{ID: deckID, Name: "deutsch", Version: 1}); err != nil {
+				t.Fatalf("error update deck on the current version: %v", err)
+			}
+
+			err = storage.UpdateDeck(user, repository.UpdateDeckParams{ID: deckID, Name: "german", Version: 1})
+			if !errors.Is(err, errors2.ErrPreconditionFailed) {
+				t.Errorf("update deck on a stale version: want ErrPreconditionFailed, got %v", err)
+			}
+
+			err = storage.UpdateDeck(user, repository.UpdateDeckParams{ID: uuid.New(), Name: "german", Version: 1})
+			if !errors.Is(err, errors2.ErrNotFound) {
+				t.Errorf("update missing deck: want ErrNotFound, got %v", err)
+			}
+
+			deck, err := storage.SelectDeck(user, repository.SelectDeckParams{ID: deckID})
+			if err != nil || deck.Version != 2 || deck.Name != "deutsch" {
+				t.Errorf("deck updated once: want version 2 named deutsch, got %+v, %v", deck, err)
+			}
+		})
+	}
+}
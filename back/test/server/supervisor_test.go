@@ -0,0 +1,196 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"languago/internal/server"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// crashingService crashes right away on its first crashes starts, it keeps
+// running after that until crash is called.
+type crashingService struct {
+	mu      sync.Mutex
+	crashes int
+	starts  []time.Time
+	errs    chan error
+}
+
+func (s *crashingService) Start(e chan error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.starts = append(s.starts, time.Now())
+	s.errs = e
+	if len(s.starts) <= s.crashes {
+		go func() { e <- errors.New("address already in use") }()
+	}
+}
+
+func (s *crashingService) crash() {
+	s.mu.Lock()
+	e := s.errs
+	s.mu.Unlock()
+
+	e <- errors.New("connection reset")
+}
+
+func (s *crashingService) Stop(context.Context) error { return nil }
+
+func (s *crashingService) started() []time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]time.Time(nil), s.starts...)
+}
+
+// logBuffer collects the log of the supervisor, which writes it from the
+// goroutines watching the services.
+type logBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+func policy() server.RestartPolicy {
+	return server.RestartPolicy{
+		MaxRestarts: 3,
+		MinBackoff:  10 * time.Millisecond,
+		MaxBackoff:  20 * time.Millisecond,
+		ResetAfter:  time.Minute,
+	}
+}
+
+// waitFor polls the condition for a second.
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if condition() {
+			return
+		}
+	}
+	t.Fatalf("error waiting for %s", what)
+}
+
+func TestSupervisorRestarts(t *testing.T) {
+	errorCh := make(chan error, 10)
+	supervisor := server.NewSupervisor(zerolog.Nop(), policy(), errorCh)
+
+	flaky := &crashingService{crashes: 3}
+	stable := &crashingService{}
+	supervisor.Add("flaky", flaky)
+	supervisor.Add("stable", stable)
+	supervisor.Run()
+
+	waitFor(t, "the restarts", func() bool { return len(flaky.started()) == 4 })
+
+	starts := flaky.started()
+	for i, want := range []time.Duration{10, 20, 20} {
+		if gap := starts[i+1].Sub(starts[i]); gap < want*time.Millisecond {
+			t.Errorf("restart %d: want a backoff of %vms, waited %v", i+1, want, gap)
+		}
+	}
+
+	statuses := supervisor.Status()
+	if s := statuses[0]; s.Name != "flaky" || s.State != server.ServiceRunning || s.Restarts != 3 || s.LastError == "" {
+		t.Errorf("flaky status: want running after 3 restarts, got %+v", s)
+	}
+	if s := statuses[1]; s.State != server.ServiceRunning || s.Restarts != 0 || len(stable.started()) != 1 {
+		t.Errorf("stable status: want running without restarts, got %+v", s)
+	}
+
+	if len(errorCh) != 3 {
+		t.Errorf("errors: want every crash reported, got %d", len(errorCh))
+	}
+
+	select {
+	case err := <-supervisor.Failed():
+		t.Errorf("failed: want nothing within the policy, got %v", err)
+	default:
+	}
+
+	supervisor.Stop()
+	if s := supervisor.Status()[0]; s.State != server.ServiceStopped {
+		t.Errorf("stopped status: want stopped, got %s", s.State)
+	}
+}
+
+func TestSupervisorGivesUp(t *testing.T) {
+	var log logBuffer
+	supervisor := server.NewSupervisor(zerolog.New(&log), policy(), nil)
+
+	broken := &crashingService{crashes: 100}
+	supervisor.Add("broken", broken)
+	supervisor.Run()
+	defer supervisor.Stop()
+
+	select {
+	case err := <-supervisor.Failed():
+		if err == nil {
+			t.Errorf("failed: want the error of the service")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("failed: want the service given up on")
+	}
+
+	if starts := len(broken.started()); starts != 4 {
+		t.Errorf("starts: want the first one and 3 restarts, got %d", starts)
+	}
+
+	if s := supervisor.Status()[0]; s.State != server.ServiceFailed {
+		t.Errorf("status: want failed, got %s", s.State)
+	}
+
+	// every state change is logged
+	for _, want := range []string{
+		"service broken is running | restarts: 0",
+		"service broken is restarting | restarts: 2",
+		"service broken is failed | restarts: 3",
+	} {
+		if !strings.Contains(log.String(), want) {
+			t.Errorf("log: want %q, got %s", want, log.String())
+		}
+	}
+}
+
+func TestSupervisorResetsCrashes(t *testing.T) {
+	p := policy()
+	p.MaxRestarts = 1
+	p.ResetAfter = 30 * time.Millisecond
+	supervisor := server.NewSupervisor(zerolog.Nop(), p, nil)
+
+	service := &crashingService{}
+	supervisor.Add("service", service)
+	supervisor.Run()
+	defer supervisor.Stop()
+
+	// a crash after the service ran for a while is the first in a row
+	for i := 0; i < 3; i++ {
+		time.Sleep(40 * time.Millisecond)
+		service.crash()
+		waitFor(t, "the restart", func() bool { return len(service.started()) == i+2 })
+	}
+
+	select {
+	case err := <-supervisor.Failed():
+		t.Errorf("failed: want the count reset, got %v", err)
+	default:
+	}
+}
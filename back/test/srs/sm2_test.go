@@ -0,0 +1,92 @@
+package srs_test
+
+import (
+	"errors"
+	"fmt"
+	"languago/pkg/srs"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReview(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		grades      []srs.Grade
+		intervals   []int
+		easeFactor  float64
+		repetitions int
+		lapses      int
+	}{
+		{"perfect", []srs.Grade{5, 5, 5, 5}, []int{1, 6, 16, 45}, 2.9, 4, 0},
+		{"hesitant keeps the ease", []srs.Grade{4, 4, 4}, []int{1, 6, 15}, 2.5, 3, 0},
+		{"pass lowers the ease", []srs.Grade{3, 3, 3}, []int{1, 6, 13}, 2.08, 3, 0},
+		{"failed first recall is no lapse", []srs.Grade{2}, []int{1}, 2.18, 0, 0},
+		{"incorrect", []srs.Grade{1}, []int{1}, 1.96, 0, 0},
+		{"blackout", []srs.Grade{0}, []int{1}, 1.7, 0, 0},
+		{"ease floor", []srs.Grade{0, 0, 0}, []int{1, 1, 1}, srs.MinEaseFactor, 0, 0},
+		{"ease floor from incorrect", []srs.Grade{1, 1, 2}, []int{1, 1, 1}, srs.MinEaseFactor, 0, 0},
+		{"lapse resets the repetitions", []srs.Grade{5, 5, 0}, []int{1, 6, 1}, 1.9, 0, 1},
+		{"relearning after a lapse", []srs.Grade{5, 5, 0, 4, 4}, []int{1, 6, 1, 1, 6}, 1.9, 2, 1},
+		{"each lapse counts", []srs.Grade{4, 1, 4, 2}, []int{1, 1, 1, 1}, 1.64, 0, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := srs.NewState(now)
+
+			var intervals []int
+			for _, grade := range tt.grades {
+				next, err := srs.Review(state, grade, now)
+				if err != nil {
+					t.Fatalf("error review: %v", err)
+				}
+
+				if want := now.Add(time.Duration(next.Interval) * 24 * time.Hour); !next.DueAt.Equal(want) {
+					t.Errorf("grade %d: want due at %v, got %v", grade, want, next.DueAt)
+				}
+
+				intervals = append(intervals, next.Interval)
+				state = next
+			}
+
+			if fmt.Sprint(intervals) != fmt.Sprint(tt.intervals) {
+				t.Errorf("intervals: want %v, got %v", tt.intervals, intervals)
+			}
+
+			if math.Abs(state.EaseFactor-tt.easeFactor) > 1e-9 {
+				t.Errorf("ease factor: want %v, got %v", tt.easeFactor, state.EaseFactor)
+			}
+
+			if state.Repetitions != tt.repetitions || state.Lapses != tt.lapses {
+				t.Errorf("repetitions and lapses: want %d and %d, got %d and %d",
+					tt.repetitions, tt.lapses, state.Repetitions, state.Lapses)
+			}
+		})
+	}
+}
+
+func TestReviewInvalidGrade(t *testing.T) {
+	state := srs.NewState(time.Now())
+
+	for _, grade := range []srs.Grade{-1, 6} {
+		next, err := srs.Review(state, grade, time.Now())
+		if !errors.Is(err, srs.ErrInvalidGrade) {
+			t.Errorf("grade %d: want ErrInvalidGrade, got %v", grade, err)
+		}
+
+		if next != state {
+			t.Errorf("grade %d: want the state unchanged, got %+v", grade, next)
+		}
+	}
+}
+
+func TestReviewZeroEaseFactor(t *testing.T) {
+	// states stored before the ease factor existed start from the default
+	next, err := srs.Review(srs.State{}, srs.GradeHesitant, time.Now())
+	if err != nil || next.EaseFactor != srs.DefaultEaseFactor {
+		t.Errorf("zero ease factor: want the default, got %v, %v", next.EaseFactor, err)
+	}
+}